@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// The AI debug overlay (F3) draws what the ghosts are thinking on top of the
+// maze. While it is on and the game is paused, the step key advances one
+// frame and slow motion plays the game at a quarter of its speed.
+
+// Frames between steps in slow motion
+const slowMotionEvery = 4
+
+// Overlay colours by ghost, in the order of newCurses
+var aiDebugColors = []color.RGBA{
+	{255, 80, 80, 255},
+	{255, 150, 220, 255},
+	{80, 220, 255, 255},
+	{255, 180, 60, 255},
+}
+
+// handleDebugControls toggles the overlay and slow motion
+func (g *Game) handleDebugControls() {
+	if Input.JustPressed(ActionDebugOverlay) {
+		g.debugOverlay = !g.debugOverlay
+		g.slowMotion = false
+		stateLog.Info("AI debug overlay", "on", g.debugOverlay)
+	}
+	if g.debugOverlay && Input.JustPressed(ActionSlowMotion) {
+		g.slowMotion = !g.slowMotion
+	}
+}
+
+// stepWhilePaused runs one frame of play from the pause screen when the step
+// key is pressed or slow motion is due, then pauses again unless the frame
+// ended the round or the game
+func (g *Game) stepWhilePaused() error {
+	if !g.debugOverlay || g.net != nil {
+		return nil
+	}
+	if !Input.JustPressed(ActionStepFrame) && !(g.slowMotion && g.globalTimer%slowMotionEvery == 0) {
+		return nil
+	}
+	g.State = g.playState()
+	var err error
+	if g.State == StateBoss {
+		err = g.updateBoss()
+	} else {
+		err = g.updateGame()
+	}
+	if g.State == StatePlaying || g.State == StateBoss {
+		g.State = StatePaused
+	}
+	return err
+}
+
+// drawAIDebug draws the overlay over the maze, in maze pixels
+func (g *Game) drawAIDebug(screen *ebiten.Image) {
+	for i, ghost := range g.Ghosts {
+		if ghost == nil || !ghost.Visible {
+			continue
+		}
+		ghost.drawAIDebug(screen, g.gameState, aiDebugColors[i%len(aiDebugColors)])
+	}
+
+	if g.Player == nil {
+		return
+	}
+	// The tile Player.Update eats from, and the circle CollideWithPlayer uses
+	tx := int(g.Player.X+TileSize/2) / TileSize
+	ty := int(g.Player.Y+TileSize/2) / TileSize
+	vector.StrokeRect(screen, float32(tx*TileSize)+1, float32(ty*TileSize)+1, TileSize-2, TileSize-2, 2, color.RGBA{255, 255, 0, 255}, false)
+	vector.StrokeCircle(screen, float32(g.Player.X)+playerCollisionRadius, float32(g.Player.Y)+playerCollisionRadius,
+		playerCollisionRadius, 1, color.RGBA{255, 255, 0, 255}, false)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("(%d,%d)", tx, ty), int(g.Player.X), int(g.Player.Y)+TileSize)
+}
+
+// drawDebugPauseHint is shown instead of the pause screen's dimming while the
+// overlay is on, so the overlay stays readable
+func (g *Game) drawDebugPauseHint(screen *ebiten.Image) {
+	field := playfieldRect()
+	hint := "PAUSED  . STEP  F4 SLOW MOTION  ESC RESUME"
+	if g.slowMotion {
+		hint = fmt.Sprintf("SLOW MOTION 1/%d  . STEP  F4 STOP  ESC RESUME", slowMotionEvery)
+	}
+	ebitenutil.DebugPrintAt(screen, hint, field.Min.X+4, field.Min.Y+4)
+}
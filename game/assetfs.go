@@ -0,0 +1,93 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+)
+
+// Everything the manifest references is compiled into the binary, so the game
+// runs from any working directory. Screenshots and unused art stay on disk only.
+//
+//go:embed assets/manifest.json
+//go:embed assets/PressStart2P-Regular.ttf
+//go:embed assets/wall.png assets/floor.png assets/player.png assets/Cherry.png
+//go:embed assets/jogo.png assets/sakuna.png assets/kenjaku.png assets/mahito.png
+//go:embed assets/cursed_bg.png assets/gojo.gif assets/jjk_title.png
+//go:embed assets/gojo_intro.png assets/sakuna_intro.png assets/kenjaku_intro.png assets/mahito_intro.png
+//go:embed assets/audio/*.ogg assets/audio/*.wav
+var embeddedAssets embed.FS
+
+// The theme packs that ship with the game, one directory each under themes/
+//
+//go:embed themes
+var embeddedThemes embed.FS
+
+// overlayFS looks a file up in each layer in turn, so earlier layers replace
+// individual files of later ones without having to provide a full asset set.
+type overlayFS struct {
+	layers []fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	var firstErr error
+	for _, layer := range o.layers {
+		f, err := layer.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// embeddedAssetFS returns the embedded assets rooted at the assets directory
+func embeddedAssetFS() fs.FS {
+	sub, err := fs.Sub(embeddedAssets, "assets")
+	if err != nil {
+		// Only possible if the embed directive above is broken
+		log.Fatalf("embedded assets unavailable: %v", err)
+	}
+	return sub
+}
+
+// builtinThemeFS returns the embedded theme packs rooted at the themes directory
+func builtinThemeFS() fs.FS {
+	sub, err := fs.Sub(embeddedThemes, "themes")
+	if err != nil {
+		log.Fatalf("embedded themes unavailable: %v", err)
+	}
+	return sub
+}
+
+// newAssetFS builds the filesystem the asset loader reads from. overrideDir,
+// when set, is layered on top of the embedded files so modders can swap single
+// files (e.g. audio/game_theme.ogg) without rebuilding. A theme pack sits
+// between the two.
+func newAssetFS(overrideDir string, theme *ThemePack) fs.FS {
+	layers := []fs.FS{embeddedAssetFS()}
+	if theme != nil {
+		layers = append([]fs.FS{theme.FS}, layers...)
+	}
+
+	if overrideDir != "" {
+		if info, err := os.Stat(overrideDir); err != nil || !info.IsDir() {
+			assetsLog.Warn("asset override directory not usable, ignoring it", "dir", overrideDir)
+		} else {
+			assetsLog.Info("using asset overrides", "dir", overrideDir)
+			layers = append([]fs.FS{os.DirFS(overrideDir)}, layers...)
+		}
+	}
+
+	if len(layers) == 1 {
+		return layers[0]
+	}
+	return overlayFS{layers: layers}
+}
@@ -5,16 +5,17 @@ import (
     "image"
     "image/gif"
     "image/draw"
-    "image/color"
-    "os"
+    "io"
+    "io/fs"
     "log"
+    "path"
     _ "image/png"
     "github.com/hajimehoshi/ebiten/v2"
-    "github.com/hajimehoshi/ebiten/v2/ebitenutil"
     _ "github.com/hajimehoshi/ebiten/v2/text"
     _ "embed"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
+	"jk/maze"
 	//"io/ioutil"
 )
 //go:embed assets/PressStart2P-Regular.ttf
@@ -25,44 +26,13 @@ var (
 	PressStartFont *opentype.Font
 )
 const (
-    TileEmpty       = 0
-    TileWall        = 1
-    TilePellet      = 2
-    TilePlayer      = 3
-    TilePowerPellet = 4
+    TileEmpty       = maze.Empty
+    TileWall        = maze.Wall
+    TilePellet      = maze.Pellet
+    TilePlayer      = maze.Player
+    TilePowerPellet = maze.PowerPellet
 )
-var level = [][]int{
-    {1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1},
-    {1,2,2,2,2,2,2,2,2,2,2,2,1,2,2,2,2,2,2,2,2,2,2,2,2,2,1},
-    {1,4,1,1,1,1,2,1,1,1,1,2,1,2,1,1,1,1,2,1,1,1,1,1,4,2,1},
-    {1,2,1,1,1,1,2,1,1,1,1,2,1,2,1,1,1,1,2,1,1,1,1,1,2,2,1},
-    {1,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,1},
-    {1,2,1,1,1,1,2,1,1,2,1,1,1,1,1,2,1,1,2,1,1,1,1,1,2,2,1},
-    {1,2,1,1,1,1,2,1,1,2,1,1,1,1,1,2,1,1,2,1,1,1,1,1,2,2,1},
-    {1,2,2,2,2,2,2,1,1,2,2,2,1,2,2,2,1,1,2,2,2,2,2,2,2,2,1},
-    {1,1,1,1,1,1,2,1,1,1,1,0,1,0,1,1,1,1,2,1,1,1,1,1,1,1,1},
-    {0,0,0,0,0,1,2,1,1,1,1,0,1,0,1,1,1,1,2,1,0,0,0,0,0,0,0},
-    {0,0,0,0,0,1,2,1,1,0,0,0,0,0,0,0,1,1,2,1,0,0,0,0,0,0,0},
-    {0,0,0,0,0,1,2,1,1,0,1,1,0,1,1,0,1,1,2,1,0,0,0,0,0,0,0},
-    {1,1,1,1,1,1,2,1,1,0,1,0,0,0,1,0,1,1,2,1,1,1,1,1,1,1,1},
-    {0,0,0,0,0,0,2,0,0,0,1,0,0,0,1,0,0,0,2,0,0,0,0,0,0,0,0},
-    {1,1,1,1,1,1,2,1,1,0,1,0,0,0,1,0,1,1,2,1,1,1,1,1,1,1,1},
-    {0,0,0,0,0,1,2,1,1,0,1,1,1,1,1,0,1,1,2,1,0,0,0,0,0,0,0},
-    {0,0,0,0,0,1,2,1,1,0,0,0,0,0,0,0,1,1,2,1,0,0,0,0,0,0,0},
-    {0,0,0,0,0,1,2,1,1,1,1,0,1,0,1,1,1,1,2,1,0,0,0,0,0,0,0},
-    {1,1,1,1,1,1,2,1,1,1,1,0,1,0,1,1,1,1,2,1,1,1,1,1,1,1,1},
-    {1,2,2,2,2,2,2,2,2,2,2,2,1,2,2,2,2,2,2,2,2,2,2,2,2,2,1},
-    {1,2,1,1,1,1,2,1,1,1,1,2,1,2,1,1,1,1,2,1,1,1,1,1,2,2,1},
-    {1,2,1,1,1,1,2,1,1,1,1,2,1,2,1,1,1,1,2,1,1,1,1,1,2,2,1},
-    {1,4,2,2,1,1,2,2,2,2,2,2,2,2,2,2,2,2,2,1,1,2,2,2,4,2,1},
-    {1,1,1,2,1,1,2,1,1,2,1,1,1,1,1,2,1,1,2,1,1,2,1,1,1,1,1},
-    {1,1,1,2,1,1,2,1,1,2,1,1,1,1,1,2,1,1,2,1,1,2,1,1,1,1,1},
-    {1,2,2,2,2,2,2,1,1,2,2,2,1,2,2,2,1,1,2,2,2,2,2,2,2,2,1},
-    {1,2,1,1,1,1,1,1,1,1,1,2,1,2,1,1,1,1,1,1,1,1,1,1,2,2,1},
-    {1,2,1,1,1,1,1,1,1,1,1,2,1,2,1,1,1,1,1,1,1,1,1,1,2,2,1},
-    {1,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,2,1},
-    {1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1,1},
-}
+var level = maze.Classic()
 var (
     WallImage    *ebiten.Image
     // PelletImage  *ebiten.Image
@@ -70,129 +40,267 @@ var (
     PlayerImage  *ebiten.Image
 )
 
-func LoadAssets() {
-    var err error
-    fmt.Println("Loading wall image from assets/wall.png")
+// Assets is the shared loader every image, animation, sound and font goes through
+var Assets *AssetLoader
+
+// AssetLoader resolves logical names from the manifest to files and caches the results.
+// Every lookup has the same failure behaviour: the problem is recorded in Errors,
+// logged once, and a placeholder (or error for non-image data) is returned.
+type AssetLoader struct {
+    FS       fs.FS
+    Manifest *AssetManifest
+    Errors   []string
+
+    images   map[string]*ebiten.Image
+    atlasOf  map[string]AtlasSpec
+    reported map[string]bool
+}
 
-    WallImage, _, err = ebitenutil.NewImageFromFile("assets/wall.png")
+// NewAssetLoader reads manifest.json from fsys; entries in overrides (a theme
+// pack's manifest, may be nil) replace the ones with the same name.
+func NewAssetLoader(fsys fs.FS, overrides *AssetManifest) (*AssetLoader, error) {
+    manifest, err := loadAssetManifest(fsys)
     if err != nil {
-        log.Fatal("failed to load wall.png",err)
+        return nil, err
     }
-    FloorImage, _, err = ebitenutil.NewImageFromFile("assets/floor.png")
-    if err != nil {
-        log.Fatal("failed to load floor.png",err)
+    if overrides != nil {
+        manifest = manifest.mergedWith(overrides)
     }
-    // PelletImage, _, err = ebitenutil.NewImageFromFile("assets/pellet.png")
-    // if err != nil {
-    //     log.Fatal("failed to load pellet",err)
-    // }
-    PlayerImage, _, err = ebitenutil.NewImageFromFile("assets/player.png")
-    if err != nil {
-        log.Fatal("failed to load player",err)
-    }
-    ttf, err := opentype.Parse(fontBytes)
-	if err != nil {
-		log.Fatalf("failed to parse font: %v", err)
-	}
-
-	bigfont, err = opentype.NewFace(ttf, &opentype.FaceOptions{
-		Size:    24,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		log.Fatalf("failed to create font face: %v", err)
-	}
-
-    // PelletImage = ebiten.NewImage(8,8)
-    // PelletImage.Fill(color.White)
+
+    l := &AssetLoader{
+        FS:       fsys,
+        Manifest: manifest,
+        images:   make(map[string]*ebiten.Image),
+        atlasOf:  make(map[string]AtlasSpec),
+        reported: make(map[string]bool),
+    }
+    for _, atlas := range manifest.Atlases {
+        for _, name := range atlas.Images {
+            l.atlasOf[name] = atlas
+        }
+    }
+    return l, nil
 }
 
-func loadImage(path string) *ebiten.Image {
-    fmt.Printf("Trying to load image: %s\n", path)
+// Directory given with --assets; its files win over theme packs and the embedded assets
+var assetOverrideDir string
 
-    // Check if file exists first
-    if _, err := os.Stat(path); os.IsNotExist(err) {
-        log.Printf("Warning: Image file does not exist: %s", path)
-        // Return a placeholder image
-        placeholder := ebiten.NewImage(100, 100)
-        placeholder.Fill(color.RGBA{128, 128, 128, 255}) // Gray placeholder
-        return placeholder
+// LoadAssets (re)loads every shared asset for the given theme pack (nil for the built-in theme)
+func LoadAssets(theme *ThemePack) {
+    var overrides *AssetManifest
+    if theme != nil {
+        overrides = &theme.Manifest.AssetManifest
     }
 
-    f, err := os.Open(path)
+    var err error
+    Assets, err = NewAssetLoader(newAssetFS(assetOverrideDir, theme), overrides)
     if err != nil {
-        log.Printf("Warning: Failed to open image file %s: %v", path, err)
-        // Return a placeholder image
-        placeholder := ebiten.NewImage(100, 100)
-        placeholder.Fill(color.RGBA{128, 128, 128, 255}) // Gray placeholder
-        return placeholder
+        log.Fatalf("failed to load asset manifest: %v", err)
+    }
+    ActiveTheme = theme
+    applyThemeStyle(theme)
+
+    WallImage = Assets.Image("tile.wall")
+    FloorImage = Assets.Image("tile.floor")
+    PlayerImage = Assets.Image("player")
+
+    LoadFont()
+
+    if len(Assets.Errors) > 0 {
+        assetsLog.Warn("asset loading finished with problems, run validate-assets for details", "problems", len(Assets.Errors))
+    }
+}
+
+// report records a loading problem once per asset
+func (l *AssetLoader) report(name string, err error) {
+    if l.reported[name] {
+        return
+    }
+    l.reported[name] = true
+    msg := fmt.Sprintf("%s: %v", name, err)
+    l.Errors = append(l.Errors, msg)
+    assetsLog.Warn("asset problem", "asset", name, "err", err)
+}
+
+// Open opens a file relative to the asset root
+func (l *AssetLoader) Open(file string) (io.ReadCloser, error) {
+    return l.FS.Open(path.Clean(file))
+}
+
+func (l *AssetLoader) decode(file string) (image.Image, error) {
+    f, err := l.Open(file)
+    if err != nil {
+        return nil, err
     }
     defer f.Close()
 
-    img, format, err := image.Decode(f)
+    img, _, err := image.Decode(f)
     if err != nil {
-        log.Printf("Warning: Failed to decode image %s: %v", path, err)
-        // Return a placeholder image
-        placeholder := ebiten.NewImage(100, 100)
-        placeholder.Fill(color.RGBA{128, 128, 128, 255}) // Gray placeholder
-        return placeholder
+        return nil, fmt.Errorf("failed to decode %s: %v", file, err)
     }
+    return img, nil
+}
 
-    fmt.Printf("Successfully loaded image: %s (format: %s)\n", path, format)
-    return ebiten.NewImageFromImage(img)
+func placeholderImage(fb AssetFallback) *ebiten.Image {
+    img := ebiten.NewImage(fb.Width, fb.Height)
+    img.Fill(fb.rgba())
+    return img
 }
 
-func LoadFont() {
-    var err error
-    PressStartFont, err = opentype.Parse(fontBytes)
+// Image returns the image registered under name, or its placeholder if it can't be loaded
+func (l *AssetLoader) Image(name string) *ebiten.Image {
+    if img, ok := l.images[name]; ok {
+        return img
+    }
+
+    entry, ok := l.Manifest.Images[name]
+    if !ok {
+        l.report(name, fmt.Errorf("not listed in %s", assetManifestFile))
+        img := placeholderImage(defaultAssetFallback)
+        l.images[name] = img
+        return img
+    }
+
+    if atlas, ok := l.atlasOf[name]; ok {
+        l.buildAtlas(atlas)
+        if img, ok := l.images[name]; ok {
+            return img
+        }
+    }
+
+    src, err := l.decode(entry.File)
     if err != nil {
-        log.Printf("Warning: Failed to parse embedded font: %v", err)
-        return
+        l.report(name, err)
+        img := placeholderImage(entry.fallbackFor())
+        l.images[name] = img
+        return img
     }
 
-    bigfont, err = opentype.NewFace(PressStartFont, &opentype.FaceOptions{
-        Size:    24,
-        DPI:     72,
-        Hinting: font.HintingFull,
-    })
+    img := ebiten.NewImageFromImage(src)
+    l.images[name] = img
+    return img
+}
+
+// buildAtlas decodes every image of an atlas, packs them into one texture and
+// caches a sub-image per name. Images that fail to load get their own placeholder.
+func (l *AssetLoader) buildAtlas(atlas AtlasSpec) {
+    sources := make(map[string]image.Image)
+    for _, name := range atlas.Images {
+        entry, ok := l.Manifest.Images[name]
+        if !ok {
+            continue
+        }
+        src, err := l.decode(entry.File)
+        if err != nil {
+            l.report(name, err)
+            l.images[name] = placeholderImage(entry.fallbackFor())
+            continue
+        }
+        sources[name] = src
+    }
+
+    canvas, rects, err := packAtlas(atlas.Size, atlas.Images, sources)
     if err != nil {
-        log.Printf("Warning: Failed to create font face: %v", err)
+        // Too big to pack: fall back to one texture per image
+        l.report("atlas "+atlas.Name, err)
+        for name, src := range sources {
+            l.images[name] = ebiten.NewImageFromImage(src)
+        }
+        return
+    }
+
+    sheet := ebiten.NewImageFromImage(canvas)
+    for name, r := range rects {
+        l.images[name] = sheet.SubImage(r).(*ebiten.Image)
     }
 }
 
-func LoadGIF(path string) ([]*ebiten.Image, error) {
-    fmt.Printf("Trying to load GIF: %s\n", path)
+// Animation returns every frame of a GIF animation
+func (l *AssetLoader) Animation(name string) ([]*ebiten.Image, error) {
+    entry, ok := l.Manifest.Animations[name]
+    if !ok {
+        err := fmt.Errorf("not listed in %s", assetManifestFile)
+        l.report(name, err)
+        return nil, err
+    }
 
-    // Check if file exists first
-    if _, err := os.Stat(path); os.IsNotExist(err) {
-        return nil, fmt.Errorf("GIF file does not exist: %s", path)
+    frames, err := l.decodeGIF(entry.File)
+    if err != nil {
+        l.report(name, err)
+        return nil, err
     }
+    return frames, nil
+}
 
-    f, err := os.Open(path)
+func (l *AssetLoader) decodeGIF(file string) ([]*ebiten.Image, error) {
+    f, err := l.Open(file)
     if err != nil {
-        return nil, fmt.Errorf("failed to open GIF file %s: %v", path, err)
+        return nil, err
     }
     defer f.Close()
 
     g, err := gif.DecodeAll(f)
     if err != nil {
-        return nil, fmt.Errorf("failed to decode GIF %s: %v", path, err)
+        return nil, fmt.Errorf("failed to decode GIF %s: %v", file, err)
     }
 
     if len(g.Image) == 0 {
-        return nil, fmt.Errorf("GIF has no frames: %s", path)
+        return nil, fmt.Errorf("GIF has no frames: %s", file)
     }
 
     var frames []*ebiten.Image
-    for i, src := range g.Image {
+    for _, src := range g.Image {
         rgba := image.NewRGBA(src.Bounds())
         draw.Draw(rgba, rgba.Bounds(), src, image.Point{}, draw.Over)
-        ebitenImg := ebiten.NewImageFromImage(rgba)
-        frames = append(frames, ebitenImg)
-        fmt.Printf("Loaded GIF frame %d/%d\n", i+1, len(g.Image))
+        frames = append(frames, ebiten.NewImageFromImage(rgba))
     }
 
-    fmt.Printf("Successfully loaded GIF: %s (%d frames)\n", path, len(frames))
+    assetsLog.Debug("loaded GIF", "file", file, "frames", len(frames))
     return frames, nil
 }
+
+// FontBytes returns the raw font file, falling back to the embedded PressStart2P
+func (l *AssetLoader) FontBytes(name string) []byte {
+    entry, ok := l.Manifest.Fonts[name]
+    if !ok {
+        l.report("font "+name, fmt.Errorf("not listed in %s", assetManifestFile))
+        return fontBytes
+    }
+
+    f, err := l.Open(entry.File)
+    if err == nil {
+        defer f.Close()
+        var data []byte
+        if data, err = io.ReadAll(f); err == nil {
+            return data
+        }
+    }
+    l.report("font "+name, err)
+    return fontBytes
+}
+
+func LoadFont() {
+    data := fontBytes
+    if Assets != nil {
+        data = Assets.FontBytes("main")
+    }
+
+    // Sized faces are rebuilt from the new font on next use
+    resetFontFaces()
+
+    var err error
+    PressStartFont, err = opentype.Parse(data)
+    if err != nil {
+        assetsLog.Warn("failed to parse font", "err", err)
+        return
+    }
+
+    bigfont, err = opentype.NewFace(PressStartFont, &opentype.FaceOptions{
+        Size:    24,
+        DPI:     72,
+        Hinting: font.HintingFull,
+    })
+    if err != nil {
+        assetsLog.Warn("failed to create font face", "err", err)
+    }
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// Padding between packed sprites so linear filtering never bleeds neighbours in
+const atlasPadding = 1
+
+// shelfPacker places rectangles row by row, opening a new shelf when a row is full
+type shelfPacker struct {
+	size        int
+	x, y        int
+	shelfHeight int
+}
+
+func (p *shelfPacker) place(w, h int) (image.Rectangle, bool) {
+	if w > p.size || h > p.size {
+		return image.Rectangle{}, false
+	}
+	if p.x+w > p.size {
+		p.x = 0
+		p.y += p.shelfHeight + atlasPadding
+		p.shelfHeight = 0
+	}
+	if p.y+h > p.size {
+		return image.Rectangle{}, false
+	}
+
+	r := image.Rect(p.x, p.y, p.x+w, p.y+h)
+	p.x += w + atlasPadding
+	if h > p.shelfHeight {
+		p.shelfHeight = h
+	}
+	return r, true
+}
+
+// packAtlas copies the given images into one size x size canvas.
+// Images are placed tallest first, which keeps shelves reasonably full.
+func packAtlas(size int, names []string, images map[string]image.Image) (*image.RGBA, map[string]image.Rectangle, error) {
+	order := make([]string, 0, len(names))
+	for _, name := range names {
+		if images[name] != nil {
+			order = append(order, name)
+		}
+	}
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && images[order[j]].Bounds().Dy() > images[order[j-1]].Bounds().Dy(); j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, size, size))
+	packer := &shelfPacker{size: size}
+	rects := make(map[string]image.Rectangle, len(order))
+
+	for _, name := range order {
+		src := images[name]
+		b := src.Bounds()
+		r, ok := packer.place(b.Dx(), b.Dy())
+		if !ok {
+			return nil, nil, fmt.Errorf("%s (%dx%d) does not fit in a %dx%d atlas", name, b.Dx(), b.Dy(), size, size)
+		}
+		draw.Draw(canvas, r, src, b.Min, draw.Src)
+		rects[name] = r
+	}
+
+	return canvas, rects, nil
+}
@@ -6,8 +6,6 @@ import (
     "bytes"
     "fmt"
     "io"
-    "log"
-    "os"
     "path/filepath"
     "time"
     
@@ -54,25 +52,25 @@ var globalAudioSystem *AudioSystem
 func NewAudioSystem() *AudioSystem {
     // Initialize audio context with error handling for PipeWire systems
     if globalAudioCtx == nil {
-        fmt.Println("🎵 Creating new audio context for PipeWire...")
+        audioLog.Debug("creating audio context")
         
         // Try PipeWire-friendly sample rates
         sampleRates := []int{48000, 44100, 22050}
         var audioCtx *audio.Context
         
         for _, rate := range sampleRates {
-            fmt.Printf("🎵 Trying sample rate: %d Hz\n", rate)
+            audioLog.Debug("trying sample rate", "hz", rate)
             audioCtx = audio.NewContext(rate)
             if audioCtx != nil {
-                fmt.Printf("✅ Successfully created audio context at %d Hz\n", rate)
+                audioLog.Info("created audio context", "hz", rate)
                 globalAudioCtx = audioCtx
                 break
             }
         }
         
         if globalAudioCtx == nil {
-            fmt.Println("❌ Failed to create audio context with any sample rate")
-            fmt.Println("💡 Try running with: SDL_AUDIODRIVER=pulse ./your-game")
+            audioLog.Error("failed to create audio context with any sample rate",
+                "hint", "try running with SDL_AUDIODRIVER=pulse")
             // Return a dummy audio system that won't crash
             return &AudioSystem{
                 AudioContext: nil,
@@ -103,59 +101,32 @@ func NewAudioSystem() *AudioSystem {
         LoadErrors:   make([]string, 0),
     }
     
-    fmt.Printf("🎵 AudioSystem created successfully (Sample Rate: %d Hz)\n", globalAudioCtx.SampleRate())
+    audioLog.Debug("audio system created", "hz", globalAudioCtx.SampleRate())
     return audioSystem
 }
 
 func (a *AudioSystem) LoadAllAudio() {
     // Safety check
     if a == nil {
-        fmt.Println("❌ Error: AudioSystem is nil")
+        audioLog.Error("audio system is nil")
         return
     }
     
     // Check if audio context is available
     if a.AudioContext == nil {
-        fmt.Println("⚠️  Audio context is nil, skipping audio loading")
+        audioLog.Warn("audio context is nil, skipping audio loading")
         a.AssetsLoaded = true
         a.BGMEnabled = false
         a.SFXEnabled = false
         return
     }
     
-    fmt.Println("🎵 Loading audio assets...")
+    audioLog.Debug("loading audio assets")
      
     
-    // Define audio files to load
-    bgmFiles := map[string]string{
-        "intro_theme":     "assets/audio/intro_theme.ogg",
-        "menu_theme":      "assets/audio/menu_theme.ogg", 
-        "game_theme":      "assets/audio/game_theme.ogg",
-        "power_mode":      "assets/audio/power_mode.ogg",
-        "boss_theme":      "assets/audio/boss_theme.ogg",
-    }
-    
-    sfxFiles := map[string]string{
-        "menu_select":         "assets/audio/menu_select.wav",
-        "transition":          "assets/audio/transition.wav",
-        "character_reveal":    "assets/audio/character_reveal.wav",
-        "game_start":          "assets/audio/game_start.wav",
-        "round_start":         "assets/audio/round_start.wav",
-        "round_complete":      "assets/audio/round_complete.wav",
-        "pellet_eat":          "assets/audio/pellet_eat.wav",
-        "power_pellet":        "assets/audio/power_pellet.wav",
-        "power_pellet_warning": "assets/audio/power_warning.wav",
-        "power_pellet_end":    "assets/audio/power_end.wav",
-        "ghost_eaten":         "assets/audio/ghost_eaten.wav",
-        "player_death":        "assets/audio/player_death.wav",
-        "game_over":           "assets/audio/game_over.wav",
-        "pause":               "assets/audio/pause.wav",
-        "unpause":             "assets/audio/unpause.wav",
-    }
-    
     // Load BGM files
-    for name, path := range bgmFiles {
-        data, err := a.loadAudioFile(path)
+    for name, entry := range Assets.Manifest.Music {
+        data, err := a.loadAudioFile(entry.File)
         if err != nil {
         a.LoadErrors = append(a.LoadErrors, fmt.Sprintf("BGM %s: %v", name, err))
             // Create silence as fallback using actual context sample rate
@@ -169,8 +140,8 @@ func (a *AudioSystem) LoadAllAudio() {
     }
     
     // Load SFX files
-    for name, path := range sfxFiles {
-        data, err := a.loadAudioFile(path)
+    for name, entry := range Assets.Manifest.SFX {
+        data, err := a.loadAudioFile(entry.File)
         if err != nil {
             a.LoadErrors = append(a.LoadErrors, fmt.Sprintf("SFX %s: %v", name, err))
             // Create short beep as fallback
@@ -182,23 +153,17 @@ func (a *AudioSystem) LoadAllAudio() {
     a.AssetsLoaded = true
     
     if len(a.LoadErrors) > 0 {
-        fmt.Printf("⚠️  Audio loading completed with %d errors:\n", len(a.LoadErrors))
         for _, err := range a.LoadErrors {
-            fmt.Printf("   - %s\n", err)
+            audioLog.Warn("audio asset failed to load", "err", err)
         }
     } else {
-        fmt.Printf("✅ All audio assets loaded successfully!\n")
+        audioLog.Info("all audio assets loaded")
     }
 }
 
 func (a *AudioSystem) loadAudioFile(path string) ([]byte, error) {
-    // Check if file exists
-    if _, err := os.Stat(path); os.IsNotExist(err) {
-        return nil, fmt.Errorf("file not found: %s", path)
-    }
-    
-    // Open file
-    file, err := os.Open(path)
+    // Open file through the asset loader
+    file, err := Assets.Open(path)
     if err != nil {
         return nil, err
     }
@@ -235,7 +200,7 @@ func (a *AudioSystem) loadAudioFile(path string) ([]byte, error) {
         return nil, fmt.Errorf("failed to read audio data: %v", err)
     }
     
-    fmt.Printf("   Loaded: %s (%d bytes)\n", filepath.Base(path), len(data))
+    audioLog.Debug("loaded audio", "file", filepath.Base(path), "bytes", len(data))
     return data, nil
 }
 
@@ -278,7 +243,7 @@ func (a *AudioSystem) PlayBGM(name string) {
     // Get audio data
     data, exists := a.BGMData[name]
     if !exists {
-        log.Printf("BGM not found: %s", name)
+        audioLog.Warn("BGM not found", "name", name)
         return
     }
     
@@ -286,7 +251,7 @@ func (a *AudioSystem) PlayBGM(name string) {
     reader := bytes.NewReader(data)
     player, err := a.AudioContext.NewPlayer(reader)
     if err != nil {
-        log.Printf("Failed to create BGM player for %s: %v", name, err)
+        audioLog.Error("failed to create BGM player", "name", name, "err", err)
         return
     }
     
@@ -299,7 +264,7 @@ func (a *AudioSystem) PlayBGM(name string) {
     a.BGMPlayer.Play()
     
     // For looping BGM, we'll need to handle this in Update()
-    fmt.Printf("🎵 Playing BGM: %s\n", name)
+    audioLog.Debug("playing BGM", "name", name)
 }
 
 func (a *AudioSystem) PlaySFX(name string) {
@@ -310,7 +275,7 @@ func (a *AudioSystem) PlaySFX(name string) {
     // Get audio data
     data, exists := a.SFXData[name]
     if !exists {
-        log.Printf("SFX not found: %s", name)
+        audioLog.Warn("SFX not found", "name", name)
         return
     }
     
@@ -318,7 +283,7 @@ func (a *AudioSystem) PlaySFX(name string) {
     reader := bytes.NewReader(data)
     player, err := a.AudioContext.NewPlayer(reader)
     if err != nil {
-        log.Printf("Failed to create SFX player for %s: %v", name, err)
+        audioLog.Error("failed to create SFX player", "name", name, "err", err)
         return
     }
     
@@ -331,7 +296,7 @@ func (a *AudioSystem) PlaySFX(name string) {
     // Store reference (will be cleaned up in Update())
     a.SFXPlayers[name+fmt.Sprint(time.Now().UnixNano())] = player
     
-    fmt.Printf("🔊 Playing SFX: %s\n", name)
+    audioLog.Debug("playing SFX", "name", name)
 }
 
 func (a *AudioSystem) Update() {
@@ -399,7 +364,7 @@ func (a *AudioSystem) StopBGM() {
         a.BGMPlayer = nil
     }
     a.CurrentBGM = ""
-    fmt.Println("🎵 BGM stopped")
+    audioLog.Debug("BGM stopped")
 }
 
 func (a *AudioSystem) FadeBGM() {
@@ -422,7 +387,7 @@ func (a *AudioSystem) SetBGMVolume(volume float64) {
     if a.BGMPlayer != nil {
         a.BGMPlayer.SetVolume(a.BGMVolume)
     }
-    fmt.Printf("🎵 BGM Volume: %.1f\n", a.BGMVolume)
+    audioLog.Debug("BGM volume", "volume", a.BGMVolume)
 }
 
 func (a *AudioSystem) SetSFXVolume(volume float64) {
@@ -431,7 +396,7 @@ func (a *AudioSystem) SetSFXVolume(volume float64) {
     }
     
     a.SFXVolume = math.Max(0.0, math.Min(MaxVolume, volume))
-    fmt.Printf("🔊 SFX Volume: %.1f\n", a.SFXVolume)
+    audioLog.Debug("SFX volume", "volume", a.SFXVolume)
 }
 
 func (a *AudioSystem) ToggleBGM() {
@@ -443,7 +408,7 @@ func (a *AudioSystem) ToggleBGM() {
     if !a.BGMEnabled {
         a.StopBGM()
     }
-    fmt.Printf("🎵 BGM %s\n", map[bool]string{true: "enabled", false: "disabled"}[a.BGMEnabled])
+    audioLog.Debug("BGM toggled", "enabled", a.BGMEnabled)
 }
 
 func (a *AudioSystem) ToggleSFX() {
@@ -462,7 +427,7 @@ func (a *AudioSystem) ToggleSFX() {
         }
     }
     
-    fmt.Printf("🔊 SFX %s\n", map[bool]string{true: "enabled", false: "disabled"}[a.SFXEnabled])
+    audioLog.Debug("SFX toggled", "enabled", a.SFXEnabled)
 }
 
 func (a *AudioSystem) GetStatus() string {
@@ -496,6 +461,10 @@ func (a *AudioSystem) PlayIntroMusic() {
     a.PlayBGM("intro_theme")
 }
 
+func (a *AudioSystem) PlayBossMusic() {
+    a.PlayBGM("boss_theme")
+}
+
 func (a *AudioSystem) PlayPowerMode() {
     a.PlayBGM("power_mode")
 }
@@ -531,7 +500,7 @@ func (a *AudioSystem) Cleanup() {
         delete(a.SFXPlayers, key)
     }
     
-    fmt.Println("🎵 Audio system cleaned up")
+    audioLog.Debug("audio system cleaned up")
 }
 
 // Integration helper: Replace the simple SoundManager in your existing code
@@ -543,11 +512,11 @@ type SoundManager struct {
 }
 
 func NewSoundManager() *SoundManager {
-    fmt.Println("🔊 Creating new SoundManager...")
+    audioLog.Debug("creating sound manager")
     audioSys := NewAudioSystem()
     
     if audioSys == nil {
-        fmt.Println("❌ Failed to create AudioSystem for SoundManager")
+        audioLog.Error("failed to create audio system for sound manager")
         return &SoundManager{
             AudioSystem: nil,
             BGMEnabled:  true,
@@ -556,7 +525,6 @@ func NewSoundManager() *SoundManager {
         }
     }
     
-    fmt.Println("✅ SoundManager created successfully")
     return &SoundManager{
         AudioSystem: audioSys,
         BGMEnabled:  true,
@@ -0,0 +1,687 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Every few rounds of a one-player game Sukuna comes out to fight in person
+// instead of the usual curses. He is too big for the corridors and floats
+// over the walls after Gojo, slashes along whole rows and columns, sets
+// patches of the maze alight and calls the other curses up as minions.
+// Only a powered-up Gojo can hurt him, one hit per power pellet; a new power
+// pellet turns up whenever the maze runs out of them.
+const (
+	bossRoundEvery = 4 // every fourth round is a boss round
+
+	bossSize          = 2 * TileSize
+	bossHP            = 6
+	bossHitPoints     = 1000 // for each hit
+	bossPoints        = 5000 // for beating him
+	bossHitFrames     = 120  // he retreats home and can't be hit after a hit
+	bossGraceFrames   = 90   // nor catch Gojo after catching him
+	bossPelletFrames  = 300  // wait before a new power pellet appears
+	bossBannerFrames  = 120  // phase name shown
+	bossVictoryFrames = 240
+)
+
+type bossAttackKind int
+
+const (
+	attackSweep bossAttackKind = iota // a slash along a row or column
+	attackBurn                        // patches of tiles that burn for a while
+)
+
+// Attack timings, in frames
+const (
+	sweepWarnFrames = 60
+	sweepTileFrames = 2  // the slash moves on a tile this often
+	sweepHotFrames  = 12 // and each tile stays deadly this long
+	burnWarnFrames  = 90
+	burnHotFrames   = 300
+	burnPatches     = 3 // 3x3 patches, the first on Gojo
+)
+
+// bossPhase is how Sukuna fights from an HP down
+type bossPhase struct {
+	Name        string
+	FromHP      int
+	Speed       float64          // pixels a frame
+	AttackEvery int              // frames between attacks
+	Attacks     []bossAttackKind // used in turn
+	Minions     []string         // curses summoned as the phase starts
+}
+
+var bossPhases = []bossPhase{
+	{Name: "DISMANTLE", FromHP: 6, Speed: 0.6, AttackEvery: 300,
+		Attacks: []bossAttackKind{attackSweep}},
+	{Name: "CLEAVE", FromHP: 4, Speed: 0.8, AttackEvery: 240,
+		Attacks: []bossAttackKind{attackSweep, attackBurn}, Minions: []string{"jogo", "mahito"}},
+	{Name: "MALEVOLENT SHRINE", FromHP: 2, Speed: 1.0, AttackEvery: 180,
+		Attacks: []bossAttackKind{attackBurn, attackSweep, attackSweep}, Minions: []string{"kenjaku"}},
+}
+
+// bossAttack marks tiles that turn deadly after a warning
+type bossAttack struct {
+	Kind  bossAttackKind
+	Tiles []attackTile
+	Warn  int // frames of warning before the first tile turns
+	Hot   int // frames each tile stays deadly
+	age   int
+}
+
+type attackTile struct {
+	X, Y int
+	At   int // frames after the warning that the tile turns deadly
+}
+
+func (a *bossAttack) hot(t attackTile) bool {
+	start := a.Warn + t.At
+	return a.age >= start && a.age < start+a.Hot
+}
+
+func (a *bossAttack) done() bool {
+	last := 0
+	for _, t := range a.Tiles {
+		last = max(last, t.At)
+	}
+	return a.age >= a.Warn+last+a.Hot
+}
+
+// Boss is Sukuna in a boss round. Unlike a Ghost he is two tiles wide,
+// goes through walls and takes several hits.
+type Boss struct {
+	X, Y  float64 // top left, in maze pixels
+	HP    int
+	Image *ebiten.Image
+
+	phase       int
+	home        [2]float64 // where he starts and retreats to
+	hitTimer    int        // frames of retreat left after a hit
+	graceTimer  int        // frames left before he can catch Gojo again
+	attackTimer int        // frames to the next attack
+	attackTurn  int
+	attacks     []*bossAttack
+	minions     []*Ghost
+	pelletTimer int // frames without a power pellet on the maze
+	banner      int // frames left showing the phase name
+	victory     int // frames into the victory sequence, 0 while fighting
+}
+
+// newBoss puts Sukuna in the middle of the ghost house
+func newBoss() *Boss {
+	x := float64(GHOST_HOUSE_X*TileSize + TileSize/2 - bossSize/2)
+	y := float64(GHOST_HOUSE_Y*TileSize + TileSize/2 - bossSize/2)
+	b := &Boss{
+		X: x, Y: y, HP: bossHP, home: [2]float64{x, y},
+		attackTimer: bossPhases[0].AttackEvery,
+		banner:      bossBannerFrames,
+	}
+	if Assets != nil {
+		b.Image = Assets.Image("boss.sukuna")
+	}
+	return b
+}
+
+func (b *Boss) Phase() bossPhase {
+	return bossPhases[b.phase]
+}
+
+func (b *Boss) center() (float64, float64) {
+	return b.X + bossSize/2, b.Y + bossSize/2
+}
+
+// bossRounds reports whether the game in progress has boss rounds; games
+// under other rules or with more than one player don't
+func (g *Game) bossRounds() bool {
+	return g.rules == nil && !g.twoPlayer && g.versus == nil && g.net == nil
+}
+
+// bossRoundDue reports whether the current round is a boss round
+func (g *Game) bossRoundDue() bool {
+	return g.RoundNumber%bossRoundEvery == 0 && g.bossRounds()
+}
+
+// startBoss makes the current round a boss round
+func (g *Game) startBoss() {
+	g.boss = newBoss()
+	stateLog.Info("boss round", "round", g.RoundNumber)
+}
+
+// playState is the state play goes on in, after the round ready screen or a pause
+func (g *Game) playState() GameState {
+	if g.boss != nil {
+		return StateBoss
+	}
+	return StatePlaying
+}
+
+// updateBoss runs a frame of a boss round, in place of updateGame
+func (g *Game) updateBoss() error {
+	b := g.boss
+	if b.victory > 0 {
+		return g.updateBossVictory()
+	}
+	if g.net == nil && Input.JustPressed(ActionPause) {
+		g.State = StatePaused
+		if g.AudioSystem != nil {
+			g.AudioSystem.PlaySFX("pause")
+		}
+		return nil
+	}
+
+	if g.Player != nil {
+		g.Player.Update(level, TileSize)
+	}
+	g.updateBossPower()
+	b.move(g)
+	g.updateBossAttacks()
+	if g.State != StateBoss {
+		return nil
+	}
+	for _, m := range b.minions {
+		m.Update(g.gameState)
+	}
+
+	timer := g.powerPelletTimer
+	g.checkPelletCollection()
+	if g.powerPelletTimer > timer {
+		for _, m := range b.minions {
+			if m.Visible {
+				m.SetFrightened(g.powerPelletTimer)
+			}
+		}
+	}
+	g.updateBossPellets()
+	g.checkBossCollisions()
+	if b.banner > 0 {
+		b.banner--
+	}
+	return nil
+}
+
+// updateBossPower counts down a power pellet, as updateGame does, but goes
+// back to the boss music
+func (g *Game) updateBossPower() {
+	if !g.powerPelletActive {
+		return
+	}
+	g.powerPelletTimer--
+	if g.powerPelletTimer == 120 && g.AudioSystem != nil {
+		g.AudioSystem.PlaySFX("power_pellet_warning")
+	}
+	if g.powerPelletTimer <= 0 {
+		g.stopBossPower()
+		if g.AudioSystem != nil {
+			g.AudioSystem.PlaySFX("power_pellet_end")
+			g.AudioSystem.StopBGM()
+			g.AudioSystem.PlayBossMusic()
+		}
+	}
+}
+
+// stopBossPower ends any power pellet and calms the minions
+func (g *Game) stopBossPower() {
+	g.powerPelletActive = false
+	g.powerPelletTimer = 0
+	g.gameState.FrightModeActive = false
+	for _, m := range g.boss.minions {
+		if m.Mode == FrightenedMode {
+			m.ResetMode()
+		}
+	}
+}
+
+// move floats Sukuna after Gojo, or home after a hit. He stands his ground
+// while Gojo is powered up.
+func (b *Boss) move(g *Game) {
+	if b.graceTimer > 0 {
+		b.graceTimer--
+	}
+	speed := b.Phase().Speed
+	tx, ty := g.Player.X+playerCollisionRadius, g.Player.Y+playerCollisionRadius
+	switch {
+	case b.hitTimer > 0:
+		b.hitTimer--
+		tx, ty = b.home[0]+bossSize/2, b.home[1]+bossSize/2
+		speed *= 3
+	case g.powerPelletActive:
+		return
+	}
+	cx, cy := b.center()
+	dx, dy := tx-cx, ty-cy
+	d := math.Hypot(dx, dy)
+	if d <= speed {
+		b.X, b.Y = tx-bossSize/2, ty-bossSize/2
+		return
+	}
+	b.X += dx / d * speed
+	b.Y += dy / d * speed
+}
+
+// updateBossAttacks starts the phase's next attack when it is due, ages the
+// ones under way and catches Gojo on a deadly tile
+func (g *Game) updateBossAttacks() {
+	b := g.boss
+	if b.hitTimer == 0 {
+		b.attackTimer--
+		if b.attackTimer <= 0 {
+			phase := b.Phase()
+			kind := phase.Attacks[b.attackTurn%len(phase.Attacks)]
+			b.attacks = append(b.attacks, g.newBossAttack(kind))
+			b.attackTurn++
+			b.attackTimer = phase.AttackEvery
+		}
+	}
+
+	px := int(g.Player.X+TileSize/2) / TileSize
+	py := int(g.Player.Y+TileSize/2) / TileSize
+	caught := false
+	live := b.attacks[:0]
+	for _, a := range b.attacks {
+		a.age++
+		for _, t := range a.Tiles {
+			if t.X == px && t.Y == py && a.hot(t) {
+				caught = true
+			}
+		}
+		if !a.done() {
+			live = append(live, a)
+		}
+	}
+	b.attacks = live
+	if caught && b.graceTimer == 0 {
+		g.bossCatch()
+	}
+}
+
+// newBossAttack aims an attack at Gojo: a sweep along his row or column,
+// alternately, from Sukuna's side, or burning patches on him and elsewhere
+func (g *Game) newBossAttack(kind bossAttackKind) *bossAttack {
+	b := g.boss
+	px := int(g.Player.X+TileSize/2) / TileSize
+	py := int(g.Player.Y+TileSize/2) / TileSize
+	w, h := len(level[0]), len(level)
+	open := func(x, y int) bool {
+		return x >= 0 && x < w && y >= 0 && y < h && level[y][x] != TileWall
+	}
+
+	if kind == attackSweep {
+		a := &bossAttack{Kind: kind, Warn: sweepWarnFrames, Hot: sweepHotFrames}
+		cx, cy := b.center()
+		row := b.attackTurn%2 == 0
+		n, flip := w, cx > float64(w*TileSize)/2
+		if !row {
+			n, flip = h, cy > float64(h*TileSize)/2
+		}
+		for i := 0; i < n; i++ {
+			pos := i
+			if flip {
+				pos = n - 1 - i
+			}
+			x, y := pos, py
+			if !row {
+				x, y = px, pos
+			}
+			if open(x, y) {
+				a.Tiles = append(a.Tiles, attackTile{X: x, Y: y, At: i * sweepTileFrames})
+			}
+		}
+		return a
+	}
+
+	a := &bossAttack{Kind: kind, Warn: burnWarnFrames, Hot: burnHotFrames}
+	rng := g.gameState.rng()
+	for p := 0; p < burnPatches; p++ {
+		x, y := px, py
+		if p > 0 {
+			x, y, _ = nearestOpenTile(level, rng.Intn(w), rng.Intn(h))
+		}
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if open(x+dx, y+dy) {
+					a.Tiles = append(a.Tiles, attackTile{X: x + dx, Y: y + dy})
+				}
+			}
+		}
+	}
+	return a
+}
+
+// checkBossCollisions lets a powered-up Gojo hit Sukuna and the minions
+// catch or be eaten as curses are
+func (g *Game) checkBossCollisions() {
+	b := g.boss
+	cx, cy := b.center()
+	d := math.Hypot(cx-(g.Player.X+playerCollisionRadius), cy-(g.Player.Y+playerCollisionRadius))
+	if d < bossSize/2+playerCollisionRadius/2 && b.hitTimer == 0 {
+		if g.powerPelletActive {
+			g.hitBoss()
+			return
+		}
+		if b.graceTimer == 0 {
+			g.bossCatch()
+			return
+		}
+	}
+
+	for _, m := range b.minions {
+		switch m.CollideWithPlayer(g.Player.X, g.Player.Y) {
+		case "ghost_eaten":
+			g.Player.Score += 200
+			if g.AudioSystem != nil {
+				g.AudioSystem.PlaySFX("ghost_eaten")
+			}
+		case "player_caught":
+			if b.graceTimer == 0 {
+				g.bossCatch()
+				return
+			}
+		}
+	}
+}
+
+// hitBoss takes a hit point off Sukuna, using up Gojo's power pellet, and
+// moves the fight on to the next phase when it is time
+func (g *Game) hitBoss() {
+	b := g.boss
+	b.HP--
+	b.hitTimer = bossHitFrames
+	b.attacks = nil
+	g.Player.Score += bossHitPoints
+	g.stopBossPower()
+	stateLog.Info("boss hit", "hp", b.HP)
+	if b.HP <= 0 {
+		g.startBossVictory()
+		return
+	}
+	if g.AudioSystem != nil {
+		g.AudioSystem.PlaySFX("ghost_eaten")
+		g.AudioSystem.StopBGM()
+		g.AudioSystem.PlayBossMusic()
+	}
+
+	for i, phase := range bossPhases {
+		if i > b.phase && b.HP <= phase.FromHP {
+			b.phase = i
+			b.banner = bossBannerFrames
+			b.attackTimer = phase.AttackEvery
+			g.summonMinions(phase.Minions)
+			stateLog.Info("boss phase", "phase", phase.Name)
+		}
+	}
+}
+
+// summonMinions calls curses up in the ghost house to join the fight
+func (g *Game) summonMinions(names []string) {
+	b := g.boss
+	for _, name := range names {
+		m := NewGhost(0, 0, "ghost."+name, name, 55)
+		m.SetScatterCorner(level)
+		b.minions = append(b.minions, m)
+	}
+	g.houseMinions()
+}
+
+// houseMinions puts the minions back in the ghost house to come out again
+func (g *Game) houseMinions() {
+	for i, m := range g.boss.minions {
+		t := ghostStartTiles[i%len(ghostStartTiles)]
+		m.X, m.Y = float64(t[0]*TileSize), float64(t[1]*TileSize)
+		m.SetMode(InHouseMode)
+		m.ReleaseTimer = 120 * (i + 1)
+	}
+}
+
+// bossCatch costs Gojo a life in a boss round and sends everyone back to
+// their places
+func (g *Game) bossCatch() {
+	if g.godMode {
+		return
+	}
+	b := g.boss
+	g.lives--
+	if g.AudioSystem != nil {
+		g.AudioSystem.PlaySFX("player_death")
+	}
+	g.resetPlayerPosition()
+	b.X, b.Y = b.home[0], b.home[1]
+	b.attacks = nil
+	b.graceTimer = bossGraceFrames
+	b.attackTimer = b.Phase().AttackEvery
+	g.houseMinions()
+	if g.ruleset().Over(g) {
+		g.endGame()
+	}
+}
+
+// updateBossPellets puts a power pellet back on the maze once there have
+// been none for a while, on the power pellet tile furthest from Sukuna, or
+// the furthest open tile if the maze has no power pellets of its own
+func (g *Game) updateBossPellets() {
+	b := g.boss
+	if g.powerPelletActive || powerPelletsIn(level) > 0 {
+		b.pelletTimer = 0
+		return
+	}
+	b.pelletTimer++
+	if b.pelletTimer < bossPelletFrames {
+		return
+	}
+	b.pelletTimer = 0
+
+	cx, cy := b.center()
+	best, bestD := [2]int{-1, -1}, -1.0
+	for _, power := range []bool{true, false} {
+		for y, row := range levelTemplate {
+			for x, tile := range row {
+				if (power && tile != TilePowerPellet) || tile == TileWall {
+					continue
+				}
+				d := math.Hypot(float64(x*TileSize+TileSize/2)-cx, float64(y*TileSize+TileSize/2)-cy)
+				if d > bestD {
+					best, bestD = [2]int{x, y}, d
+				}
+			}
+		}
+		if bestD >= 0 {
+			break
+		}
+	}
+	if bestD < 0 {
+		return
+	}
+	if level[best[1]][best[0]] != TilePellet {
+		g.pelletCount++
+	}
+	level[best[1]][best[0]] = TilePowerPellet
+}
+
+// powerPelletsIn counts the power pellets left in level
+func powerPelletsIn(level [][]int) int {
+	n := 0
+	for _, row := range level {
+		for _, tile := range row {
+			if tile == TilePowerPellet {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// startBossVictory begins the victory sequence: the minions vanish and
+// Sukuna breaks apart
+func (g *Game) startBossVictory() {
+	b := g.boss
+	b.victory = 1
+	b.minions = nil
+	g.Player.Score += bossPoints
+	stateLog.Info("boss defeated", "round", g.RoundNumber, "score", g.Player.Score)
+	if g.AudioSystem != nil {
+		g.AudioSystem.StopBGM()
+		g.AudioSystem.PlaySFX("character_reveal")
+	}
+}
+
+// updateBossVictory plays out the victory sequence, then goes on to the next round
+func (g *Game) updateBossVictory() error {
+	g.boss.victory++
+	if g.boss.victory < bossVictoryFrames {
+		return nil
+	}
+	g.RoundNumber++
+	g.State = StateRoundReady
+	g.ShowRoundReady = true
+	g.RoundReadyTimer = 0
+	g.startNextRound()
+	if g.AudioSystem != nil {
+		g.AudioSystem.PlaySFX("round_complete")
+	}
+	return nil
+}
+
+// Drawing
+
+var (
+	bossWarnColor = color.RGBA{255, 40, 40, 90}
+	bossHotColor  = color.RGBA{255, 120, 60, 200}
+	bossCutColor  = color.RGBA{255, 240, 240, 230}
+)
+
+// drawBoss draws the attacks, the minions and Sukuna, in maze pixels
+func (g *Game) drawBoss(screen *ebiten.Image) {
+	b := g.boss
+	if b == nil {
+		return
+	}
+	for _, a := range b.attacks {
+		for _, t := range a.Tiles {
+			x, y := float32(t.X*TileSize), float32(t.Y*TileSize)
+			switch {
+			case a.hot(t) && a.Kind == attackSweep:
+				vector.DrawFilledRect(screen, x, y+TileSize/2-3, TileSize, 6, bossCutColor, false)
+				vector.DrawFilledRect(screen, x, y, TileSize, TileSize, bossWarnColor, false)
+			case a.hot(t):
+				flicker := uint8(40 * math.Abs(math.Sin(float64(g.globalTimer+t.X*7+t.Y*3)*0.3)))
+				hot := bossHotColor
+				hot.G += flicker
+				vector.DrawFilledRect(screen, x+2, y+2, TileSize-4, TileSize-4, hot, false)
+			case a.age < a.Warn+t.At && (a.age/8)%2 == 0:
+				vector.DrawFilledRect(screen, x, y, TileSize, TileSize, bossWarnColor, false)
+				vector.StrokeRect(screen, x+1, y+1, TileSize-2, TileSize-2, 1, color.RGBA{255, 40, 40, 200}, false)
+			}
+		}
+	}
+	for _, m := range b.minions {
+		if m.Visible {
+			m.Draw(screen)
+		}
+	}
+	if b.Image == nil {
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	bounds := b.Image.Bounds()
+	op.GeoM.Scale(bossSize/float64(bounds.Dx()), bossSize/float64(bounds.Dy()))
+	x, y := b.X, b.Y
+	switch {
+	case b.victory > 0:
+		// Shakes harder and fades away
+		t := float64(b.victory) / bossVictoryFrames
+		x += 6 * t * math.Sin(float64(b.victory)*1.7)
+		y += 6 * t * math.Cos(float64(b.victory)*2.3)
+		op.ColorScale.Scale(1, 1-float32(t)*0.5, 1-float32(t)*0.5, 1)
+		op.ColorScale.ScaleAlpha(1 - float32(t))
+	case b.hitTimer > 0 && (b.hitTimer/6)%2 == 0:
+		op.ColorScale.Scale(3, 3, 3, 0.7)
+	case g.powerPelletActive:
+		op.ColorScale.Scale(0.5, 0.5, 1, 1)
+	case b.phase > 0:
+		// Redder with every phase
+		k := 1 - 0.2*float32(b.phase)
+		op.ColorScale.Scale(1, k, k, 1)
+	}
+	op.GeoM.Translate(x, y)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(b.Image, op)
+
+	if b.victory > 0 {
+		g.drawBossShards(screen)
+	}
+}
+
+// drawBossShards throws cursed energy out of Sukuna as he breaks apart
+func (g *Game) drawBossShards(screen *ebiten.Image) {
+	b := g.boss
+	cx, cy := b.center()
+	t := float64(b.victory)
+	for i := 0; i < 16; i++ {
+		angle := float64(i) * math.Pi / 8
+		r := t * (1.5 + float64(i%3)*0.5)
+		alpha := uint8(255 * math.Max(0, 1-t/bossVictoryFrames))
+		x := float32(cx + r*math.Cos(angle))
+		y := float32(cy + r*math.Sin(angle))
+		vector.DrawFilledRect(screen, x-3, y-3, 6, 6, color.RGBA{200, 30, 60, alpha}, false)
+	}
+}
+
+// drawBossHUD puts Sukuna's HP bar in the middle of the bottom strip
+func (g *Game) drawBossHUD(screen *ebiten.Image) {
+	b := g.boss
+	y := playfieldRect().Max.Y
+	label := hudLabelStyle
+	label.Align = AlignCenter
+	DrawText(screen, "SUKUNA  "+b.Phase().Name, logicalWidth/2, y+6, label, g.globalTimer)
+
+	const barW, barH = 300, 10
+	x := float32(logicalWidth/2 - barW/2)
+	top := float32(y + 24)
+	vector.DrawFilledRect(screen, x, top, barW, barH, color.RGBA{60, 10, 20, 255}, false)
+	fill := float32(barW) * float32(b.HP) / bossHP
+	vector.DrawFilledRect(screen, x, top, fill, barH, color.RGBA{220, 20, 60, 255}, false)
+	for i := 1; i < bossHP; i++ {
+		mark := x + float32(barW)*float32(i)/bossHP
+		vector.DrawFilledRect(screen, mark-1, top, 2, barH, color.RGBA{0, 0, 0, 255}, false)
+	}
+	vector.StrokeRect(screen, x, top, barW, barH, 1, color.RGBA{255, 215, 0, 200}, false)
+}
+
+// drawBossOverlay shows the phase name as a phase begins and the victory
+// message, over the playfield
+func (g *Game) drawBossOverlay(screen *ebiten.Image) {
+	b := g.boss
+	if b == nil {
+		return
+	}
+	field := playfieldRect()
+	cx, cy := (field.Min.X+field.Max.X)/2, (field.Min.Y+field.Max.Y)/2
+	switch {
+	case b.victory > 0:
+		title := overlayTitle
+		title.Pulse = 0.2
+		DrawText(screen, "SUKUNA EXORCISED", cx, cy-60, title, b.victory)
+		DrawText(screen, fmt.Sprintf("+%d", bossPoints), cx, cy+10, overlayText, b.victory)
+	case b.banner > 0 && (b.banner > 30 || b.banner/5%2 == 0):
+		warn := overlayText
+		warn.Size = TextLarge
+		warn.Color = color.RGBA{255, 60, 60, 255}
+		DrawText(screen, b.Phase().Name, cx, field.Min.Y+24, warn, g.globalTimer)
+	}
+}
+
+// drawBossRoundInfo warns of the boss on the round ready screen
+func (g *Game) drawBossRoundInfo(screen *ebiten.Image, cx, cy int) {
+	warn := overlayText
+	warn.Color = color.RGBA{255, 40, 40, 255}
+	warn.Pulse = 0.3
+	DrawText(screen, "BOSS ROUND: SUKUNA", cx, cy+115, warn, g.RoundReadyTimer)
+	hint := overlayHint
+	hint.Pulse = 0
+	DrawText(screen, "POWER PELLETS HURT HIM", cx, cy+145, hint, g.RoundReadyTimer)
+}
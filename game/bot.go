@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Controller plays Gojo instead of the keyboard. Act is called once per
+// frame with what the player can see and returns "up", "down", "left",
+// "right", or "" to stand still.
+type Controller interface {
+	Act(obs *Observation) string
+}
+
+// Observation is the game as a Controller sees it. Level is the live maze
+// and must not be modified.
+type Observation struct {
+	Frame       int                `json:"frame"`
+	Level       [][]int            `json:"level"`
+	TileSize    int                `json:"tile_size"`
+	PlayerX     float64            `json:"player_x"` // top-left corner in pixels
+	PlayerY     float64            `json:"player_y"`
+	PlayerTileX int                `json:"player_tile_x"`
+	PlayerTileY int                `json:"player_tile_y"`
+	Direction   string             `json:"direction"`
+	Ghosts      []GhostObservation `json:"ghosts"`
+	FrightTimer int                `json:"fright_timer"` // frames of power pellet left, 0 when not powered
+	PelletsLeft int                `json:"pellets_left"`
+	Score       int                `json:"score"`
+	Lives       int                `json:"lives"`
+	Round       int                `json:"round"`
+}
+
+type GhostObservation struct {
+	Name  string    `json:"name"`
+	TileX int       `json:"tile_x"`
+	TileY int       `json:"tile_y"`
+	Mode  GhostMode `json:"mode"`
+}
+
+var ghostModeNames = [...]string{
+	ChaseMode:      "chase",
+	ScatterMode:    "scatter",
+	FrightenedMode: "frightened",
+	DeadMode:       "dead",
+	InHouseMode:    "in_house",
+}
+
+func (m GhostMode) String() string {
+	if m >= 0 && int(m) < len(ghostModeNames) {
+		return ghostModeNames[m]
+	}
+	return fmt.Sprintf("mode(%d)", int(m))
+}
+
+// MarshalText writes modes by name for external bots
+func (m GhostMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText reads a mode by name, as in tournament configs
+func (m *GhostMode) UnmarshalText(text []byte) error {
+	for mode, name := range ghostModeNames {
+		if name == string(text) {
+			*m = GhostMode(mode)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown ghost mode %q", text)
+}
+
+// Dangerous reports whether touching the ghost costs a life
+func (g GhostObservation) Dangerous() bool {
+	return g.Mode == ChaseMode || g.Mode == ScatterMode
+}
+
+// Observe builds the observation for the current frame
+func (g *Game) Observe() *Observation {
+	obs := newObservation(g.globalTimer, level, g.Player, g.Ghosts)
+	obs.PelletsLeft = g.pelletCount
+	obs.Score = g.Player.Score
+	obs.Lives = g.lives
+	obs.Round = g.RoundNumber
+	if g.powerPelletActive {
+		obs.FrightTimer = g.powerPelletTimer
+	}
+	return obs
+}
+
+// newObservation fills in the maze, the player and the ghosts
+func newObservation(frame int, level [][]int, p *Player, ghosts []*Ghost) *Observation {
+	obs := &Observation{
+		Frame:       frame,
+		Level:       level,
+		TileSize:    TileSize,
+		PlayerX:     p.X,
+		PlayerY:     p.Y,
+		PlayerTileX: int(p.X+TileSize/2) / TileSize,
+		PlayerTileY: int(p.Y+TileSize/2) / TileSize,
+		Direction:   p.Direction,
+	}
+	for _, ghost := range ghosts {
+		obs.Ghosts = append(obs.Ghosts, GhostObservation{
+			Name:  ghost.GhostType,
+			TileX: int(ghost.X+TileSize/2) / TileSize,
+			TileY: int(ghost.Y+TileSize/2) / TileSize,
+			Mode:  ghost.Mode,
+		})
+	}
+	return obs
+}
+
+// SetBot hands Gojo to a controller, or back to the player with nil
+func (g *Game) SetBot(c Controller) {
+	g.Player.Controller = c
+	g.Player.Observe = g.Observe
+}
+
+// Built-in bots by name, for --bot
+var bots = map[string]func() Controller{
+	"greedy": func() Controller { return &GreedyBot{} },
+}
+
+// NewBot makes the bot for a --bot value: a built-in name,
+// "exec:<command>" for a child process or "unix:<path>" for a socket (see
+// externalbot.go)
+func NewBot(spec string, opts BotOptions) (Controller, error) {
+	if command, ok := strings.CutPrefix(spec, "exec:"); ok {
+		return StartBotProcess(command, opts)
+	}
+	if path, ok := strings.CutPrefix(spec, "unix:"); ok {
+		return ListenBotSocket(path, opts)
+	}
+	if newBot, ok := bots[spec]; ok {
+		return newBot(), nil
+	}
+	var names []string
+	for n := range bots {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown bot %q, have %s, exec:<command> or unix:<path>", spec, strings.Join(names, ", "))
+}
+
+// GreedyBot is the reference bot. It runs from dangerous ghosts that get
+// close, hunts frightened ones while the power pellet lasts, and otherwise
+// walks the A* path to the nearest pellet.
+type GreedyBot struct{}
+
+const (
+	botDangerDistance = 4   // tiles; closer dangerous ghosts make the bot flee
+	botHuntDistance   = 8   // tiles; frightened ghosts closer than this get chased
+	botHuntMinFright  = 120 // frames of fright needed to bother chasing
+)
+
+var botSteps = []struct {
+	dir    string
+	dx, dy int
+}{
+	{"up", 0, -1}, {"down", 0, 1}, {"left", -1, 0}, {"right", 1, 0},
+}
+
+func (b *GreedyBot) Act(obs *Observation) string {
+	px, py := obs.PlayerTileX, obs.PlayerTileY
+
+	if dir := b.flee(obs); dir != "" {
+		return b.steer(obs, dir)
+	}
+
+	tx, ty, ok := b.huntTarget(obs)
+	if !ok {
+		tx, ty, ok = nearestPellet(obs.Level, px, py)
+	}
+	if !ok {
+		return ""
+	}
+	path := findPath(obs.Level, px, py, tx, ty)
+	if len(path) < 2 {
+		return b.settle(obs)
+	}
+	next := path[1]
+	for _, s := range botSteps {
+		if next.X == px+s.dx && next.Y == py+s.dy {
+			return b.steer(obs, s.dir)
+		}
+	}
+	return ""
+}
+
+// flee picks the open neighbouring tile furthest from the dangerous ghosts
+// in range, or "" when none are close
+func (b *GreedyBot) flee(obs *Observation) string {
+	px, py := obs.PlayerTileX, obs.PlayerTileY
+	var threats []GhostObservation
+	for _, g := range obs.Ghosts {
+		if g.Dangerous() && tileDistance(px, py, g.TileX, g.TileY) <= botDangerDistance {
+			threats = append(threats, g)
+		}
+	}
+	if len(threats) == 0 {
+		return ""
+	}
+
+	best, bestScore := "", -1
+	for _, s := range botSteps {
+		x, y := px+s.dx, py+s.dy
+		if !isOpenTile(obs.Level, x, y) {
+			continue
+		}
+		score := 1 << 30
+		for _, g := range threats {
+			score = min(score, tileDistance(x, y, g.TileX, g.TileY))
+		}
+		if score > bestScore {
+			best, bestScore = s.dir, score
+		}
+	}
+	return best
+}
+
+// huntTarget returns the nearest frightened ghost worth chasing
+func (b *GreedyBot) huntTarget(obs *Observation) (int, int, bool) {
+	if obs.FrightTimer < botHuntMinFright {
+		return 0, 0, false
+	}
+	px, py := obs.PlayerTileX, obs.PlayerTileY
+	found, bestDist, tx, ty := false, botHuntDistance+1, 0, 0
+	for _, g := range obs.Ghosts {
+		if g.Mode != FrightenedMode {
+			continue
+		}
+		if d := tileDistance(px, py, g.TileX, g.TileY); d < bestDist && isOpenTile(obs.Level, g.TileX, g.TileY) {
+			found, bestDist, tx, ty = true, d, g.TileX, g.TileY
+		}
+	}
+	return tx, ty, found
+}
+
+// steer turns a tile direction into a move. The player only fits through
+// a side passage when lined up with it, so it lines up first.
+func (b *GreedyBot) steer(obs *Observation, dir string) string {
+	size := float64(obs.TileSize)
+	alignX := float64(obs.PlayerTileX) * size
+	alignY := float64(obs.PlayerTileY) * size
+	switch dir {
+	case "up", "down":
+		if obs.PlayerX < alignX {
+			return "right"
+		}
+		if obs.PlayerX > alignX {
+			return "left"
+		}
+	case "left", "right":
+		if obs.PlayerY < alignY {
+			return "down"
+		}
+		if obs.PlayerY > alignY {
+			return "up"
+		}
+	}
+	return dir
+}
+
+// settle moves the player squarely onto its tile. Power pellets are only
+// collected there, not as soon as the player's centre crosses into the tile.
+func (b *GreedyBot) settle(obs *Observation) string {
+	size := float64(obs.TileSize)
+	switch {
+	case obs.PlayerX < float64(obs.PlayerTileX)*size:
+		return "right"
+	case obs.PlayerX > float64(obs.PlayerTileX)*size:
+		return "left"
+	case obs.PlayerY < float64(obs.PlayerTileY)*size:
+		return "down"
+	case obs.PlayerY > float64(obs.PlayerTileY)*size:
+		return "up"
+	}
+	return ""
+}
+
+// nearestPellet finds the closest pellet by walking distance
+func nearestPellet(level [][]int, startX, startY int) (int, int, bool) {
+	if !isOpenTile(level, startX, startY) {
+		return 0, 0, false
+	}
+	seen := map[[2]int]bool{{startX, startY}: true}
+	queue := [][2]int{{startX, startY}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if tile := level[cur[1]][cur[0]]; tile == TilePellet || tile == TilePowerPellet {
+			return cur[0], cur[1], true
+		}
+		for _, s := range botSteps {
+			next := [2]int{cur[0] + s.dx, cur[1] + s.dy}
+			if !seen[next] && isOpenTile(level, next[0], next[1]) {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func isOpenTile(level [][]int, x, y int) bool {
+	return y >= 0 && y < len(level) && x >= 0 && x < len(level[y]) && level[y][x] != TileWall
+}
+
+func tileDistance(ax, ay, bx, by int) int {
+	dx, dy := ax-bx, ay-by
+	return max(dx, -dx) + max(dy, -dy)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Config holds the player's settings between sessions
+type Config struct {
+	Theme     string   `json:"theme"`
+	HighScore int      `json:"high_score"`
+	Bindings  Bindings `json:"bindings"`
+
+	// Versus mode: which ghost the second player drives and with what
+	VersusGhost   string   `json:"versus_ghost"`
+	GhostBindings Bindings `json:"ghost_bindings"`
+
+	// Best endless runs, highest first, see endless.go
+	EndlessScores []EndlessScore `json:"endless_scores"`
+
+	// Rulesets, see rules.go
+	TimeAttackBest int  `json:"time_attack_best"`     // frames, 0 when there is none
+	PelletRushBest int  `json:"pellet_rush_best"`     // pellets
+	Practice       bool `json:"time_attack_practice"` // time attack without curses
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Theme:         defaultThemeName,
+		Bindings:      defaultBindings(),
+		VersusGhost:   "sukuna",
+		GhostBindings: defaultGhostBindings(),
+	}
+}
+
+// configDir is where the config file and user theme packs live
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".pacman-jjk"
+	}
+	return filepath.Join(dir, "pacman-jjk")
+}
+
+func configPath() string {
+	return filepath.Join(configDir(), "config.json")
+}
+
+// LoadConfig reads the config file; a missing or broken file gives the defaults
+func LoadConfig() *Config {
+	cfg := defaultConfig()
+
+	data, err := os.ReadFile(configPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			stateLog.Warn("could not read config", "err", err)
+		}
+		return cfg
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		stateLog.Warn("ignoring invalid config", "path", configPath(), "err", err)
+		return defaultConfig()
+	}
+	cfg.Bindings = cfg.Bindings.withDefaults()
+	return cfg
+}
+
+// Save writes the config file, creating the config directory if needed
+func (c *Config) Save() error {
+	if err := os.MkdirAll(configDir(), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath(), data, 0o644)
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// The developer console drops down with the backtick key and runs commands
+// against the game through the same methods gameplay uses. The game is
+// frozen while it is open. Scripts of the same commands can be run at
+// startup with --console-script to set up a scenario.
+
+// consoleCommand is one command; run returns the text to show
+type consoleCommand struct {
+	usage string
+	help  string
+	run   func(g *Game, args []string) (string, error)
+}
+
+// consoleCommands is filled in by init, as help refers back to it
+var consoleCommands map[string]consoleCommand
+
+func init() {
+	consoleCommands = map[string]consoleCommand{
+		"help":     {"help [command]", "list commands or show one's usage", consoleHelp},
+		"start":    {"start", "start a one-player game", consoleStart},
+		"endless":  {"endless [seed]", "start an endless run, on a random seed if none is given", consoleEndless},
+		"god":      {"god [on|off]", "toggle whether ghosts can catch Gojo", consoleGod},
+		"setlives": {"setlives <n>", "set the lives left", consoleSetLives},
+		"level":    {"level <n>", "start round n with a full maze", consoleLevel},
+		"boss":     {"boss", "restart the round as a boss round against Sukuna", consoleBoss},
+		"spawn":    {"spawn fruit", "put a bonus fruit below the ghost house", consoleSpawn},
+		"ghost":    {"ghost <name> mode <mode>", "put a curse in chase, scatter, frightened, dead or in_house", consoleGhost},
+		"speed":    {"speed <name|all> <factor>", "scale a curse's speed, 1 is normal", consoleSpeed},
+		"teleport": {"teleport <x> <y>", "move Gojo to a tile", consoleTeleport},
+		"fright":   {"fright <frames>", "frighten the curses as a power pellet does", consoleFright},
+		"save":     {"save <slot>", "save the game to a named slot", consoleSave},
+		"load":     {"load <slot>", "load a game from a named slot", consoleLoad},
+	}
+}
+
+// Exec runs one console command line
+func (g *Game) Exec(line string) (string, error) {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return "", nil
+	}
+	cmd, ok := consoleCommands[strings.ToLower(words[0])]
+	if !ok {
+		return "", fmt.Errorf("unknown command %q, try help", words[0])
+	}
+	out, err := cmd.run(g, words[1:])
+	if err != nil {
+		return "", err
+	}
+	stateLog.Info("console", "command", line)
+	return out, nil
+}
+
+// RunConsoleScript runs a file of console commands, one per line. Blank
+// lines and lines starting with # are skipped; the first error stops it.
+func (g *Game) RunConsoleScript(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out, err := g.Exec(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		g.console.print("> " + line)
+		if out != "" {
+			g.console.print(out)
+		}
+	}
+	return scanner.Err()
+}
+
+var errNoMatch = errors.New("start a game first")
+
+// requireMatch reports an error unless a game is in progress
+func requireMatch(g *Game) error {
+	if g.inMatch() || g.State == StatePaused {
+		return nil
+	}
+	return errNoMatch
+}
+
+func consoleHelp(g *Game, args []string) (string, error) {
+	if len(args) > 0 {
+		cmd, ok := consoleCommands[args[0]]
+		if !ok {
+			return "", fmt.Errorf("unknown command %q", args[0])
+		}
+		return cmd.usage + ": " + cmd.help, nil
+	}
+	names := consoleCommandNames()
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = consoleCommands[name].usage
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func consoleStart(g *Game, args []string) (string, error) {
+	g.startGame()
+	return "", nil
+}
+
+func consoleEndless(g *Game, args []string) (string, error) {
+	seed := newEndlessSeed()
+	if len(args) > 0 {
+		var err error
+		if seed, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+			return "", errors.New("usage: endless [seed]")
+		}
+	}
+	g.startEndless(seed)
+	return fmt.Sprintf("endless run on seed %d", seed), nil
+}
+
+func consoleGod(g *Game, args []string) (string, error) {
+	switch {
+	case len(args) == 0:
+		g.godMode = !g.godMode
+	case args[0] == "on":
+		g.godMode = true
+	case args[0] == "off":
+		g.godMode = false
+	default:
+		return "", errors.New("usage: god [on|off]")
+	}
+	if g.godMode {
+		return "god mode on", nil
+	}
+	return "god mode off", nil
+}
+
+func consoleSetLives(g *Game, args []string) (string, error) {
+	n, err := consoleInt(args, 0, "setlives <n>")
+	if err != nil {
+		return "", err
+	}
+	if n < 1 {
+		return "", errors.New("lives must be at least 1")
+	}
+	g.lives = n
+	return "", nil
+}
+
+func consoleLevel(g *Game, args []string) (string, error) {
+	n, err := consoleInt(args, 0, "level <n>")
+	if err != nil {
+		return "", err
+	}
+	if n < 1 {
+		return "", errors.New("levels start at 1")
+	}
+	if err := requireMatch(g); err != nil {
+		return "", err
+	}
+	g.RoundNumber = n
+	g.gameState.CurrentLevel = n
+	g.State = StateRoundReady
+	g.ShowRoundReady = true
+	g.RoundReadyTimer = 0
+	g.startNextRound()
+	return "", nil
+}
+
+func consoleBoss(g *Game, args []string) (string, error) {
+	if err := requireMatch(g); err != nil {
+		return "", err
+	}
+	if !g.bossRounds() {
+		return "", errors.New("no boss rounds in this game")
+	}
+	g.State = StateRoundReady
+	g.ShowRoundReady = true
+	g.RoundReadyTimer = 0
+	g.startNextRound()
+	if g.boss == nil {
+		g.startBoss()
+	}
+	return "", nil
+}
+
+func consoleSpawn(g *Game, args []string) (string, error) {
+	if len(args) != 1 || args[0] != "fruit" {
+		return "", errors.New("usage: spawn fruit")
+	}
+	if err := requireMatch(g); err != nil {
+		return "", err
+	}
+	return "", g.spawnFruit()
+}
+
+func consoleGhost(g *Game, args []string) (string, error) {
+	if len(args) != 3 || args[1] != "mode" {
+		return "", errors.New("usage: ghost <name> mode <mode>")
+	}
+	ghost, err := consoleGhostNamed(g, args[0])
+	if err != nil {
+		return "", err
+	}
+	var mode GhostMode
+	if err := mode.UnmarshalText([]byte(args[2])); err != nil {
+		return "", err
+	}
+	ghost.SetMode(mode)
+	return "", nil
+}
+
+func consoleSpeed(g *Game, args []string) (string, error) {
+	if len(args) != 2 {
+		return "", errors.New("usage: speed <name|all> <factor>")
+	}
+	factor, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || factor <= 0 {
+		return "", fmt.Errorf("speed factor %q must be a positive number", args[1])
+	}
+	if args[0] == "all" {
+		for _, ghost := range g.Ghosts {
+			ghost.SpeedFactor = factor
+		}
+		return "", nil
+	}
+	ghost, err := consoleGhostNamed(g, args[0])
+	if err != nil {
+		return "", err
+	}
+	ghost.SpeedFactor = factor
+	return "", nil
+}
+
+func consoleTeleport(g *Game, args []string) (string, error) {
+	x, err := consoleInt(args, 0, "teleport <x> <y>")
+	if err != nil {
+		return "", err
+	}
+	y, err := consoleInt(args, 1, "teleport <x> <y>")
+	if err != nil {
+		return "", err
+	}
+	if y < 0 || y >= len(level) || x < 0 || x >= len(level[0]) {
+		return "", fmt.Errorf("tile (%d,%d) is outside the maze", x, y)
+	}
+	if level[y][x] == TileWall {
+		return "", fmt.Errorf("tile (%d,%d) is a wall", x, y)
+	}
+	g.Player.X = float64(x * TileSize)
+	g.Player.Y = float64(y * TileSize)
+	return "", nil
+}
+
+func consoleFright(g *Game, args []string) (string, error) {
+	frames, err := consoleInt(args, 0, "fright <frames>")
+	if err != nil {
+		return "", err
+	}
+	if frames < 1 {
+		return "", errors.New("frames must be positive")
+	}
+	if err := requireMatch(g); err != nil {
+		return "", err
+	}
+	g.startPowerMode(frames)
+	return "", nil
+}
+
+func consoleSave(g *Game, args []string) (string, error) {
+	path, err := consoleSlotPath(args, "save <slot>")
+	if err != nil {
+		return "", err
+	}
+	if err := g.SaveGame(path); err != nil {
+		return "", err
+	}
+	return "saved to " + path, nil
+}
+
+func consoleLoad(g *Game, args []string) (string, error) {
+	path, err := consoleSlotPath(args, "load <slot>")
+	if err != nil {
+		return "", err
+	}
+	if err := g.LoadGame(path); err != nil {
+		return "", err
+	}
+	return "loaded " + path, nil
+}
+
+// consoleInt parses args[i] as an integer
+func consoleInt(args []string, i int, usage string) (int, error) {
+	if i >= len(args) {
+		return 0, errors.New("usage: " + usage)
+	}
+	n, err := strconv.Atoi(args[i])
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", args[i])
+	}
+	return n, nil
+}
+
+func consoleGhostNamed(g *Game, name string) (*Ghost, error) {
+	for _, ghost := range g.Ghosts {
+		if ghost.GhostType == name {
+			return ghost, nil
+		}
+	}
+	return nil, fmt.Errorf("no curse named %q (want %s)", name, strings.Join(ghostNames(g.Ghosts), ", "))
+}
+
+// consoleSlotPath names a save slot file next to the quick save
+func consoleSlotPath(args []string, usage string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("usage: " + usage)
+	}
+	slot := args[0]
+	for _, r := range slot {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", fmt.Errorf("slot names use letters, digits, - and _ only")
+		}
+	}
+	return filepath.Join(configDir(), "slot-"+slot+".bin"), nil
+}
+
+func consoleCommandNames() []string {
+	names := make([]string, 0, len(consoleCommands))
+	for name := range consoleCommands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// consoleCompletions lists what the word being typed could be, given the
+// words before it
+func consoleCompletions(g *Game, words []string) []string {
+	if len(words) == 0 {
+		return consoleCommandNames()
+	}
+	switch words[0] {
+	case "help":
+		if len(words) == 1 {
+			return consoleCommandNames()
+		}
+	case "god":
+		if len(words) == 1 {
+			return []string{"on", "off"}
+		}
+	case "spawn":
+		if len(words) == 1 {
+			return []string{"fruit"}
+		}
+	case "ghost":
+		switch len(words) {
+		case 1:
+			return ghostNames(g.Ghosts)
+		case 2:
+			return []string{"mode"}
+		case 3:
+			return ghostModeNames[:]
+		}
+	case "speed":
+		if len(words) == 1 {
+			return append(ghostNames(g.Ghosts), "all")
+		}
+	}
+	return nil
+}
+
+// Console is the drop-down: the line being typed, earlier lines and output
+type Console struct {
+	open    bool
+	input   []rune
+	history []string
+	recall  int // index into history while browsing it, len(history) when not
+	lines   []string
+}
+
+// Lines of output kept for scrolling back
+const consoleScrollback = 200
+
+func NewConsole() *Console {
+	return &Console{}
+}
+
+func (c *Console) print(text string) {
+	c.lines = append(c.lines, strings.Split(text, "\n")...)
+	if over := len(c.lines) - consoleScrollback; over > 0 {
+		c.lines = slices.Delete(c.lines, 0, over)
+	}
+}
+
+// Update handles the console's keys and reports whether it is open, in
+// which case the game doesn't get the frame. It won't open in networked
+// matches, replays or while spectating, which a local command would break.
+func (c *Console) Update(g *Game) bool {
+	toggled := inpututil.IsKeyJustPressed(ebiten.KeyBackquote)
+	if !c.open {
+		if toggled && g.net == nil && g.replay == nil && g.spectating == nil {
+			c.open = true
+			c.recall = len(c.history)
+			return true
+		}
+		return false
+	}
+	if toggled || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		c.open = false
+		c.input = c.input[:0]
+		return true
+	}
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r != '`' {
+			c.input = append(c.input, r)
+		}
+	}
+
+	switch {
+	case repeatingKey(ebiten.KeyBackspace):
+		if len(c.input) > 0 {
+			c.input = c.input[:len(c.input)-1]
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		c.submit(g)
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		c.complete(g)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		if c.recall > 0 {
+			c.recall--
+			c.input = []rune(c.history[c.recall])
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		if c.recall < len(c.history) {
+			c.recall++
+		}
+		if c.recall == len(c.history) {
+			c.input = c.input[:0]
+		} else {
+			c.input = []rune(c.history[c.recall])
+		}
+	}
+	return true
+}
+
+// repeatingKey is true when key is pressed and then every few frames while
+// it is held
+func repeatingKey(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || d >= 30 && d%4 == 0
+}
+
+func (c *Console) submit(g *Game) {
+	line := strings.TrimSpace(string(c.input))
+	c.input = c.input[:0]
+	if line == "" {
+		return
+	}
+	if len(c.history) == 0 || c.history[len(c.history)-1] != line {
+		c.history = append(c.history, line)
+	}
+	c.recall = len(c.history)
+
+	c.print("> " + line)
+	out, err := g.Exec(line)
+	switch {
+	case err != nil:
+		c.print("error: " + err.Error())
+	case out != "":
+		c.print(out)
+	}
+}
+
+// complete extends the last word to the longest prefix its candidates
+// share, listing them when there is more than one
+func (c *Console) complete(g *Game) {
+	text := string(c.input)
+	words := strings.Fields(text)
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(text, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var matches []string
+	for _, candidate := range consoleCompletions(g, words) {
+		if strings.HasPrefix(candidate, partial) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+
+	common := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	completed := strings.Join(append(words, common), " ")
+	if len(matches) == 1 {
+		completed += " "
+	} else {
+		c.print(strings.Join(matches, "  "))
+	}
+	c.input = []rune(completed)
+}
+
+// Draw shows the console over the top third of the canvas
+func (c *Console) Draw(screen *ebiten.Image, timer int) {
+	if !c.open {
+		return
+	}
+	const lineHeight = 16
+	height := logicalHeight / 3
+	vector.DrawFilledRect(screen, 0, 0, logicalWidth, float32(height), color.RGBA{0, 0, 0, 210}, false)
+	vector.StrokeLine(screen, 0, float32(height), logicalWidth, float32(height), 1, color.RGBA{255, 215, 0, 200}, false)
+
+	y := height - lineHeight - 4
+	prompt := "> " + string(c.input)
+	if timer/30%2 == 0 {
+		prompt += "_"
+	}
+	ebitenutil.DebugPrintAt(screen, prompt, 8, y)
+	for i := len(c.lines) - 1; i >= 0 && y > lineHeight; i-- {
+		y -= lineHeight
+		ebitenutil.DebugPrintAt(screen, c.lines[i], 8, y)
+	}
+}
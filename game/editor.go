@@ -0,0 +1,511 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+
+	"jk/maze"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// The level editor (--edit file, or EDITOR on the menu for a level in the
+// config directory) paints a maze with the mouse and saves it in the level
+// file format (see maze/level.go) once it validates. Left drag paints with
+// the current tool, right drag erases, and the toolbar along the bottom has
+// the tools and actions, each with a key. Back leaves for the menu and puts
+// back the maze that was in play.
+
+type editorTool int
+
+const (
+	toolWall editorTool = iota
+	toolPellet
+	toolPowerPellet
+	toolEmpty
+	toolStart
+	toolHouse
+	toolDoor
+	toolFruit
+	toolCount
+)
+
+var editorToolNames = [toolCount]string{"WALL", "PELLET", "POWER", "EMPTY", "START", "HOUSE", "DOOR", "FRUIT"}
+
+// Tiles each painting tool lays down
+var editorToolTiles = [toolCount]int{
+	toolWall:        TileWall,
+	toolPellet:      TilePellet,
+	toolPowerPellet: TilePowerPellet,
+	toolEmpty:       TileEmpty,
+}
+
+// Toolbar buttons after the tools
+const (
+	buttonMirror = iota + int(toolCount)
+	buttonUndo
+	buttonRedo
+	buttonSave
+	buttonTest
+	buttonCount
+)
+
+var editorButtonLabels = [buttonCount]string{
+	"1 WALL", "2 PELLET", "3 POWER", "4 EMPTY", "5 START", "6 HOUSE", "7 DOOR", "8 FRUIT",
+	"M MIRROR", "^Z UNDO", "^Y REDO", "^S SAVE", "F2 TEST",
+}
+
+// Undo steps kept
+const editorUndoLimit = 100
+
+type LevelEditor struct {
+	path     string
+	level    *maze.Level
+	tool     editorTool
+	mirror   bool // paint the mirrored tile across the vertical centre line too
+	undo     []*maze.Level
+	redo     []*maze.Level
+	painting bool // a mouse stroke is in progress; it is one undo step
+	problems []maze.Problem
+	message  string
+	testing  bool       // the level is being played
+	before   levelSetup // the maze in play when the editor opened
+
+	hover  maze.Point
+	inMaze bool // hover is on the grid
+	dirty  bool // mazeImage needs redrawing
+	maze   *ebiten.Image
+	world  *ebiten.Image
+}
+
+// NewLevelEditor opens path, or starts from the classic maze when the file
+// doesn't exist yet
+func NewLevelEditor(path string) (*LevelEditor, error) {
+	l, err := maze.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		l, err = maze.ClassicLevel(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e := &LevelEditor{path: path, level: l, dirty: true}
+	e.validate()
+	return e, nil
+}
+
+// customLevelPath is the level the menu's editor works on
+func customLevelPath() string {
+	return filepath.Join(configDir(), "levels", "custom.txt")
+}
+
+// openEditor switches to the editor on path
+func (g *Game) openEditor(path string) error {
+	e, err := NewLevelEditor(path)
+	if err != nil {
+		return err
+	}
+	e.before = g.currentSetup()
+	g.editor = e
+	g.State = StateEditor
+	return nil
+}
+
+// closeEditor goes back to the menu with the maze from before the editor
+func (g *Game) closeEditor() {
+	g.restoreSetup(g.editor.before)
+	g.editor = nil
+	g.resetGame()
+	g.State = StateMenu
+}
+
+func (e *LevelEditor) validate() {
+	e.problems = maze.Validate(e.level)
+}
+
+// Update handles the editor's mouse and keys
+func (e *LevelEditor) Update(g *Game) error {
+	if Input.JustPressed(ActionBack) {
+		g.closeEditor()
+		return nil
+	}
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	for i := 0; i < int(toolCount); i++ {
+		if inpututil.IsKeyJustPressed(ebiten.Key1 + ebiten.Key(i)) {
+			e.tool = editorTool(i)
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyM):
+		e.press(g, buttonMirror)
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyZ) && ebiten.IsKeyPressed(ebiten.KeyShift):
+		e.press(g, buttonRedo)
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyZ):
+		e.press(g, buttonUndo)
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyY):
+		e.press(g, buttonRedo)
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyS):
+		e.press(g, buttonSave)
+	case inpututil.IsKeyJustPressed(ebiten.KeyF2):
+		e.press(g, buttonTest)
+	}
+
+	cx, cy := g.cursorOnCanvas()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		for i := 0; i < buttonCount; i++ {
+			if image.Pt(cx, cy).In(editorButtonRect(i)) {
+				e.press(g, i)
+				return nil
+			}
+		}
+	}
+
+	e.hover, e.inMaze = e.tileAt(cx, cy)
+	left := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	right := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+	if !left && !right {
+		e.painting = false
+		return nil
+	}
+	if !e.inMaze {
+		return nil
+	}
+	tool := e.tool
+	if right {
+		tool = toolEmpty
+	}
+	e.paint(tool, e.hover)
+	return nil
+}
+
+// press runs a toolbar button
+func (e *LevelEditor) press(g *Game, button int) {
+	switch {
+	case button < int(toolCount):
+		e.tool = editorTool(button)
+	case button == buttonMirror:
+		e.mirror = !e.mirror
+	case button == buttonUndo:
+		e.step(&e.undo, &e.redo, "nothing to undo")
+	case button == buttonRedo:
+		e.step(&e.redo, &e.undo, "nothing to redo")
+	case button == buttonSave:
+		e.save()
+	case button == buttonTest:
+		e.test(g)
+	}
+}
+
+// step moves the level from one history stack to the other
+func (e *LevelEditor) step(from, to *[]*maze.Level, empty string) {
+	if len(*from) == 0 {
+		e.message = empty
+		return
+	}
+	*to = append(*to, e.level)
+	e.level = (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	e.changed()
+}
+
+// remember saves the level for undo before a change
+func (e *LevelEditor) remember() {
+	e.undo = append(e.undo, e.level.Copy())
+	if len(e.undo) > editorUndoLimit {
+		e.undo = e.undo[1:]
+	}
+	e.redo = nil
+}
+
+func (e *LevelEditor) changed() {
+	e.dirty = true
+	e.message = ""
+	e.validate()
+}
+
+// paint applies tool at p, and at its mirror image for tiles when mirroring
+func (e *LevelEditor) paint(tool editorTool, p maze.Point) {
+	l := e.level
+	if tool == toolHouse {
+		if e.painting {
+			return // one house per click
+		}
+		e.painting = true
+		if err := e.canStampHouse(p); err != nil {
+			e.message = err.Error()
+			return
+		}
+		e.remember()
+		e.stampHouse(p)
+		e.changed()
+		return
+	}
+
+	targets := []maze.Point{p}
+	if e.mirror && tool < toolStart {
+		if m := (maze.Point{X: len(l.Tiles[p.Y]) - 1 - p.X, Y: p.Y}); m != p {
+			targets = append(targets, m)
+		}
+	}
+	if !e.wouldChange(tool, targets) {
+		return
+	}
+	if !e.painting {
+		e.painting = true
+		e.remember()
+	}
+	for _, t := range targets {
+		switch tool {
+		case toolStart:
+			l.Start = t
+		case toolDoor:
+			l.Door = t
+		case toolFruit:
+			l.Fruit = t
+		default:
+			l.Tiles[t.Y][t.X] = editorToolTiles[tool]
+			continue
+		}
+		// Markers sit on open floor
+		if l.Tiles[t.Y][t.X] == TileWall {
+			l.Tiles[t.Y][t.X] = TileEmpty
+		}
+	}
+	e.changed()
+}
+
+func (e *LevelEditor) wouldChange(tool editorTool, targets []maze.Point) bool {
+	l := e.level
+	for _, t := range targets {
+		switch tool {
+		case toolStart:
+			return l.Start != t
+		case toolDoor:
+			return l.Door != t
+		case toolFruit:
+			return l.Fruit != t
+		}
+		if l.Tiles[t.Y][t.X] != editorToolTiles[tool] {
+			return true
+		}
+	}
+	return false
+}
+
+// canStampHouse checks a 3x3 house centred on p, with its wall, fits
+func (e *LevelEditor) canStampHouse(p maze.Point) error {
+	for y := p.Y - 2; y <= p.Y+2; y++ {
+		for x := p.X - 2; x <= p.X+2; x++ {
+			if !e.level.In(maze.Point{X: x, Y: y}) {
+				return errors.New("the ghost house doesn't fit there")
+			}
+		}
+	}
+	return nil
+}
+
+// stampHouse builds a 3x3 ghost house centred on p: walled in, with the
+// door in the middle of the top wall
+func (e *LevelEditor) stampHouse(p maze.Point) {
+	l := e.level
+	l.House = maze.Rect{X: p.X - 1, Y: p.Y - 1, W: 3, H: 3}
+	l.Door = maze.Point{X: p.X, Y: p.Y - 2}
+	for y := p.Y - 2; y <= p.Y+2; y++ {
+		for x := p.X - 2; x <= p.X+2; x++ {
+			t := maze.Point{X: x, Y: y}
+			switch {
+			case l.House.Contains(t) || t == l.Door:
+				l.Tiles[y][x] = TileEmpty
+			default:
+				l.Tiles[y][x] = TileWall
+			}
+		}
+	}
+}
+
+func (e *LevelEditor) save() {
+	if problem, ok := maze.FirstError(e.problems); ok {
+		e.message = fmt.Sprintf("not saved: %s", problem)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0o755); err != nil {
+		e.message = "not saved: " + err.Error()
+		return
+	}
+	if err := e.level.Save(e.path); err != nil {
+		e.message = "not saved: " + err.Error()
+		return
+	}
+	e.message = "saved " + e.path
+}
+
+// test plays the level as it is, until F2 or the end of the game
+func (e *LevelEditor) test(g *Game) {
+	if problem, ok := maze.FirstError(e.problems); ok {
+		e.message = fmt.Sprintf("can't play: %s", problem)
+		return
+	}
+	g.useLevel(e.level)
+	g.startGame()
+	e.testing = true
+}
+
+// stopTesting returns from a test game to the editor
+func (g *Game) stopTesting() {
+	g.editor.testing = false
+	g.State = StateEditor
+	if g.AudioSystem != nil {
+		g.AudioSystem.StopBGM()
+	}
+}
+
+// tileAt maps a point on the canvas to a maze tile
+func (e *LevelEditor) tileAt(cx, cy int) (maze.Point, bool) {
+	geo := e.worldGeoM()
+	geo.Invert()
+	wx, wy := geo.Apply(float64(cx), float64(cy))
+	if wx < 0 || wy < 0 {
+		return maze.Point{}, false
+	}
+	p := maze.Point{X: int(wx) / TileSize, Y: int(wy) / TileSize}
+	return p, e.level.In(p)
+}
+
+// worldGeoM places the maze on the canvas, as drawPlayfield does
+func (e *LevelEditor) worldGeoM() ebiten.GeoM {
+	return centerIn(e.level.Width()*TileSize, e.level.Height()*TileSize, playfieldRect())
+}
+
+// cursorOnCanvas is the mouse position in canvas coordinates
+func (g *Game) cursorOnCanvas() (int, int) {
+	geo := centerIn(logicalWidth, logicalHeight, image.Rectangle{Max: g.windowSize})
+	geo.Invert()
+	x, y := ebiten.CursorPosition()
+	cx, cy := geo.Apply(float64(x), float64(y))
+	return int(cx), int(cy)
+}
+
+// editorButtonRect is where toolbar button i is on the canvas
+func editorButtonRect(i int) image.Rectangle {
+	const w, gap = 86, 6
+	x := 8 + i*(w+gap)
+	y := logicalHeight - hudBottomHeight + 8
+	return image.Rect(x, y, x+w, y+hudBottomHeight-16)
+}
+
+func (e *LevelEditor) Draw(screen *ebiten.Image, g *Game) {
+	l := e.level
+	w, h := l.Width()*TileSize, l.Height()*TileSize
+	if e.dirty || e.maze == nil {
+		if e.maze != nil {
+			e.maze.Deallocate()
+		}
+		e.maze = renderMaze(rectangular(l.Tiles), TileSize)
+		e.dirty = false
+	}
+	e.world = offscreen(e.world, w, h)
+	e.world.DrawImage(e.maze, nil)
+
+	for y, row := range l.Tiles {
+		for x, tile := range row {
+			switch tile {
+			case TilePellet:
+				g.drawPellet(e.world, x, y, false)
+			case TilePowerPellet:
+				g.drawPellet(e.world, x, y, true)
+			}
+		}
+	}
+
+	// Markers
+	hr := l.House
+	vector.StrokeRect(e.world, float32(hr.X*TileSize), float32(hr.Y*TileSize), float32(hr.W*TileSize), float32(hr.H*TileSize), 2, color.RGBA{255, 100, 255, 255}, false)
+	ebitenutil.DebugPrintAt(e.world, "HOUSE", hr.X*TileSize+4, hr.Y*TileSize+4)
+	vector.DrawFilledRect(e.world, float32(l.Door.X*TileSize), float32(l.Door.Y*TileSize+TileSize/2-2), TileSize, 4, color.RGBA{255, 180, 220, 255}, false)
+	drawIcon(e.world, PlayerImage, float64(l.Start.X*TileSize), float64(l.Start.Y*TileSize), TileSize)
+	drawIcon(e.world, Assets.Image("fruit.cherry"), float64(l.Fruit.X*TileSize), float64(l.Fruit.Y*TileSize), TileSize)
+
+	if e.mirror {
+		mid := float32(w) / 2
+		vector.StrokeLine(e.world, mid, 0, mid, float32(h), 1, color.RGBA{0, 200, 255, 160}, false)
+	}
+	for _, p := range e.problems {
+		if p.At != nil && p.Severity == maze.Error {
+			vector.StrokeRect(e.world, float32(p.At.X*TileSize)+2, float32(p.At.Y*TileSize)+2, TileSize-4, TileSize-4, 2, color.RGBA{255, 0, 0, 255}, false)
+		}
+	}
+	if e.inMaze {
+		vector.StrokeRect(e.world, float32(e.hover.X*TileSize), float32(e.hover.Y*TileSize), TileSize, TileSize, 2, color.RGBA{255, 255, 255, 200}, false)
+	}
+
+	op := &ebiten.DrawImageOptions{GeoM: e.worldGeoM()}
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(e.world, op)
+
+	e.drawBars(screen)
+}
+
+// drawBars fills the HUD strips with the status line and the toolbar
+func (e *LevelEditor) drawBars(screen *ebiten.Image) {
+	status := fmt.Sprintf("EDITING %s   TOOL %s   MIRROR %v   ESC TO LEAVE", e.path, editorToolNames[e.tool], e.mirror)
+	if e.inMaze {
+		status += fmt.Sprintf("   TILE (%d,%d)", e.hover.X, e.hover.Y)
+	}
+	ebitenutil.DebugPrintAt(screen, status, 8, 8)
+
+	line := e.message
+	switch {
+	case line != "":
+	case len(e.problems) == 0:
+		line = "level is valid"
+	default:
+		first, ok := maze.FirstError(e.problems)
+		if !ok {
+			first = e.problems[0]
+		}
+		line = fmt.Sprintf("%d problem(s), first: %s", len(e.problems), first)
+	}
+	ebitenutil.DebugPrintAt(screen, line, 8, 28)
+
+	for i := 0; i < buttonCount; i++ {
+		r := editorButtonRect(i)
+		fill := color.RGBA{30, 30, 50, 255}
+		if i == int(e.tool) || i == buttonMirror && e.mirror {
+			fill = color.RGBA{120, 90, 20, 255}
+		}
+		vector.DrawFilledRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), fill, false)
+		vector.StrokeRect(screen, float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()), 1, color.RGBA{255, 215, 0, 200}, false)
+		ebitenutil.DebugPrintAt(screen, editorButtonLabels[i], r.Min.X+4, r.Min.Y+8)
+	}
+}
+
+// rectangular pads ragged rows with walls so they can be drawn
+func rectangular(tiles [][]int) [][]int {
+	w := 0
+	for _, row := range tiles {
+		w = max(w, len(row))
+	}
+	out := make([][]int, len(tiles))
+	for y, row := range tiles {
+		out[y] = append(make([]int, 0, w), row...)
+		for len(out[y]) < w {
+			out[y] = append(out[y], TileWall)
+		}
+	}
+	return out
+}
+
+// leaveTestPlay goes back to the editor when F2 is pressed during a test game
+func (g *Game) leaveTestPlay() bool {
+	if g.editor == nil || !g.editor.testing || !inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		return false
+	}
+	g.stopTesting()
+	return true
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"jk/maze"
+)
+
+func TestCloseEditorPutsBackTheMaze(t *testing.T) {
+	g := snapshotTestGame(t)
+	classic := g.currentSetup()
+	t.Cleanup(func() { g.restoreSetup(classic) })
+
+	// Play a generated maze, then edit and test-play another level
+	played, err := maze.Generate(5, 31, 27)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.useLevel(played)
+	want := g.currentSetup()
+	if err := g.openEditor(filepath.Join(t.TempDir(), "new.txt")); err != nil {
+		t.Fatal(err)
+	}
+	g.editor.test(g)
+	if !g.editor.testing || reflect.DeepEqual(levelTemplate, want.template) {
+		t.Fatal("test play didn't switch to the edited level")
+	}
+	g.stopTesting()
+
+	g.closeEditor()
+	if g.State != StateMenu || g.editor != nil {
+		t.Fatalf("state %v, editor %v after closing, want the menu", g.State, g.editor)
+	}
+	if got := g.currentSetup(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("maze after closing the editor:\ngot  %+v\nwant %+v", got, want)
+	}
+	if !reflect.DeepEqual(level, want.template) {
+		t.Error("the maze in play isn't a fresh copy of the one from before")
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"jk/maze"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Endless mode plays a freshly generated maze every round, each round a
+// little harder than the last, until the lives run out. A run is fixed by
+// its seed, so the same seed gives the same mazes and can be shared.
+type Endless struct {
+	Seed  int64
+	home  levelSetup // maze to go back to after the run
+	extra []*Ghost   // curses milestones added
+	rank  int        // place on the endless board once the run is over, -1 if none
+}
+
+// Difficulty curves, by round from 1
+const (
+	endlessSpeedGain    = 0.75 // ghost speed approaches 1 + this times the base
+	endlessSpeedRounds  = 8.0  // rounds for the speed to cover ~63% of the gain
+	endlessFrightDecay  = 0.85 // fright time kept from one round to the next
+	endlessScatterDecay = 0.9  // scatter time kept from one round to the next
+	endlessChaseGain    = 60   // frames of chase added per round
+
+	endlessBoardSize = 10
+)
+
+// endlessMilestone is something that kicks in from a round on
+type endlessMilestone struct {
+	Round     int
+	Text      string
+	Curse     string // a curse of this type joins
+	NoScatter bool   // the curses chase all the time
+}
+
+var endlessMilestones = []endlessMilestone{
+	{Round: 5, Text: "A FIFTH CURSE JOINS", Curse: "jogo"},
+	{Round: 10, Text: "THE CURSES STOP SCATTERING", NoScatter: true},
+	{Round: 15, Text: "A SIXTH CURSE JOINS", Curse: "kenjaku"},
+}
+
+// EndlessScore is a run on the endless board
+type EndlessScore struct {
+	Score int   `json:"score"`
+	Round int   `json:"round"`
+	Seed  int64 `json:"seed"`
+}
+
+// EndlessBest is the top endless score
+func (c *Config) EndlessBest() int {
+	if len(c.EndlessScores) == 0 {
+		return 0
+	}
+	return c.EndlessScores[0].Score
+}
+
+// newEndlessSeed picks a seed short enough to read out to someone
+func newEndlessSeed() int64 {
+	return rand.Int63n(1_000_000)
+}
+
+// startEndless begins an endless run on the mazes of seed
+func (g *Game) startEndless(seed int64) {
+	g.startGame()
+	g.endless = &Endless{Seed: seed, home: g.currentSetup(), rank: -1}
+	stateLog.Info("starting endless run", "seed", seed)
+	g.startNextRound()
+}
+
+// stopEndless ends the run and puts back the maze and curses from before it
+func (g *Game) stopEndless() {
+	e := g.endless
+	if e == nil {
+		return
+	}
+	g.setEndlessCurses(0)
+	g.endless = nil
+	g.restoreSetup(e.home)
+	g.gameState.Difficulty = nil
+	g.ghostManager.SetWaves(nil)
+}
+
+// roundSeed is the maze seed of a round of the run
+func (e *Endless) roundSeed(round int) int64 {
+	return e.Seed*1_000_003 + int64(round)
+}
+
+// nextEndlessMaze sets up the maze, curses and difficulty of the current
+// round; startNextRound then fills the maze and places everyone
+func (g *Game) nextEndlessMaze() {
+	e := g.endless
+	round := g.RoundNumber
+
+	curses, scatter := 0, true
+	for _, m := range endlessMilestones {
+		if round >= m.Round {
+			if m.Curse != "" {
+				curses++
+			}
+			scatter = scatter && !m.NoScatter
+		}
+	}
+	g.setEndlessCurses(curses)
+
+	classic := maze.ClassicLevel()
+	l, err := maze.Generate(e.roundSeed(round), classic.Width(), classic.Height())
+	if err != nil {
+		stateLog.Error("could not generate endless maze, keeping the last one", "seed", e.Seed, "round", round, "err", err)
+	} else {
+		g.setLevel(l)
+	}
+
+	g.gameState.Difficulty = endlessDifficulty(round)
+	g.ghostManager.SetWaves(endlessWaves(round, scatter))
+}
+
+// setEndlessCurses adds or removes milestone curses until there are n
+func (g *Game) setEndlessCurses(n int) {
+	e := g.endless
+	for len(e.extra) > n {
+		ghost := e.extra[len(e.extra)-1]
+		e.extra = e.extra[:len(e.extra)-1]
+		g.ghostManager.RemoveGhost(ghost)
+		for i, gh := range g.Ghosts {
+			if gh == ghost {
+				g.Ghosts = append(g.Ghosts[:i], g.Ghosts[i+1:]...)
+				break
+			}
+		}
+	}
+	var curses []string
+	for _, m := range endlessMilestones {
+		if m.Curse != "" {
+			curses = append(curses, m.Curse)
+		}
+	}
+	for len(e.extra) < n {
+		name := curses[len(e.extra)]
+		ghost := NewGhost(0, 0, "ghost."+name, name, 55)
+		ghost.SetScatterCorner(level)
+		e.extra = append(e.extra, ghost)
+		g.Ghosts = append(g.Ghosts, ghost)
+		g.ghostManager.AddGhost(ghost)
+	}
+}
+
+// endlessDifficulty is the ghost speed and fright time of a round: the
+// speed rises towards its cap and fright time shrinks towards nothing
+func endlessDifficulty(round int) *Difficulty {
+	r := float64(round - 1)
+	return &Difficulty{
+		SpeedMultiplier: 1 + endlessSpeedGain*(1-math.Exp(-r/endlessSpeedRounds)),
+		MaxFright:       int(FRIGHT_DURATION * math.Pow(endlessFrightDecay, r)),
+	}
+}
+
+// endlessWaves is the classic scatter/chase pattern with the scatters
+// shortened and the chases lengthened by round, or chasing only
+func endlessWaves(round int, scatter bool) []GhostWave {
+	if !scatter {
+		return []GhostWave{{-1, ChaseMode}}
+	}
+	scatterScale := math.Pow(endlessScatterDecay, float64(round-1))
+	waves := make([]GhostWave, len(classicWaves))
+	for i, w := range classicWaves {
+		switch {
+		case w.Frames < 0:
+		case w.Mode == ScatterMode:
+			w.Frames = max(1, int(float64(w.Frames)*scatterScale))
+		default:
+			w.Frames += endlessChaseGain * (round - 1)
+		}
+		waves[i] = w
+	}
+	return waves
+}
+
+// frightFrames is how long a power pellet frightens the curses
+func (g *Game) frightFrames() int {
+	if d := g.gameState.Difficulty; d != nil {
+		return d.MaxFright
+	}
+	return FRIGHT_DURATION
+}
+
+// saveEndlessScore puts the finished run on the endless board if it makes it
+func (g *Game) saveEndlessScore() {
+	e := g.endless
+	if g.Player.Score <= 0 {
+		return
+	}
+	run := EndlessScore{Score: g.Player.Score, Round: g.RoundNumber, Seed: e.Seed}
+	board := g.config.EndlessScores
+	rank := sort.Search(len(board), func(i int) bool { return board[i].Score < run.Score })
+	if rank >= endlessBoardSize {
+		return
+	}
+	board = slices.Insert(board, rank, run)
+	g.config.EndlessScores = board[:min(len(board), endlessBoardSize)]
+	e.rank = rank
+	if err := g.config.Save(); err != nil {
+		stateLog.Error("failed to save endless score", "err", err)
+	}
+}
+
+// drawEndlessHUD shows the seed so a run can be shared
+func (g *Game) drawEndlessHUD(screen *ebiten.Image) {
+	style := hudLabelStyle
+	style.Align = AlignCenter
+	DrawText(screen, fmt.Sprintf("ENDLESS  SEED %d", g.endless.Seed), logicalWidth/2, playfieldRect().Max.Y+18, style, g.globalTimer)
+}
+
+// drawEndlessRoundInfo adds the seed and any milestone of the round to the
+// round ready screen
+func (g *Game) drawEndlessRoundInfo(screen *ebiten.Image, cx, cy int) {
+	DrawText(screen, fmt.Sprintf("ENDLESS  SEED %d", g.endless.Seed), cx, cy-130, overlayText, g.RoundReadyTimer)
+	for _, m := range endlessMilestones {
+		if m.Round == g.RoundNumber {
+			warn := overlayText
+			warn.Color = color.RGBA{255, 80, 80, 255}
+			warn.Pulse = 0.2
+			DrawText(screen, m.Text, cx, cy+90, warn, g.RoundReadyTimer)
+		}
+	}
+}
+
+func (g *Game) drawEndlessSummary(screen *ebiten.Image, cx, cy int) {
+	e := g.endless
+	title := overlayTitle
+	title.Color = color.RGBA{255, 50, 50, 255}
+	DrawText(screen, "GAME OVER", cx, cy-190, title, g.globalTimer)
+	line := fmt.Sprintf("%d PTS  ROUND %d  SEED %d", g.Player.Score, g.RoundNumber, e.Seed)
+	DrawText(screen, line, cx, cy-130, overlayText, g.globalTimer)
+
+	label := hudLabelStyle
+	label.Align = AlignCenter
+	DrawText(screen, "ENDLESS BEST", cx, cy-80, label, g.globalTimer)
+	if g.config != nil {
+		for i, s := range g.config.EndlessScores[:min(len(g.config.EndlessScores), 5)] {
+			style := overlayHint
+			style.Pulse = 0
+			if i == e.rank {
+				style.Color = color.RGBA{255, 215, 0, 255}
+				style.Pulse = 0.2
+			}
+			row := fmt.Sprintf("%2d. %7d  ROUND %2d  SEED %d", i+1, s.Score, s.Round, s.Seed)
+			DrawText(screen, row, cx, cy-56+i*20, style, g.globalTimer)
+		}
+	}
+	DrawText(screen, "PRESS SPACE TO RETURN TO MENU", cx, cy+80, overlayHint, g.globalTimer)
+}
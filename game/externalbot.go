@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// External bots are other programs playing Gojo over newline-delimited
+// JSON, either a child process on stdin/stdout or a client of a Unix socket.
+// Every frame the game writes the observation:
+//
+//	{"frame":120,"level":[[1,1,...],...],"player_tile_x":1,...,"ghosts":[...]}
+//
+// and waits up to the move timeout for the reply for that frame:
+//
+//	{"frame":120,"action":"left"}
+//
+// A late reply is dropped and the fallback action is used for that frame.
+// A bot that stops reading its observations for botWriteTimeout is dropped
+// for the rest of the game, rather than blocking it.
+
+// BotOptions apply to external bots
+type BotOptions struct {
+	Timeout  time.Duration // how long to wait for each move
+	Fallback string        // action when the bot is late or gone; "keep" keeps the current direction
+}
+
+// botWriteTimeout is how long an observation may take to write. A bot this
+// far behind on reading is stuck rather than slow.
+const botWriteTimeout = time.Second
+
+var errBotWriteTimeout = errors.New("bot stopped reading its observations")
+
+func defaultBotOptions() BotOptions {
+	return BotOptions{Timeout: 10 * time.Millisecond, Fallback: "keep"}
+}
+
+func (o BotOptions) validate() error {
+	if o.Timeout <= 0 {
+		return fmt.Errorf("bot timeout must be positive, got %v", o.Timeout)
+	}
+	if o.Fallback != "keep" && !isBotAction(o.Fallback) {
+		return fmt.Errorf("unknown fallback action %q", o.Fallback)
+	}
+	return nil
+}
+
+type botReply struct {
+	Frame  int    `json:"frame"`
+	Action string `json:"action"`
+}
+
+// ExternalBot is a Controller backed by another program
+type ExternalBot struct {
+	opts    BotOptions
+	name    string
+	out     io.Writer
+	replies chan botReply
+	closers []io.Closer
+	cmd     *exec.Cmd
+
+	connected chan io.ReadWriteCloser // socket mode: the accepted client
+	gone      chan struct{}           // closed when the bot's output ends
+	dead      bool
+	late      int // moves that missed the timeout, reported now and then
+}
+
+func newExternalBot(name string, opts BotOptions) *ExternalBot {
+	return &ExternalBot{
+		opts:    opts,
+		name:    name,
+		replies: make(chan botReply, 16),
+		gone:    make(chan struct{}),
+	}
+}
+
+// StartBotProcess runs command (split on spaces) and plays through its
+// stdin and stdout. The bot's stderr goes to ours.
+func StartBotProcess(command string, opts BotOptions) (*ExternalBot, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("empty bot command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	b := newExternalBot(args[0], opts)
+	b.cmd = cmd
+	b.out = stdin
+	b.closers = append(b.closers, stdin)
+	go b.read(stdout)
+	return b, nil
+}
+
+// ListenBotSocket waits for a bot to connect to a Unix socket at path.
+// Until one does, the fallback action is used.
+func ListenBotSocket(path string, opts BotOptions) (*ExternalBot, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	os.Remove(path) // a stale socket from an earlier run
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	b := newExternalBot(path, opts)
+	b.connected = make(chan io.ReadWriteCloser, 1)
+	b.closers = append(b.closers, ln)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		inputLog.Info("bot connected", "socket", path)
+		b.connected <- conn
+		b.read(conn)
+	}()
+	inputLog.Info("waiting for a bot", "socket", path)
+	return b, nil
+}
+
+// read forwards replies until the bot's output ends
+func (b *ExternalBot) read(r io.Reader) {
+	defer close(b.gone)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var reply botReply
+		if err := json.Unmarshal(scanner.Bytes(), &reply); err != nil {
+			inputLog.Warn("bot sent a bad line", "bot", b.name, "line", scanner.Text(), "err", err)
+			continue
+		}
+		b.replies <- reply
+	}
+}
+
+func (b *ExternalBot) Act(obs *Observation) string {
+	if b.out == nil && b.connected != nil {
+		select {
+		case conn := <-b.connected:
+			b.out = conn
+			b.closers = append(b.closers, conn)
+		default:
+			return b.fallback(obs)
+		}
+	}
+	if b.dead {
+		return b.fallback(obs)
+	}
+
+	data, err := json.Marshal(obs)
+	if err == nil {
+		err = b.write(append(data, '\n'))
+	}
+	if err != nil {
+		b.die(err)
+		return b.fallback(obs)
+	}
+
+	timeout := time.NewTimer(b.opts.Timeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case reply := <-b.replies:
+			if reply.Frame != obs.Frame {
+				continue // a late answer to an earlier frame
+			}
+			if !isBotAction(reply.Action) {
+				inputLog.Warn("bot sent an unknown action", "bot", b.name, "action", reply.Action)
+				return b.fallback(obs)
+			}
+			return reply.Action
+		case <-b.gone:
+			b.die(errors.New("bot closed its output"))
+			return b.fallback(obs)
+		case <-timeout.C:
+			b.late++
+			if b.late%60 == 1 {
+				inputLog.Warn("bot is missing moves", "bot", b.name, "missed", b.late)
+			}
+			return b.fallback(obs)
+		}
+	}
+}
+
+// write sends one line to the bot, giving up after botWriteTimeout. Sockets
+// take a write deadline; a pipe can't, so the line is written from a
+// goroutine that Close unblocks once the bot is dropped.
+func (b *ExternalBot) write(line []byte) error {
+	if conn, ok := b.out.(net.Conn); ok {
+		if err := conn.SetWriteDeadline(time.Now().Add(botWriteTimeout)); err != nil {
+			return err
+		}
+		_, err := conn.Write(line)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			err = errBotWriteTimeout
+		}
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := b.out.Write(line)
+		done <- err
+	}()
+	timeout := time.NewTimer(botWriteTimeout)
+	defer timeout.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timeout.C:
+		return errBotWriteTimeout
+	}
+}
+
+func (b *ExternalBot) fallback(obs *Observation) string {
+	if b.opts.Fallback == "keep" {
+		return obs.Direction
+	}
+	return b.opts.Fallback
+}
+
+func (b *ExternalBot) die(err error) {
+	if !b.dead {
+		b.dead = true
+		inputLog.Error("bot stopped", "bot", b.name, "err", err)
+	}
+}
+
+// Close ends the connection and stops a child process
+func (b *ExternalBot) Close() error {
+	for _, c := range b.closers {
+		c.Close()
+	}
+	if b.cmd != nil {
+		done := make(chan struct{})
+		go func() {
+			b.cmd.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			b.cmd.Process.Kill()
+		}
+	}
+	return nil
+}
+
+func isBotAction(action string) bool {
+	switch action {
+	case "", "up", "down", "left", "right":
+		return true
+	}
+	return false
+}
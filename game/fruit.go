@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// A bonus fruit sits below the ghost house for a while and is worth points
+// when Gojo reaches it. For now only the console spawns one.
+const (
+	fruitFrames = 600 // 10 seconds at 60 FPS
+	fruitPoints = 100
+)
+
+// Where the fruit appears, or the nearest open tile to it
+var fruitTile = [2]int{13, 16}
+
+type Fruit struct {
+	X, Y   int // tile
+	Timer  int // frames left
+	Points int
+}
+
+// spawnFruit places a fruit, replacing any that is already out
+func (g *Game) spawnFruit() error {
+	x, y, ok := nearestOpenTile(level, fruitTile[0], fruitTile[1])
+	if !ok {
+		return errors.New("no open tile for the fruit")
+	}
+	g.fruit = &Fruit{X: x, Y: y, Timer: fruitFrames, Points: fruitPoints}
+	return nil
+}
+
+// updateFruit counts the fruit down and lets Gojo eat it
+func (g *Game) updateFruit() {
+	f := g.fruit
+	if f == nil {
+		return
+	}
+	f.Timer--
+	px := int(g.Player.X+TileSize/2) / TileSize
+	py := int(g.Player.Y+TileSize/2) / TileSize
+	if px == f.X && py == f.Y {
+		g.Player.Score += f.Points
+		if g.AudioSystem != nil {
+			g.AudioSystem.PlaySFX("ghost_eaten")
+		}
+		stateLog.Info("fruit eaten", "points", f.Points)
+		g.fruit = nil
+		return
+	}
+	if f.Timer <= 0 {
+		g.fruit = nil
+	}
+}
+
+func (g *Game) drawFruit(screen *ebiten.Image) {
+	if g.fruit == nil {
+		return
+	}
+	// Blink for the last two seconds
+	if g.fruit.Timer < 120 && (g.fruit.Timer/10)%2 == 0 {
+		return
+	}
+	drawIcon(screen, Assets.Image("fruit.cherry"), float64(g.fruit.X*TileSize), float64(g.fruit.Y*TileSize), TileSize)
+}
+
+// nearestOpenTile searches outwards from (x, y) for a tile that isn't a wall
+func nearestOpenTile(level [][]int, x, y int) (int, int, bool) {
+	for radius := 0; radius < len(level)+len(level[0]); radius++ {
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				tx, ty := x+dx, y+dy
+				if ty >= 0 && ty < len(level) && tx >= 0 && tx < len(level[0]) && level[ty][tx] != TileWall {
+					return tx, ty, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
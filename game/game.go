@@ -4,14 +4,13 @@ import (
     "github.com/hajimehoshi/ebiten/v2"
     "github.com/hajimehoshi/ebiten/v2/ebitenutil"
     "fmt"
+    "image"
     "image/color"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"log"
 	"math"
-)
+	"math/rand"
 
-var glowColor = color.RGBA{255, 50, 50, 255} // bright red center
-var outerGlowColor = color.RGBA{255, 50, 50, 100} // transparent red for glow
+	"jk/maze"
+)
 
 type GameState int
 
@@ -23,6 +22,10 @@ const(
     StateIntro
     RoundReady
     StateRoundReady
+    StateSettings
+    StateNetLobby
+    StateEditor
+    StateBoss
 )
 
 type GameStateStruct struct{
@@ -37,6 +40,8 @@ type GameStateStruct struct{
     CurrentLevel       int
     GhostManager *GhostManager
     NewGhostManager *GhostManager
+    Rand *rand.Rand // random choices of the ghosts; nil uses simRand
+    Difficulty *Difficulty // nil scales by CurrentLevel
 }
 
 type Game struct{
@@ -65,6 +70,47 @@ type Game struct{
     ShowRoundReady bool
     AudioSystem *AudioSystem
 
+    config   *Config
+    themes   []*ThemePack
+    settings *SettingsPage
+    mazeImage *ebiten.Image // pre-rendered walls and floor, nil until first drawn
+
+    // Alternating two-player mode, see twoplayer.go
+    twoPlayer     bool
+    boards        [2]*PlayerBoard
+    currentPlayer int
+
+    versus *Versus // set while a second player drives a ghost, see versus.go
+    net    *NetSession // set while hosting or joining a networked match, see netplay.go
+
+    // Replays, see savefile.go
+    recorder *ReplayRecorder
+    replay   *ReplayReader
+
+    // Spectating, see spectate.go
+    spectateServer *SpectateServer
+    spectating     *SpectateClient
+
+    // AI debug overlay, see aidebug.go
+    debugOverlay bool
+    slowMotion   bool
+
+    // Developer console, see console.go
+    console *Console
+    godMode bool // ghosts can't catch Gojo
+
+    fruit *Fruit // bonus fruit on the maze, nil when there is none, see fruit.go
+
+    editor *LevelEditor // level editor, nil unless started with --edit, see editor.go
+
+    endless *Endless // endless run in progress, nil otherwise, see endless.go
+    rules   Ruleset  // nil plays the classic rules, see rules.go
+    boss    *Boss    // Sukuna in a boss round, nil otherwise, see boss.go
+
+    windowSize image.Point // size Layout was last given
+
+    canvas    *ebiten.Image // logical-resolution frame, see layout.go
+    world     *ebiten.Image // maze-sized frame the playfield is drawn into
 }
 
 const TileSize = 32
@@ -81,14 +127,14 @@ func NewGame() *Game {
     AudioSystem:= NewAudioSystem()
     
     if AudioSystem != nil {
-        fmt.Println("🎵 Initializing audio system...")
+        audioLog.Info("initializing audio system")
         AudioSystem.LoadAllAudio()
     } else {
-        fmt.Println("❌ Failed to initialize audio system")
+        audioLog.Error("failed to initialize audio system")
     }
 
     g := &Game{
-        Player: NewPlayer(playerStartX, playerStartY, "assets/player.png"),
+        Player: NewPlayer(playerStartX, playerStartY, "player"),
 		menuUI: NewUIPage(),
         State: StateMenu,
         lives: 3,
@@ -102,16 +148,13 @@ func NewGame() *Game {
         RoundNumber: 1,
         ShowRoundReady: false,
         AudioSystem: AudioSystem,
+        settings: NewSettingsPage(),
+        console: NewConsole(),
     }
 
     g.ghostManager = NewGhostManager(gameState) 
     
-	ghosts := []*Ghost{
-		NewGhost(13*TileSize, 13*TileSize, "assets/jogo.png", "jogo", 55),
-    	NewGhost(12*TileSize, 13*TileSize, "assets/sakuna.png", "sukuna", 55),    
-    	NewGhost(14*TileSize, 13*TileSize, "assets/kenjaku.png", "kenjaku", 55),  
-    	NewGhost(13*TileSize, 14*TileSize, "assets/mahito.png", "mahito", 55),
-	}
+	ghosts := newCurses()
 
 	//g.AudioSystem.LoadAllAudio()
 
@@ -126,12 +169,12 @@ func NewGame() *Game {
     InitPellets(level, TileSize)
 	g.countPellets()
 
-	fmt.Println("🎵 Starting intro music...")
+	audioLog.Info("starting intro music")
 	if g.AudioSystem!=nil{
     	g.AudioSystem.PlayIntroMusic()
 
 	}else{
-    	fmt.Println("⚠️  AudioSystem is nil")
+    	audioLog.Warn("audio system is nil")
 	}
 	// if g.AudioSystem != nil {
  //        fmt.Println("🎵 AudioSystem is available, playing intro music")
@@ -143,12 +186,56 @@ func NewGame() *Game {
 }
 
 func (g *Game) Update() error {
-    g.globalTimer++
-    g.updateGameState()
+    Input.Update()
+    g.updateVersusInput()
     if g.AudioSystem != nil {
         g.AudioSystem.Update()
+    }
+    if g.console.Update(g) {
+        return nil
     }
      g.handleSoundControls()
+    g.handleDebugControls()
+
+    // In a networked match the simulation only advances when every player's input has arrived
+    if g.net != nil {
+        return g.updateNetplay()
+    }
+    if g.replay != nil {
+        return g.updateReplay()
+    }
+    if g.spectating != nil {
+        return g.updateSpectating()
+    }
+    if err := g.step(); err != nil {
+        return err
+    }
+    g.frameDone()
+    return nil
+}
+
+// frameDone hands a simulated frame to the replay recorder and spectators
+func (g *Game) frameDone() {
+    g.recordFrame()
+    g.publishFrame()
+}
+
+// inMatch reports whether a game is in progress, as opposed to the menus
+func (g *Game) inMatch() bool {
+    switch g.State {
+    case StatePlaying, StateRoundReady, StateGameOver, StateBoss:
+        return true
+    }
+    return false
+}
+
+// step advances the game by one frame
+func (g *Game) step() error {
+    g.globalTimer++
+    g.updateGameState()
+    if g.leaveTestPlay() {
+        return nil
+    }
 
     switch g.State {
     case StateMenu:
@@ -163,6 +250,14 @@ func (g *Game) Update() error {
         return g.updateIntro()
     case StateRoundReady:
         return g.updateRoundReady()
+    case StateSettings:
+        return g.updateSettings()
+    case StateNetLobby:
+        // netplay.go drives the lobby
+    case StateEditor:
+        return g.editor.Update(g)
+    case StateBoss:
+        return g.updateBoss()
     }
     return nil
 }
@@ -177,7 +272,7 @@ func (g *Game) updateIntro() error {
       // Check if intro is complete
 
        if g.IntroSystem.IsComplete() {
-            fmt.Println("Intro complete, transitioning to menu")
+            stateLog.Info("intro complete, transitioning to menu")
             g.State = StateMenu
             // if g.AudioSystem!=nil{
             //     g.AudioSystem.PlayMenuMusic()
@@ -186,7 +281,7 @@ func (g *Game) updateIntro() error {
             // }
 
             if g.AudioSystem != nil {
-                fmt.Println("🎵 Stopping intro music, starting menu music")
+                audioLog.Info("stopping intro music, starting menu music")
                 g.AudioSystem.StopBGM()  // Stop intro music first
                 g.AudioSystem.PlayMenuMusic()  // Then play menu music
                 g.AudioSystem.PlaySFX("transition")
@@ -201,11 +296,10 @@ func (g *Game) updateRoundReady() error {
     
   // Auto-advance after 3 seconds or on input
 
-   if g.RoundReadyTimer > 180 || 
-       inpututil.IsKeyJustPressed(ebiten.KeySpace) || 
-       inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+   skip := g.net == nil && Input.JustPressed(ActionConfirm) // peers can't skip independently
+   if g.RoundReadyTimer > 180 || skip {
         
-      g.State = StatePlaying
+      g.State = g.playState()
 
        g.ShowRoundReady = false
         g.RoundReadyTimer = 0
@@ -216,11 +310,15 @@ func (g *Game) updateRoundReady() error {
        //  g.SoundManager.PlaySFX("round_start")
         if g.AudioSystem != nil {
             g.AudioSystem.StopBGM()//i added this i thing go wrong remove this stops menu music 
-            g.AudioSystem.PlayGameMusic()  // This will play "game_theme"
+            if g.boss != nil {
+                g.AudioSystem.PlayBossMusic()
+            } else {
+                g.AudioSystem.PlayGameMusic()  // This will play "game_theme"
+            }
             g.AudioSystem.PlaySFX("round_start")
             g.AudioSystem.PlaySFX("game_start")
         }
-      fmt.Printf("Starting round %d\n", g.RoundNumber)
+      stateLog.Info("starting round", "round", g.RoundNumber)
     }
     
     
@@ -252,29 +350,68 @@ func (g *Game) updateMenu() error {
         if g.menuUI.IsEnterPressed() {
             switch g.menuUI.GetSelectedOption() {
             case 0: // START GAME
-                fmt.Println("Starting game...")
+                g.startGame()
+                 if g.AudioSystem != nil {
+                    g.AudioSystem.PlaySFX("menu_select")
+                }
+                // g.SoundManager.PlaySFX("menu_selected")
+            case 1: // ENDLESS
+                g.startEndless(newEndlessSeed())
+                if g.AudioSystem != nil {
+                    g.AudioSystem.PlaySFX("menu_select")
+                }
+            case 2: // TIME ATTACK
+                practice := g.config != nil && g.config.Practice
+                g.startRuleset(&TimeAttack{Practice: practice})
+                if g.AudioSystem != nil {
+                    g.AudioSystem.PlaySFX("menu_select")
+                }
+            case 3: // PELLET RUSH
+                g.startRuleset(&PelletRush{})
+                if g.AudioSystem != nil {
+                    g.AudioSystem.PlaySFX("menu_select")
+                }
+            case 4: // 2 PLAYERS
+                stateLog.Info("starting two player game")
                 g.State = StateRoundReady
                 g.ShowRoundReady=true
                 g.RoundReadyTimer=0
-                g.RoundNumber=1 
                 g.resetGame()
-                 if g.AudioSystem != nil {
+                g.startTwoPlayer()
+                if g.AudioSystem != nil {
                     g.AudioSystem.PlaySFX("menu_select")
                 }
-                // g.SoundManager.PlaySFX("menu_selected")
-            case 1: // SETTINGS (you can implement later)
-                fmt.Println("Settings selected")
+            case 5: // VERSUS
+                stateLog.Info("starting versus game")
+                g.State = StateRoundReady
+                g.ShowRoundReady=true
+                g.RoundReadyTimer=0
+                g.resetGame()
+                g.startVersus()
+                if g.AudioSystem != nil {
+                    g.AudioSystem.PlaySFX("menu_select")
+                }
+            case 6: // EDITOR
+                if err := g.openEditor(customLevelPath()); err != nil {
+                    stateLog.Warn("could not open the editor", "err", err)
+                    break
+                }
+                if g.AudioSystem != nil {
+                    g.AudioSystem.PlaySFX("menu_select")
+                }
+            case 7: // SETTINGS
+                stateLog.Info("settings selected")
                  if g.AudioSystem != nil {
                     g.AudioSystem.PlaySFX("menu_select")
                 }
-                // For now, do nothing or show a message
-            case 2: // GALLERY (you can implement later)  
-                fmt.Println("Gallery selected")
+                g.openSettings()
+            case 8: // GALLERY (you can implement later)  
+                stateLog.Info("gallery selected")
                  if g.AudioSystem != nil {
                     g.AudioSystem.PlaySFX("menu_select")
                 }
                 // For now, do nothing or show a message
-            case 3: // EXIT
+            case 9: // EXIT
                 return fmt.Errorf("quit game")
 
             }
@@ -283,32 +420,38 @@ func (g *Game) updateMenu() error {
     return nil
 }
 
+// startGame starts a one-player game from round 1
+func (g *Game) startGame() {
+    stateLog.Info("starting game")
+    g.State = StateRoundReady
+    g.ShowRoundReady=true
+    g.RoundReadyTimer=0
+    g.RoundNumber=1 
+    g.resetGame()
+}
+
 func (g *Game) updateGame() error {
-    // Handle pause
-    if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+    // Handle pause, except in networked matches which can't pause for one peer
+    if g.net == nil && Input.JustPressed(ActionPause) {
         g.State = StatePaused
          if g.AudioSystem != nil {
             g.AudioSystem.PlaySFX("pause")
         }
         return nil
     }
-        // Debug: Print game state occasionally
-    if g.globalTimer%120 == 0 { // Every 2 seconds
-        fmt.Printf("=== GAME DEBUG ===\n")
-        fmt.Printf("Global Timer: %d\n", g.globalTimer)
-        fmt.Printf("Player Position: (%.1f, %.1f)\n", g.Player.X, g.Player.Y)
-        fmt.Printf("Number of ghosts: %d\n", len(g.Ghosts))
-        fmt.Printf("Ghost Manager exists: %v\n", g.ghostManager != nil)
-        
+    g.handleQuickSave()
+    // Debug: log game state occasionally
+    if g.globalTimer%120 == 0 && debugEnabled(stateLog) { // Every 2 seconds
+        stateLog.Debug("game",
+            "timer", g.globalTimer,
+            "player_x", g.Player.X, "player_y", g.Player.Y,
+            "ghosts", len(g.Ghosts), "ghost_manager", g.ghostManager != nil)
         for i, ghost := range g.Ghosts {
             if ghost != nil {
-                fmt.Printf("Ghost %d (%s): (%.1f, %.1f) Speed: %.2f Visible: %v\n", 
-                    i, ghost.GhostType, ghost.X, ghost.Y, ghost.Speed, ghost.Visible)
-            } else {
-                fmt.Printf("Ghost %d: nil\n", i)
+                stateLog.Debug("game ghost", "index", i, "ghost", ghost.GhostType,
+                    "x", ghost.X, "y", ghost.Y, "speed", ghost.Speed, "visible", ghost.Visible)
             }
         }
-        fmt.Printf("=================\n")
     }
 
 
@@ -330,12 +473,12 @@ func (g *Game) updateGame() error {
         if g.powerPelletTimer <= 0 {
             g.powerPelletActive = false
             g.gameState.FrightModeActive = false
-            fmt.Println("Power pellet mode ended")
+            stateLog.Debug("power pellet mode ended")
             
 
             if g.AudioSystem != nil {
                 g.AudioSystem.PlaySFX("power_pellet_end")
-				fmt.Print("🎵 Power mode ended, returning to game music")
+				audioLog.Debug("power mode ended, returning to game music")
 				g.AudioSystem.StopBGM()  // Stop power mode music first
                 g.AudioSystem.EndPowerMode()  // This will play "game_theme" again
             }
@@ -353,24 +496,24 @@ func (g *Game) updateGame() error {
             }
         }
     }
+    rules := g.ruleset()
+    rules.Update(g)
+
     // Update ghosts - try both methods to see which works
-    fmt.Printf("Updating ghosts... Manager: %v, Direct: %d ghosts\n", 
-        g.ghostManager != nil, len(g.Ghosts))
     // Update ghosts using ghost manager
-    if g.ghostManager != nil {
+    if g.ghostManager != nil && rules.Ghosts() {
         g.ghostManager.UpdateAll()
     }
 
     // Method 2: Update ghosts directly (for debugging)
-    for i, ghost := range g.Ghosts {
-        if ghost != nil {
-            fmt.Printf("Updating ghost %d (%s)\n", i, ghost.GhostType)
+    for _, ghost := range g.Ghosts {
+        if ghost != nil && rules.Ghosts() {
             ghost.Update(g.gameState)
         }
     }
     
     // Check for collisions using ghost manager
-    if g.ghostManager != nil {
+    if g.ghostManager != nil && rules.Ghosts() {
         result := g.ghostManager.CheckCollisions(g.Player.X, g.Player.Y)
         switch result {
         case "ghost_eaten":
@@ -382,22 +525,29 @@ func (g *Game) updateGame() error {
             // g.SoundManager.PlaySFX("ghost_eaten")
             // Ghost manager already handles the ghost state change
         case "player_caught":
+            if g.godMode {
+                break
+            }
             g.lives--
+            if g.versus != nil {
+                g.onVersusCatch(g.ghostManager.LastCollider)
+            }
             if g.AudioSystem != nil {
                 g.AudioSystem.PlaySFX("player_death")
             }
             // g.SoundManager.PlaySFX("player_death")
             g.resetPlayerPosition()
             
-            
-            if g.lives <= 0 {
-                g.State = StateGameOver
-                if g.AudioSystem != nil {
-                    g.AudioSystem.PlaySFX("game_over")
-                    g.AudioSystem.StopBGM()
+            // In two-player mode every death hands over to the other player
+            if g.twoPlayer {
+                if g.swapPlayers() {
+                    return nil
                 }
-                // g.SoundManager.PlaySFX("game_over")
-                // g.SoundManager.StopBGM()
+                g.lives = 0
+            }
+            
+            if rules.Over(g) {
+                g.endGame()
                 return nil
             }
             
@@ -407,38 +557,60 @@ func (g *Game) updateGame() error {
     
     // Check pellet collection
     g.checkPelletCollection()
+    g.updateFruit()
     
-    // Check win condition
-    if g.pelletCount <= 0 {
+    // Check win and lose conditions
+    if rules.Over(g) {
+        g.endGame()
+        return nil
+    }
+    if rules.Cleared(g) {
+        if g.versus != nil {
+            g.onVersusClear()
+        }
         g.RoundNumber++
         g.State=StateRoundReady
         g.ShowRoundReady=true
         g.RoundReadyTimer=0
-        g.resetGame()
+        g.startNextRound()
         // g.SoundManager.PlaySFX("round_complete")
         if g.AudioSystem != nil {
             g.AudioSystem.PlaySFX("round_complete")
         }
 
         
-        fmt.Printf("Round %d completed! Advancing to round %d \n",g.RoundNumber-1,g.RoundNumber)
+        stateLog.Info("round completed", "round", g.RoundNumber-1, "next", g.RoundNumber)
     }
     
     return nil
 }
 
+// endGame ends the game under its rules
+func (g *Game) endGame() {
+    g.State = StateGameOver
+    g.ruleset().Finish(g)
+    if g.AudioSystem != nil {
+        g.AudioSystem.PlaySFX("game_over")
+        g.AudioSystem.StopBGM()
+    }
+}
+
 func (g *Game) updatePaused() error {
-    if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-        g.State = StatePlaying
+    g.handleQuickSave()
+    if Input.JustPressed(ActionPause) {
+        g.State = g.playState()
         if g.AudioSystem != nil {
             g.AudioSystem.PlaySFX("unpause")
         }
+        return nil
     }
-    return nil
+    return g.stepWhilePaused()
 }
 
 func (g *Game) updateGameOver() error {
-    if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+    if Input.JustPressed(ActionConfirm) && g.editor != nil && g.editor.testing {
+        g.stopTesting()
+    } else if Input.JustPressed(ActionConfirm) {
         g.resetGame()
         g.State = StateMenu
     }
@@ -470,29 +642,33 @@ func (g *Game) checkPelletCollection() {
         g.Player.Score += 50
         g.pelletCount--
         
-        // Activate power pellet mode
-        g.powerPelletActive = true
-        g.powerPelletTimer = 600 // 10 seconds at 60 FPS
-        g.gameState.FrightModeActive=true
+        g.startPowerMode(g.frightFrames())
+    }
+}
 
-        // g.SoundManager.PlaySFX("power_pellet")
-         if g.AudioSystem != nil {
-            g.AudioSystem.PlaySFX("power_pellet")
-            fmt.Println("🎵 Starting power mode music")
-            g.AudioSystem.StopBGM()//this will stop current music if sounds weird remove
-            g.AudioSystem.PlayPowerMode()  // This will play "power_mode" BGM
-        }
-        // Set all visible ghosts to frightened mode
-        for _, ghost := range g.Ghosts {
-            if ghost.Visible {
-                ghost.SetFrightened(600)
-            }
-        }
-        // Also trigger through ghost manager
-        if g.ghostManager != nil {
-            g.ghostManager.TriggerFrightMode()
-        }
+// startPowerMode frightens the ghosts for the given number of frames, as a
+// power pellet does
+func (g *Game) startPowerMode(frames int) {
+    g.powerPelletActive = true
+    g.powerPelletTimer = frames
+    g.gameState.FrightModeActive=true
 
+    // g.SoundManager.PlaySFX("power_pellet")
+    if g.AudioSystem != nil {
+        g.AudioSystem.PlaySFX("power_pellet")
+        audioLog.Debug("starting power mode music")
+        g.AudioSystem.StopBGM()//this will stop current music if sounds weird remove
+        g.AudioSystem.PlayPowerMode()  // This will play "power_mode" BGM
+    }
+    // Set all visible ghosts to frightened mode
+    for _, ghost := range g.Ghosts {
+        if ghost.Visible {
+            ghost.SetFrightened(frames)
+        }
+    }
+    // Also trigger through ghost manager
+    if g.ghostManager != nil {
+        g.ghostManager.TriggerFrightMode()
     }
 }
 
@@ -507,21 +683,72 @@ func (g *Game) resetPlayerPosition() {
 
 
 func (g *Game) resetGame() {
+    g.stopEndless()
+    g.rules = nil
     if g.Player != nil {
         g.Player.Score = 0
         g.resetPlayerPosition()
     } else {
-        log.Println("⚠️ Warning: g.Player is nil during resetGame")
+        stateLog.Warn("player is nil during resetGame")
     }
     g.lives = 3
+    g.RoundNumber = 1
+    g.fruit = nil
+    g.boss = nil
+    g.twoPlayer = false
+    g.stopVersus()
     g.resetGhosts()
     
     // Reset level pellets
+    copyLevelInto(level, levelTemplate)
     InitPellets(level, TileSize)
     g.countPellets()
 }
 
+// startNextRound refills the maze after a cleared round, keeping score and lives
+func (g *Game) startNextRound() {
+    if g.endless != nil {
+        g.nextEndlessMaze()
+    }
+    g.fruit = nil
+    g.boss = nil
+    g.resetPlayerPosition()
+    g.resetGhosts()
+    g.powerPelletActive = false
+    g.powerPelletTimer = 0
+    g.refillMaze()
+    if g.bossRoundDue() {
+        g.startBoss()
+    }
+}
+
+// refillMaze puts every pellet back
+func (g *Game) refillMaze() {
+    copyLevelInto(level, levelTemplate)
+    InitPellets(level, TileSize)
+    g.countPellets()
+}
+
+// levelTemplate is the untouched maze; level itself loses pellets as they are eaten
+var levelTemplate = copyLevel(level)
+
+func copyLevel(src [][]int) [][]int {
+    return maze.Copy(src)
+}
+
+// copyLevelInto overwrites dst with src; both must have the same shape
+func copyLevelInto(dst, src [][]int) {
+    for y := range dst {
+        copy(dst[y], src[y])
+    }
+}
+
 func (g *Game) countPellets() {
+    g.pelletCount = countPelletsIn(level)
+}
+
+// countPelletsIn counts the pellets and power pellets left in level
+func countPelletsIn(level [][]int) int {
     count := 0
     for _, row := range level {
         for _, tile := range row {
@@ -530,10 +757,27 @@ func (g *Game) countPellets() {
             }
         }
     }
-    g.pelletCount = count
+    return count
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+    // Everything is drawn at the logical resolution and scaled to the window at the end
+    g.canvas = offscreen(g.canvas, logicalWidth, logicalHeight)
+    g.drawState(g.canvas)
+    if g.net != nil && g.State != StateNetLobby {
+        g.drawNetStatus(g.canvas)
+    }
+    if g.replay != nil {
+        g.drawReplayStatus(g.canvas)
+    }
+    if g.spectating != nil {
+        g.drawSpectateStatus(g.canvas)
+    }
+    g.console.Draw(g.canvas, g.globalTimer)
+    g.present(screen, g.canvas)
+}
+
+func (g *Game) drawState(screen *ebiten.Image) {
     switch g.State {
     case StateMenu:
         if g.menuUI != nil {
@@ -541,15 +785,20 @@ func (g *Game) Draw(screen *ebiten.Image) {
         }
         return
         
-    case StatePlaying, StatePaused:
-        g.drawGame(screen)
+    case StatePlaying, StatePaused, StateBoss:
+        g.drawPlayfield(screen)
+        g.drawHUD(screen)
+        g.drawBossOverlay(screen)
         
-        if g.State == StatePaused {
+        if g.State == StatePaused && g.debugOverlay {
+            g.drawDebugPauseHint(screen)
+        } else if g.State == StatePaused {
             g.drawPauseOverlay(screen)
         }
         
     case StateGameOver:
-        g.drawGame(screen)
+        g.drawPlayfield(screen)
+        g.drawHUD(screen)
         g.drawGameOverOverlay(screen)
 
     case StateIntro:
@@ -561,48 +810,57 @@ func (g *Game) Draw(screen *ebiten.Image) {
      case StateRoundReady:      // Add round ready drawing
         g.drawRoundReady(screen)
         return
+
+    case StateSettings:
+        g.drawSettings(screen)
+        return
+
+    case StateNetLobby:
+        g.drawNetLobby(screen)
+        return
+
+    case StateEditor:
+        g.editor.Draw(screen, g)
+        return
     }
 }
 
 func (g *Game) drawGame(screen *ebiten.Image) {
-    // Draw maze background
-    DrawMaze(screen)
+    // Draw maze background, rendered once per level
+    if g.mazeImage == nil {
+        g.mazeImage = renderMaze(level, TileSize)
+    }
+    screen.DrawImage(g.mazeImage, nil)
     
-    // Draw level tiles with pellets
+    // Draw pellets on top of the maze
     for y, row := range level {
         for x, tile := range row {
-            op := &ebiten.DrawImageOptions{}
-            op.GeoM.Translate(float64(x*TileSize), float64(y*TileSize))
-
             switch tile {
-            case TileWall:
-                screen.DrawImage(WallImage, op)
             case TilePellet:
-                screen.DrawImage(FloorImage, op)
                 g.drawPellet(screen, x, y, false)
             case TilePowerPellet:
-                screen.DrawImage(FloorImage, op)
                 g.drawPellet(screen, x, y, true)
-            case TileEmpty:
-                screen.DrawImage(FloorImage, op)
             }
         }
     }
+    g.drawFruit(screen)
     
     // Draw ghosts
     for _, ghost := range g.Ghosts {
-        if ghost.Visible {
+        if ghost.Visible && g.ruleset().Ghosts() && g.boss == nil {
             ghost.Draw(screen)
         }
     }
+    g.drawBoss(screen)
     
     // Draw player on top
     if g.Player != nil {
         g.Player.Draw(screen)
     }
-    
-    // Draw UI
-    g.drawUI(screen)
+
+    if g.debugOverlay {
+        g.drawAIDebug(screen)
+    }
 }
 
 func (g *Game) drawPellet(screen *ebiten.Image, x, y int, isPowerPellet bool) {
@@ -617,81 +875,105 @@ func (g *Game) drawPellet(screen *ebiten.Image, x, y int, isPowerPellet bool) {
             size += 2.0 * float64(g.powerPelletTimer%30) / 30.0
         }
         
-        ebitenutil.DrawRect(screen, cx-size, cy-size, size*2, size*2, outerGlowColor)
-        ebitenutil.DrawRect(screen, cx-size/2, cy-size/2, size, size, glowColor)
+        ebitenutil.DrawRect(screen, cx-size, cy-size, size*2, size*2, palette.OuterGlow)
+        ebitenutil.DrawRect(screen, cx-size/2, cy-size/2, size, size, palette.Glow)
     } else {
         // Draw regular pellet
         pelletSize := 3.0
-        ebitenutil.DrawRect(screen, cx-pelletSize/2, cy-pelletSize/2, pelletSize, pelletSize, color.RGBA{255, 255, 0, 255})
+        ebitenutil.DrawRect(screen, cx-pelletSize/2, cy-pelletSize/2, pelletSize, pelletSize, palette.Pellet)
     }
 }
 
-func (g *Game) drawUI(screen *ebiten.Image) {
-    // Score
-    ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Score: %d", g.Player.Score), 10, 10)
-    
-    // Lives
-    ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Lives: %d", g.lives), 10, 30)
-    
-    // Power pellet timer
-    if g.powerPelletActive {
-        timeLeft := g.powerPelletTimer / 60 // Convert to seconds
-        ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Power: %ds", timeLeft), 10, 50)
+// highScore is the best saved score, or the current one if it is higher
+func (g *Game) highScore() int {
+    best := 0
+    if g.config != nil && g.endless != nil {
+        best = g.config.EndlessBest()
+    } else if g.config != nil {
+        best = g.config.HighScore
+    }
+    if g.Player != nil && g.Player.Score > best {
+        best = g.Player.Score
+    }
+    return best
+}
+
+// saveHighScore stores the best score of the game in the config if it beats the saved one
+func (g *Game) saveHighScore() {
+    if g.config == nil || g.Player == nil {
+        return
+    }
+    best := g.Player.Score
+    if g.twoPlayer {
+        for _, b := range g.boards {
+            best = max(best, b.Score)
+        }
+    }
+    if g.endless != nil {
+        g.saveEndlessScore()
+        return
+    }
+    if best <= g.config.HighScore {
+        return
+    }
+    g.config.HighScore = best
+    if err := g.config.Save(); err != nil {
+        stateLog.Error("failed to save high score", "err", err)
     }
-    
-    // Pellets remaining
-    ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Pellets: %d", g.pelletCount), 10, 70)
 }
 
 func (g *Game) drawPauseOverlay(screen *ebiten.Image) {
     // Semi-transparent overlay
-    ebitenutil.DrawRect(screen, 0, 0, float64(len(level[0])*TileSize), float64(len(level)*TileSize), 
+    field := playfieldRect()
+    ebitenutil.DrawRect(screen, float64(field.Min.X), float64(field.Min.Y), float64(field.Dx()), float64(field.Dy()), 
                        color.RGBA{0, 0, 0, 128})
     
     // Pause text
-    width := len(level[0]) * TileSize
-    height := len(level) * TileSize
-    ebitenutil.DebugPrintAt(screen, "PAUSED", width/2-30, height/2)
-    ebitenutil.DebugPrintAt(screen, "Press ESC to resume", width/2-70, height/2+20)
+    cx, cy := (field.Min.X+field.Max.X)/2, (field.Min.Y+field.Max.Y)/2
+    DrawText(screen, "PAUSED", cx, cy-40, overlayTitle, g.globalTimer)
+    DrawText(screen, "PRESS ESC TO RESUME", cx, cy+20, overlayHint, g.globalTimer)
 }
 
 func (g *Game) drawGameOverOverlay(screen *ebiten.Image) {
     // Semi-transparent overlay
-    ebitenutil.DrawRect(screen, 0, 0, float64(len(level[0])*TileSize), float64(len(level)*TileSize), 
+    field := playfieldRect()
+    ebitenutil.DrawRect(screen, float64(field.Min.X), float64(field.Min.Y), float64(field.Dx()), float64(field.Dy()), 
                        color.RGBA{0, 0, 0, 128})
     
     // Game over text
-    width := len(level[0]) * TileSize
-    height := len(level) * TileSize
-    ebitenutil.DebugPrintAt(screen, "GAME OVER", width/2-40, height/2-10)
-    ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Final Score: %d", g.Player.Score), width/2-60, height/2+10)
-    ebitenutil.DebugPrintAt(screen, "Press SPACE to return to menu", width/2-100, height/2+30)
-}
-
-func DrawMaze(screen *ebiten.Image) {
-    for y, row := range level {
-        for x, tile := range row {
-            op := &ebiten.DrawImageOptions{}
-            op.GeoM.Translate(float64(x*TileSize), float64(y*TileSize))
-
-            switch tile {
-            case TileWall:
-                screen.DrawImage(WallImage, op)
-            default:
-                screen.DrawImage(FloorImage, op)
-            }
-        }
+    cx, cy := (field.Min.X+field.Max.X)/2, (field.Min.Y+field.Max.Y)/2
+    if g.twoPlayer {
+        g.drawTwoPlayerSummary(screen, cx, cy)
+        return
+    }
+    if g.versus != nil {
+        g.drawVersusSummary(screen, cx, cy)
+        return
     }
+    if g.endless != nil {
+        g.drawEndlessSummary(screen, cx, cy)
+        return
+    }
+    if g.ruleset().DrawSummary(g, screen, cx, cy) {
+        return
+    }
+    title := overlayTitle
+    title.Color = color.RGBA{255, 50, 50, 255}
+    DrawText(screen, "GAME OVER", cx, cy-60, title, g.globalTimer)
+    DrawText(screen, fmt.Sprintf("FINAL SCORE %d", g.Player.Score), cx, cy+10, overlayText, g.globalTimer)
+    if g.config != nil && g.Player.Score > 0 && g.Player.Score >= g.config.HighScore {
+        record := overlayText
+        record.Color = color.RGBA{255, 215, 0, 255}
+        record.Pulse = 0.2
+        DrawText(screen, "NEW HIGH SCORE!", cx, cy+40, record, g.globalTimer)
+    }
+    DrawText(screen, "PRESS SPACE TO RETURN TO MENU", cx, cy+80, overlayHint, g.globalTimer)
 }
 
+// Layout keeps the window's own size; Draw scales the logical canvas into it
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-    // Use the modern menu size when in menu state
-    if g.State == StateMenu {
-        return 1200, 800  // Match the modern menu size
-    }
-    width := len(level[0]) * TileSize
-    height := len(level) * TileSize
-    return width, height
+    g.windowSize = image.Pt(outsideWidth, outsideHeight)
+    return outsideWidth, outsideHeight
 }
 
 // Improved collision detection functions
@@ -787,22 +1069,68 @@ func isWallCollidingStrict(level [][]int, px, py float64, size, TileSize int) bo
 
     return false
 }
+
+// tunnelMove handles a box at (px, py) sticking out of the side of the
+// maze. In a tunnel row it may, as long as the tiles it overlaps on either
+// side are open, and once its centre crosses the edge it comes back in on
+// the other side. ok is false when the box is inside the maze or not in a
+// tunnel; gym's world.tunnelMove follows the same rule.
+func tunnelMove(level [][]int, px, py float64, size, TileSize int) (float64, bool) {
+    row := int(py) / TileSize
+    if py < 0 || int(py)%TileSize != 0 || size > TileSize || row >= len(level) || !maze.Tunnel(level[row]) {
+        return px, false
+    }
+    w := len(level[row])
+    width := float64(w * TileSize)
+    if px >= 0 && px+float64(size) <= width {
+        return px, false
+    }
+
+    if center := px + float64(size)/2; center < 0 {
+        px += width
+    } else if center >= width {
+        px -= width
+    }
+    for _, cx := range []float64{px, px + float64(size) - 1} {
+        tx := int(math.Floor(cx / float64(TileSize)))
+        if level[row][(tx%w+w)%w] == TileWall {
+            return px, false
+        }
+    }
+    return px, true
+}
+
+// newCurses makes the four ghosts, in the order the game keeps them
+func newCurses() []*Ghost {
+	return []*Ghost{
+		NewGhost(13*TileSize, 13*TileSize, "ghost.jogo", "jogo", 55),
+    	NewGhost(12*TileSize, 13*TileSize, "ghost.sukuna", "sukuna", 55),    
+    	NewGhost(14*TileSize, 13*TileSize, "ghost.kenjaku", "kenjaku", 55),  
+    	NewGhost(13*TileSize, 14*TileSize, "ghost.mahito", "mahito", 55),
+	}
+}
+
 func (g *Game) resetGhosts() {
+    resetCurses(g.Ghosts, level)
+    g.powerPelletActive = false
+    g.powerPelletTimer = 0
+    g.gameState.FrightModeActive = false
+}
+
+// resetCurses puts the ghosts back in the house of level
+func resetCurses(ghosts []*Ghost, level [][]int) {
     // Use VERIFIED empty tile positions (check your level array first!)
     // These positions should be in the CENTER of empty tiles
-    ghostStartPositions := [][2]float64{
-        // Place ghosts in known empty areas - ADJUST THESE TO YOUR ACTUAL LEVEL
-        {13*TileSize, 13*TileSize}, // jogo - center of ghost house
-        {12*TileSize, 13*TileSize}, // sukuna - left of center
-        {14*TileSize, 13*TileSize}, // kenjaku - right of center
-        {13*TileSize, 14*TileSize}, // mahito - below center
+    // Curses beyond the start tiles share them
+    ghostStartPositions := make([][2]float64, len(ghosts))
+    for i := range ghostStartPositions {
+        t := ghostStartTiles[i%len(ghostStartTiles)]
+        ghostStartPositions[i] = [2]float64{float64(t[0]*TileSize), float64(t[1]*TileSize)}
     }
     
-    fmt.Println("=== RESETTING GHOSTS ===")
-    
     // Verify all positions are actually empty before placing ghosts
     for i, pos := range ghostStartPositions {
-        if i >= len(g.Ghosts) {
+        if i >= len(ghosts) {
             break
         }
         
@@ -810,15 +1138,10 @@ func (g *Game) resetGhosts() {
         tileX := int(pos[0] / TileSize)
         tileY := int(pos[1] / TileSize)
         
-        fmt.Printf("Checking position %d: pixel(%.1f,%.1f) = tile(%d,%d)\n", 
-                   i, pos[0], pos[1], tileX, tileY)
-        
         if tileY >= 0 && tileY < len(level) && tileX >= 0 && tileX < len(level[0]) {
             tileValue := level[tileY][tileX]
-            fmt.Printf("  Tile value: %d\n", tileValue)
-            
             if tileValue == TileWall {
-                fmt.Printf("  ERROR: Position %d is a wall! Finding alternative...\n", i)
+                aiLog.Debug("ghost start is a wall, finding alternative", "index", i, "tile_x", tileX, "tile_y", tileY)
                 // Find the nearest empty tile
                 found := false
                 for radius := 1; radius <= 5 && !found; radius++ {
@@ -832,21 +1155,20 @@ func (g *Game) resetGhosts() {
                                level[checkY][checkX] != TileWall {
                                 ghostStartPositions[i][0] = float64(checkX * TileSize)
                                 ghostStartPositions[i][1] = float64(checkY * TileSize)
-                                fmt.Printf("  Found alternative: tile(%d,%d) = pixel(%.1f,%.1f)\n",
-                                          checkX, checkY, ghostStartPositions[i][0], ghostStartPositions[i][1])
+                                aiLog.Debug("found alternative start", "index", i, "tile_x", checkX, "tile_y", checkY)
                                 found = true
                             }
                         }
                     }
                 }
                 if !found {
-                    fmt.Printf("  WARNING: Could not find safe position for ghost %d!\n", i)
+                    aiLog.Warn("no safe start position for ghost", "index", i)
                 }
             }
         }
         
         // Set ghost position
-        ghost := g.Ghosts[i]
+        ghost := ghosts[i]
         ghost.X = ghostStartPositions[i][0]
         ghost.Y = ghostStartPositions[i][1]
         
@@ -868,48 +1190,50 @@ func (g *Game) resetGhosts() {
             ghost.Direction = "up" // Default direction
         }
         
-        fmt.Printf("Reset ghost %s: pos(%.1f,%.1f), mode=%d, release_timer=%d\n",
-                   ghost.GhostType, ghost.X, ghost.Y, ghost.Mode, ghost.ReleaseTimer)
+        aiLog.Debug("reset ghost", "ghost", ghost.GhostType, "x", ghost.X, "y", ghost.Y,
+                    "mode", ghost.Mode, "release_timer", ghost.ReleaseTimer)
     }
-    
-    g.powerPelletActive = false
-    g.powerPelletTimer = 0
-    g.gameState.FrightModeActive = false
-    fmt.Println("=== RESET COMPLETE ===")
 }
 
 func (g *Game) drawRoundReady(screen *ebiten.Image) {
     // Draw the game field first (dimmed)
-    g.drawGame(screen)
+    g.drawPlayfield(screen)
+    g.drawHUD(screen)
     
     // Dark overlay
-    ebitenutil.DrawRect(screen, 0, 0, float64(len(level[0])*TileSize), float64(len(level)*TileSize), 
+    field := playfieldRect()
+    ebitenutil.DrawRect(screen, float64(field.Min.X), float64(field.Min.Y), float64(field.Dx()), float64(field.Dy()), 
                        color.RGBA{0, 0, 0, 180})
     
     // Pulsing effect
     // pulse := 0.7 + 0.3*math.Sin(float64(g.RoundReadyTimer)*0.15)
     
-    // Round number
-    width := len(level[0]) * TileSize
-    height := len(level) * TileSize
+    // Round number, centered on the playfield
+    width := logicalWidth
+    height := field.Min.Y + field.Max.Y
     
     roundText := fmt.Sprintf("ROUND %d", g.RoundNumber)
-    if bigfont != nil {
-        // Use the existing font system
-        ebitenutil.DebugPrintAt(screen, roundText, width/2-60, height/2-40)
-    } else {
-        ebitenutil.DebugPrintAt(screen, roundText, width/2-60, height/2-40)
+    if g.twoPlayer {
+        DrawText(screen, fmt.Sprintf("PLAYER %d READY", g.currentPlayer+1), width/2, height/2-130, overlayTitle, g.RoundReadyTimer)
+    }
+    if g.endless != nil {
+        g.drawEndlessRoundInfo(screen, width/2, height/2)
     }
+    if g.boss != nil {
+        g.drawBossRoundInfo(screen, width/2, height/2)
+    }
+    DrawText(screen, roundText, width/2, height/2-70, overlayTitle, g.RoundReadyTimer)
     
-    // Ready text
-    // readyAlpha := uint8(pulse * 255)
-    readyText := "READY?"
-    ebitenutil.DebugPrintAt(screen, readyText, width/2-30, height/2)
+    // Ready text fades from white to yellow over the first second
+    ready := overlayText
+    ready.Size = TextLarge
+    ready.Color = lerpColor(color.RGBA{255, 255, 255, 255}, color.RGBA{255, 255, 0, 255}, float64(g.RoundReadyTimer)/60)
+    ready.Pulse = 0.15
+    DrawText(screen, "READY?", width/2, height/2, ready, g.RoundReadyTimer)
     
     // Instructions
     if g.RoundReadyTimer > 60 {
-        instrText := "PRESS SPACE TO BEGIN"
-        ebitenutil.DebugPrintAt(screen, instrText, width/2-80, height/2+40)
+        DrawText(screen, "PRESS SPACE TO BEGIN", width/2, height/2+50, overlayHint, g.RoundReadyTimer)
     }
     
     // Cursed energy effects around the text
@@ -939,21 +1263,21 @@ func (g *Game) drawCursedEnergyEffects(screen *ebiten.Image, centerX, centerY in
 }
 
 func (g *Game) handleSoundControls() {
-    if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+    if Input.JustPressed(ActionMuteMusic) {
         // g.SoundManager.ToggleBGM()
          if g.AudioSystem != nil {
             g.AudioSystem.ToggleBGM()
         }
     }
     
-    if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+    if Input.JustPressed(ActionMuteSFX) {
         // g.SoundManager.ToggleSFX()
          if g.AudioSystem != nil {
             g.AudioSystem.ToggleSFX()
         }
     }
     
-    if inpututil.IsKeyJustPressed(ebiten.KeyEqual) { // Plus key
+    if Input.JustPressed(ActionVolumeUp) {
         // currentVol := g.SoundManager.Volume
         // g.SoundManager.SetVolume(currentVol + 0.1)
         if g.AudioSystem != nil {
@@ -963,7 +1287,7 @@ func (g *Game) handleSoundControls() {
         }
     }
     
-    if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
+    if Input.JustPressed(ActionVolumeDown) {
         // currentVol := g.SoundManager.Volume
         // g.SoundManager.SetVolume(currentVol - 0.1)
         if g.AudioSystem != nil {
@@ -975,7 +1299,7 @@ func (g *Game) handleSoundControls() {
 }
 func (g *Game) updateGameEnhanced() error {
     // Handle pause
-    if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+    if Input.JustPressed(ActionPause) {
         g.State = StatePaused
         g.SoundManager.PlaySFX("pause")
         return nil
@@ -1065,7 +1389,7 @@ func (g *Game) updateGameEnhanced() error {
         g.State = StateRoundReady
         g.ShowRoundReady = true
         g.RoundReadyTimer = 0
-        g.resetGame()
+        g.startNextRound()
         g.SoundManager.PlaySFX("round_complete")
         
         // Increase difficulty slightly each round
@@ -1079,54 +1403,4 @@ func (g *Game) updateGameEnhanced() error {
     return nil
 }
 
-// Enhanced UI drawing with sound visualization
-func (g *Game) drawUIEnhanced(screen *ebiten.Image) {
-    // Score with glow effect for high scores
-    scoreColor := color.RGBA{255, 255, 255, 255}
-    if g.Player.Score > 1000 {
-        pulse := math.Sin(float64(g.globalTimer)*0.2) * 0.3 + 0.7
-        scoreColor = color.RGBA{uint8(255 * pulse), uint8(255 * pulse), 100, 255}
-    }
-    
-    scoreText := fmt.Sprintf("Score: %d", g.Player.Score)
-    ebitenutil.DebugPrintAt(screen, scoreText, 10, 10)
-    
-    // Lives with heart symbols (or curse symbols for JJK theme)
-    livesText := fmt.Sprintf("Lives: %d", g.lives)
-    for i := 0; i < g.lives; i++ {
-        // Draw curse symbol or use text
-        ebitenutil.DrawRect(screen, float64(10+i*20), 30, 15, 15, color.RGBA{200, 50, 50, 255})
-    }
-    ebitenutil.DebugPrintAt(screen, livesText, 10, 50)
-    
-    // Power pellet timer with dramatic countdown
-    if g.powerPelletActive {
-        timeLeft := g.powerPelletTimer / 60
-        timerColor := color.RGBA{255, 255, 100, 255}
-_ = scoreColor
-_ = timerColor
-        
-        // Change color as time runs out
-        if timeLeft <= 2 {
-            pulse := math.Sin(float64(g.globalTimer)*0.5) * 0.5 + 0.5
-            timerColor = color.RGBA{uint8(255 * pulse), 50, 50, 255}
-        }
-        
-        powerText := fmt.Sprintf("CURSED POWER: %ds", timeLeft)
-        ebitenutil.DebugPrintAt(screen, powerText, 10, 70)
-    }
-    
-    // Round number
-    roundText := fmt.Sprintf("Round: %d", g.RoundNumber)
-    ebitenutil.DebugPrintAt(screen, roundText, 10, 90)
-    
-    // Pellets remaining
-    pelletsText := fmt.Sprintf("Pellets: %d", g.pelletCount)
-    ebitenutil.DebugPrintAt(screen, pelletsText, 10, 110)
-    
-    // Sound indicator
-    if g.SoundManager.BGMEnabled {
-        ebitenutil.DebugPrintAt(screen, "♪", len(level[0])*TileSize-30, 10)
-    }
-}
 
@@ -9,6 +9,9 @@ import (
 "github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"fmt"
 	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 
@@ -17,13 +20,28 @@ const (
 	FRIGHT_DURATION = 600 // 10 seconds at 60fps
 	CHASE_DURATION = 1200 // 20 seconds
 	SCATTER_DURATION = 420 // 7 seconds
-	
-	// Ghost house positions
+)
+
+// Ghost house positions, moved by useLevel for custom mazes
+var (
 	GHOST_HOUSE_X = 13
 	GHOST_HOUSE_Y = 13
 	GHOST_HOUSE_EXIT_Y = 11
 )
 
+// simRand drives every random choice the simulation makes. Netplay reseeds
+// it so all peers make the same choices.
+var simRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// rng is the random source for this game state's ghosts. Headless games
+// running side by side each set their own.
+func (gs *GameStateStruct) rng() *rand.Rand {
+	if gs.Rand != nil {
+		return gs.Rand
+	}
+	return simRand
+}
+
 // GhostMode represents the current state of a ghost
 type GhostMode int
 
@@ -70,6 +88,9 @@ type Ghost struct {
 	
 	// Additional production features
 	LastPosition     [2]int        // For stuck detection
+	Controller       GhostController // Steers the ghost instead of the AI when set
+	Chase            ChaseStrategy // Picks the chase target instead of updateChaseTarget when set
+	SpeedFactor      float64       // Scales the ghost's speed; 0 means 1
 	// drawDebugInfo    bool          // Debug visualization toggle
 	// soundEnabled     bool          // Audio trigger toggle
 	//networkSync      bool          // Network synchronization flag
@@ -77,12 +98,39 @@ type Ghost struct {
 }
 
 
+// GhostWave is one stretch of the scatter/chase pattern
+type GhostWave struct {
+	Frames int       `json:"frames"` // -1 lasts for the rest of the game
+	Mode   GhostMode `json:"mode"`
+}
+
+// Classic Pacman wave pattern
+var classicWaves = []GhostWave{
+	{420, ScatterMode},   // 7 seconds scatter
+	{1200, ChaseMode},    // 20 seconds chase
+	{420, ScatterMode},   // 7 seconds scatter
+	{1200, ChaseMode},    // 20 seconds chase
+	{300, ScatterMode},   // 5 seconds scatter
+	{1200, ChaseMode},    // 20 seconds chase
+	{300, ScatterMode},   // 5 seconds scatter
+	{-1, ChaseMode},      // Indefinite chase
+}
+
 // Ghost manager for coordinated AI behavior
 type GhostManager struct {
 	ghosts []*Ghost
+	LastCollider *Ghost // ghost behind the last collision CheckCollisions reported
 	gameState *GameStateStruct
 	globalModeTimer int
 	waveNumber int // For scatter/chase wave patterns
+	Waves []GhostWave // nil uses classicWaves
+}
+
+func (gm *GhostManager) waves() []GhostWave {
+	if gm.Waves != nil {
+		return gm.Waves
+	}
+	return classicWaves
 }
 
 //NewGhostManager creates a coordinated ghost management system
@@ -95,6 +143,24 @@ func NewGhostManager(gameState *GameStateStruct) *GhostManager {
 	}
 }
 
+// SetWaves switches to a scatter/chase pattern, nil for the classic one,
+// starting from its first wave
+func (gm *GhostManager) SetWaves(waves []GhostWave) {
+	gm.Waves = waves
+	gm.waveNumber = 0
+	gm.globalModeTimer = 0
+}
+
+// RemoveGhost stops managing ghost
+func (gm *GhostManager) RemoveGhost(ghost *Ghost) {
+	for i, gh := range gm.ghosts {
+		if gh == ghost {
+			gm.ghosts = append(gm.ghosts[:i], gm.ghosts[i+1:]...)
+			return
+		}
+	}
+}
+
 // AddGhost adds a ghost to the manager
 func (gm *GhostManager) AddGhost(ghost *Ghost) {
 	gm.ghosts = append(gm.ghosts, ghost)
@@ -113,24 +179,10 @@ func (gm *GhostManager) UpdateAll() {
 
 // Handle global mode changes (scatter/chase waves)
 func (gm *GhostManager) handleGlobalModeChanges() {
-	// Classic Pacman wave pattern
-	wavePattern := []struct{
-		duration int
-		mode GhostMode
-	}{
-		{420, ScatterMode},   // 7 seconds scatter
-		{1200, ChaseMode},    // 20 seconds chase
-		{420, ScatterMode},   // 7 seconds scatter
-		{1200, ChaseMode},    // 20 seconds chase
-		{300, ScatterMode},   // 5 seconds scatter
-		{1200, ChaseMode},    // 20 seconds chase
-		{300, ScatterMode},   // 5 seconds scatter
-		{-1, ChaseMode},      // Indefinite chase
-	}
-	
+	wavePattern := gm.waves()
 	if gm.waveNumber < len(wavePattern) {
 		wave := wavePattern[gm.waveNumber]
-		if wave.duration > 0 && gm.globalModeTimer >= wave.duration {
+		if wave.Frames > 0 && gm.globalModeTimer >= wave.Frames {
 			gm.advanceWave()
 		}
 	}
@@ -141,19 +193,17 @@ func (gm *GhostManager) advanceWave() {
 	gm.globalModeTimer = 0
 	
 	// Update all ghosts to new mode
+	wave := GhostWave{-1, ChaseMode} // Indefinite once the pattern runs out
+	if wavePattern := gm.waves(); gm.waveNumber < len(wavePattern) {
+		wave = wavePattern[gm.waveNumber]
+	}
 for _, ghost := range gm.ghosts {
 		if ghost.Mode != FrightenedMode && ghost.Mode != DeadMode && ghost.Mode != InHouseMode {
-			if gm.waveNumber < 8 {
-				if gm.waveNumber%2 == 0 {
-					ghost.Mode = ScatterMode
-					ghost.ScatterTimer = 420
-				} else {
-					ghost.Mode = ChaseMode
-					ghost.ChaseTimer = 1200
-				}
+			ghost.Mode = wave.Mode
+			if wave.Mode == ScatterMode {
+				ghost.ScatterTimer = wave.Frames
 			} else {
-				ghost.Mode = ChaseMode
-				ghost.ChaseTimer = -1 // Indefinite
+				ghost.ChaseTimer = wave.Frames
 			}
 			
 			// Force direction reversal on mode change (except first wave)
@@ -177,6 +227,7 @@ func (gm *GhostManager) CheckCollisions(playerX, playerY float64) string {
 	for _, ghost := range gm.ghosts {
 		result := ghost.CollideWithPlayer(playerX, playerY)
 		if result != "no_collision" {
+			gm.LastCollider = ghost
 			return result
 		}
 	}
@@ -184,10 +235,11 @@ func (gm *GhostManager) CheckCollisions(playerX, playerY float64) string {
 }
 
 // NewGhost creates a new ghost with advanced AI capabilities
-func NewGhost(x, y float64, imagePath, ghostType string, size int) *Ghost {
-	// Load image (you'll need to implement this based on your image loading system)
-	var image *ebiten.Image
-    image = loadImage(imagePath) // Implement this function
+func NewGhost(x, y float64, imageName, ghostType string, size int) *Ghost {
+	var image *ebiten.Image // headless games run without assets
+	if Assets != nil {
+		image = Assets.Image(imageName)
+	}
 	
 	ghost := &Ghost{
 		X:               x,
@@ -225,18 +277,14 @@ func NewGhost(x, y float64, imagePath, ghostType string, size int) *Ghost {
 		ghost.ReleaseTimer = 900
 		//ghost.Mode=ChaseMode
 	}
-	fmt.Printf("Created ghost %s at (%.1f, %.1f) with speed %.2f\n", 
-		ghostType, x, y, ghost.Speed)
+	aiLog.Debug("created ghost", "ghost", ghostType, "x", x, "y", y, "speed", ghost.Speed)
 		return ghost
 }
 
 // Quick test function to verify tile constants
 func TestTileConstants() {
-	fmt.Println("Checking tile constants:")
-	fmt.Printf("TileEmpty = %d\n", TileEmpty)
-	fmt.Printf("TileWall = %d\n", TileWall)
-	fmt.Printf("TilePellet = %d\n", TilePellet)
-	fmt.Printf("TilePowerPellet = %d\n", TilePowerPellet)
+	aiLog.Debug("tile constants", "empty", TileEmpty, "wall", TileWall,
+		"pellet", TilePellet, "power_pellet", TilePowerPellet)
 }
 
 // Update handles the main ghost AI logic
@@ -246,15 +294,15 @@ func (g *Ghost) Update(gameState *GameStateStruct) {
 	g.LastUpdate = now
 
 
-// Debug output (remove this in production)
-	if g.PersonalityMode%60 == 0 { // Print every second
-		pacmanTileX := int(gameState.PacmanX / TileSize)
-		pacmanTileY := int(gameState.PacmanY / TileSize)
-		ghostTileX := int(g.X / TileSize)
-		ghostTileY := int(g.Y / TileSize)
-		
-		fmt.Printf("Ghost %s: Mode=%d, Pos=(%d,%d), Target=(%d,%d), Pacman=(%d,%d)\n",
-			g.GhostType, g.Mode, ghostTileX, ghostTileY, g.TargetX, g.TargetY, pacmanTileX, pacmanTileY)
+	// Once a second, when the ai category logs debug
+	if g.PersonalityMode%60 == 0 && debugEnabled(aiLog) {
+		aiLog.Debug("ghost",
+			"ghost", g.GhostType, "mode", g.Mode,
+			"x", g.X, "y", g.Y, "tile_x", int(g.X/TileSize), "tile_y", int(g.Y/TileSize),
+			"speed", g.Speed, "direction", g.Direction,
+			"target_x", g.TargetX, "target_y", g.TargetY,
+			"pacman_tile_x", int(gameState.PacmanX/TileSize), "pacman_tile_y", int(gameState.PacmanY/TileSize),
+			"surroundings", g.localArea(gameState, 1))
 	}
 
 	// Performance optimization - only update AI logic periodically
@@ -262,40 +310,13 @@ func (g *Ghost) Update(gameState *GameStateStruct) {
 	// 	return
 	// }
 
-
-	if g.PersonalityMode%60 == 0 {
-		fmt.Printf("=== GHOST DEBUG %s ===\n", g.GhostType)
-		fmt.Printf("Position: (%.1f, %.1f) -> Tile: (%d, %d)\n", 
-			g.X, g.Y, int(g.X/TileSize), int(g.Y/TileSize))
-		fmt.Printf("Speed: %.2f, Direction: %s\n", g.Speed, g.Direction)
-		fmt.Printf("Mode: %d, Target: (%d, %d)\n", g.Mode, g.TargetX, g.TargetY)
-		fmt.Printf("Pacman: (%.1f, %.1f) -> Tile: (%d, %d)\n", 
-			gameState.PacmanX, gameState.PacmanY, 
-			int(gameState.PacmanX/TileSize), int(gameState.PacmanY/TileSize))
-		
-		// Check what's around the ghost
-		currentTileX := int(g.X / TileSize)
-		currentTileY := int(g.Y / TileSize)
-		fmt.Printf("Surrounding tiles:\n")
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				tx := currentTileX + dx
-				ty := currentTileY + dy
-				if ty >= 0 && ty < len(gameState.Level) && tx >= 0 && tx < len(gameState.Level[0]) {
-					fmt.Printf("%d ", gameState.Level[ty][tx])
-				} else {
-					fmt.Printf("X ")
-				}
-			}
-			fmt.Printf("\n")
-		}
-		fmt.Printf("==================\n")
-	}
 	// Update timers
 	g.updateTimers()
 	
 	// Handle stuck state detection and recovery
-	g.handleStuckState(gameState)
+	if !g.isControlled() {
+		g.handleStuckState(gameState)
+	}
 	
 	// Update difficulty scaling
 	g.updateDifficultyScaling(gameState)
@@ -303,13 +324,21 @@ func (g *Ghost) Update(gameState *GameStateStruct) {
 	// Update mode based on timers and game state
 	g.updateMode(gameState)
 	
+	// Controlled ghosts skip the targeting AI but keep timers, modes and speed
+	if g.isControlled() {
+		g.handleTunnels(gameState)
+		g.Controller.Steer(g, gameState)
+		g.PersonalityMode++
+		return
+	}
+	
 	// Update target based on current mode and ghost type
 	g.UpdateTarget(gameState)
 
 		
 	// Test movement in all directions to see what's valid
-	if g.PersonalityMode%120 == 0 { // Every 2 seconds
-		fmt.Printf("Movement test for %s:\n", g.GhostType)
+	if g.PersonalityMode%120 == 0 && debugEnabled(aiLog) { // Every 2 seconds
+		var open []string
 		directions := []string{"up", "down", "left", "right"}
 		for _, dir := range directions {
 			testX, testY := g.X, g.Y
@@ -324,9 +353,11 @@ func (g *Ghost) Update(gameState *GameStateStruct) {
 				testX += g.Speed
 			}
 			
-			valid := g.isValidPosition(gameState, testX, testY)
-			fmt.Printf("  %s: (%.1f,%.1f) -> %v\n", dir, testX, testY, valid)
+			if g.isValidPosition(gameState, testX, testY) {
+				open = append(open, dir)
+			}
 		}
+		aiLog.Debug("movement test", "ghost", g.GhostType, "open", open)
 	}
 
 	// Handle screen wrapping (tunnels)
@@ -352,8 +383,10 @@ func (g *Ghost) moveToTargetSimple(gameState *GameStateStruct) {
 	currentTileX := int((g.X + float64(g.Size)/2) / TileSize)
 	currentTileY := int((g.Y + float64(g.Size)/2) / TileSize)
 	
-	fmt.Printf("Ghost %s at tile (%d,%d) targeting (%d,%d)\n", 
-		g.GhostType, currentTileX, currentTileY, g.TargetX, g.TargetY)
+	if debugEnabled(aiLog) {
+		aiLog.Debug("simple move", "ghost", g.GhostType, "tile_x", currentTileX, "tile_y", currentTileY,
+			"target_x", g.TargetX, "target_y", g.TargetY)
+	}
 	
 	// Choose direction
 	dx := g.TargetX - currentTileX
@@ -393,32 +426,39 @@ func (g *Ghost) moveToTargetSimple(gameState *GameStateStruct) {
 	// Bounds check
 	if tileY < 0 || tileY >= len(gameState.Level) || 
 		tileX < 0 || tileX >= len(gameState.Level[0]) {
-		fmt.Printf("  Move blocked: out of bounds (%d,%d)\n", tileX, tileY)
+		if debugEnabled(aiLog) {
+			aiLog.Debug("move blocked: out of bounds", "ghost", g.GhostType, "tile_x", tileX, "tile_y", tileY)
+		}
 		return
 	}
 	
 	// Wall check
 	if gameState.Level[tileY][tileX] == TileWall {
-		fmt.Printf("  Move blocked: wall at (%d,%d) = %d\n", 
-			tileX, tileY, gameState.Level[tileY][tileX])
+		if debugEnabled(aiLog) {
+			aiLog.Debug("move blocked: wall", "ghost", g.GhostType, "tile_x", tileX, "tile_y", tileY)
+		}
 		return
 	}
 	
 	// Move is valid
 	g.X = newX
 	g.Y = newY
-	fmt.Printf("  Moved to (%.1f,%.1f)\n", g.X, g.Y)
+	if debugEnabled(aiLog) {
+		aiLog.Debug("moved", "ghost", g.GhostType, "x", g.X, "y", g.Y)
+	}
 }
 // Check what your tile constants are
 func (g *Ghost) debugTileConstants(gameState *GameStateStruct) {
-	// Print some sample tiles to see what values you're using
-	fmt.Println("Sample tile values:")
+	// Log some sample tiles to see what values you're using
+	var rows []string
 	for y := 0; y < min(5, len(gameState.Level)); y++ {
+		row := ""
 		for x := 0; x < min(10, len(gameState.Level[0])); x++ {
-			fmt.Printf("%d ", gameState.Level[y][x])
+			row += fmt.Sprintf("%d ", gameState.Level[y][x])
 		}
-		fmt.Println()
+		rows = append(rows, row)
 	}
+	aiLog.Debug("sample tile values", "rows", rows)
 }
 // Helper function for Go versions that don't have min
 func min(a, b int) int {
@@ -430,9 +470,9 @@ func min(a, b int) int {
 
 func (g *Ghost) moveToTarget(gameState *GameStateStruct) {
     // Debug info
-    if g.PersonalityMode%120 == 0 {
-        fmt.Printf("Moving %s: pos(%.1f,%.1f) target(%d,%d) mode=%d\n",
-                   g.GhostType, g.X, g.Y, g.TargetX, g.TargetY, g.Mode)
+    if g.PersonalityMode%120 == 0 && debugEnabled(aiLog) {
+        aiLog.Debug("moving", "ghost", g.GhostType, "x", g.X, "y", g.Y,
+                    "target_x", g.TargetX, "target_y", g.TargetY, "mode", g.Mode)
     }
     
     targetPixelX := float64(g.TargetX * TileSize)
@@ -532,12 +572,14 @@ func (g *Ghost) moveToTarget(gameState *GameStateStruct) {
             g.X = newX
             g.Y = newY
             g.Direction = dir.name
-            fmt.Printf("Ghost %s unstuck with micro-movement %s\n", g.GhostType, dir.name)
+            aiLog.Debug("unstuck with micro-movement", "ghost", g.GhostType, "direction", dir.name)
             return
         }
     }
     
-    fmt.Printf("Ghost %s completely stuck at (%.1f,%.1f)!\n", g.GhostType, g.X, g.Y)
+    if debugEnabled(aiLog) {
+        aiLog.Debug("completely stuck", "ghost", g.GhostType, "x", g.X, "y", g.Y)
+    }
 }
 
 func (g *Ghost) UpdateTarget(gameState *GameStateStruct) {
@@ -545,7 +587,11 @@ func (g *Ghost) UpdateTarget(gameState *GameStateStruct) {
     
     switch g.Mode {
     case ChaseMode:
-        g.updateChaseTarget(gameState)
+        if g.Chase != nil {
+            g.Chase(g, gameState)
+        } else {
+            g.updateChaseTarget(gameState)
+        }
     case ScatterMode:
         g.TargetX = g.ScatterTarget[0]
         g.TargetY = g.ScatterTarget[1]
@@ -570,9 +616,9 @@ func (g *Ghost) UpdateTarget(gameState *GameStateStruct) {
     g.clampTarget(gameState)
     
     // Debug: Log target changes
-    if (g.TargetX != oldTargetX || g.TargetY != oldTargetY) && g.PersonalityMode%60 == 0 {
-        fmt.Printf("Ghost %s target changed: (%d,%d) -> (%d,%d) [mode=%d]\n",
-                   g.GhostType, oldTargetX, oldTargetY, g.TargetX, g.TargetY, g.Mode)
+    if (g.TargetX != oldTargetX || g.TargetY != oldTargetY) && g.PersonalityMode%60 == 0 && debugEnabled(aiLog) {
+        aiLog.Debug("target changed", "ghost", g.GhostType, "from_x", oldTargetX, "from_y", oldTargetY,
+                    "target_x", g.TargetX, "target_y", g.TargetY, "mode", g.Mode)
     }
 }
 
@@ -674,8 +720,6 @@ func (g *Ghost) updateChaseTarget(gameState *GameStateStruct) {
 		// Direct chase
 		g.TargetX = pacmanTileX
 		g.TargetY = pacmanTileY
-		fmt.Printf("Ghost jogo chase mode: target=(%d,%d) pacman=(%d,%d)\n", 
-			g.TargetX, g.TargetY, pacmanTileX, pacmanTileY)
 		
 	case "sukuna": 
 		// 2 tiles ahead (simplified)
@@ -733,7 +777,7 @@ func (g *Ghost) updateFrightenedTarget(gameState *GameStateStruct) {
 	}
 	
 	if len(validTargets) > 0 {
-		target := validTargets[rand.Intn(len(validTargets))]
+		target := validTargets[gameState.rng().Intn(len(validTargets))]
 		g.TargetX = target.X
 		g.TargetY = target.Y
 	}
@@ -962,6 +1006,22 @@ func (g *Ghost) followPath(gameState *GameStateStruct) {
 	}
 }
 
+// SetScatterCorner points the ghost's scatter target at its corner of level,
+// where the classic maze has them
+func (g *Ghost) SetScatterCorner(level [][]int) {
+	w, h := len(level[0]), len(level)
+	switch g.GhostType {
+	case "jogo":
+		g.ScatterTarget = [2]int{w - 2, 0} // Top-right
+	case "sukuna":
+		g.ScatterTarget = [2]int{2, 0} // Top-left
+	case "kenjaku":
+		g.ScatterTarget = [2]int{w - 2, h} // Bottom-right
+	case "mahito":
+		g.ScatterTarget = [2]int{2, h} // Bottom-left
+	}
+}
+
 // SetFrightened activates frightened mode
 func (g *Ghost) SetFrightened(duration int) {
 	if g.Mode != DeadMode && g.Mode != InHouseMode {
@@ -1000,36 +1060,70 @@ func (g *Ghost) ResetMode() {
 	g.FrightTimer = 0
 	g.CruiseElroyMode = 0
 }
+// SetMode puts the ghost in mode the way the game would: frightened as by a
+// power pellet, dead as if eaten, or back in the house with a short wait
+func (g *Ghost) SetMode(mode GhostMode) {
+	switch mode {
+	case FrightenedMode:
+		g.SetFrightened(FRIGHT_DURATION)
+		return
+	case DeadMode:
+		g.Speed = g.BaseSpeed * 2
+		g.Visible = false
+	case InHouseMode:
+		g.ReleaseTimer = 300
+	case ChaseMode:
+		g.ChaseTimer = 1200
+	case ScatterMode:
+		g.ScatterTimer = 420
+	}
+	if mode != DeadMode {
+		g.Speed = g.BaseSpeed
+		g.Visible = true
+	}
+	g.FrightTimer = 0
+	g.Mode = mode
+}
+
 // CollideWithPlayer handles collision with player
+// Half the player's size; CollideWithPlayer treats the player as a circle
+// this wide around (playerX+16, playerY+16)
+const playerCollisionRadius = 16
+
+func (g *Ghost) collisionRadius() float64 {
+	return float64(g.Size) / 2
+}
+
 func (g *Ghost) CollideWithPlayer(playerX, playerY float64) string {
 	// Calculate distance between centers
 	ghostCenterX := g.X + float64(g.Size)/2
 	ghostCenterY := g.Y + float64(g.Size)/2
 	
 	// Assume player is also centered (adjust if needed)
-	playerCenterX := playerX + 16 // Assuming player size ~32, so center at +16
-	playerCenterY := playerY + 16
+	playerCenterX := playerX + playerCollisionRadius
+	playerCenterY := playerY + playerCollisionRadius
 	
 	distance := math.Sqrt(math.Pow(ghostCenterX-playerCenterX, 2) + 
 						 math.Pow(ghostCenterY-playerCenterY, 2))
 	
 	// Collision threshold based on combined sizes
-	threshold := float64(g.Size)/2 + 16 // Half ghost size + half player size
+	threshold := g.collisionRadius() + playerCollisionRadius
 	
 	if distance < threshold {
-    	fmt.Printf("Ghost %s collision! Mode: %d, Distance: %.2f, Threshold: %.2f\n", 
-                   g.GhostType, g.Mode, distance, threshold)
+    	if debugEnabled(aiLog) {
+    		aiLog.Debug("collision", "ghost", g.GhostType, "mode", g.Mode, "distance", distance, "threshold", threshold)
+    	}
 		switch g.Mode {
 		case FrightenedMode:
 			if g.CanBeEaten() {
 				g.Mode = DeadMode
 				g.Speed = g.BaseSpeed * 2
 				g.Visible = false // Hide dead ghost temporarily
-                fmt.Printf("Ghost %s eaten!\n", g.GhostType)
+                aiLog.Debug("ghost eaten", "ghost", g.GhostType)
 				return "ghost_eaten"
 			}
 		case ChaseMode, ScatterMode:
-    		fmt.Printf("Player caught by ghost %s!\n", g.GhostType)
+    		aiLog.Debug("player caught", "ghost", g.GhostType)
 			return "player_caught"
 		}
 	}
@@ -1109,22 +1203,28 @@ func (g *Ghost) Draw(screen *ebiten.Image) {
 }
 
 func (g *Ghost) debugSurroundingTiles(gameState *GameStateStruct) {
+    if debugEnabled(aiLog) {
+        aiLog.Debug("tile debug", "ghost", g.GhostType, "x", g.X, "y", g.Y,
+                    "tiles", g.localArea(gameState, 1))
+    }
+}
+
+// localArea draws the tiles within radius of the ghost, one row per line:
+// # wall, . empty, o pellet, O power pellet, X outside the maze, with the
+// ghost's own tile in brackets
+func (g *Ghost) localArea(gameState *GameStateStruct, radius int) string {
     currentTileX := int(g.X / TileSize)
     currentTileY := int(g.Y / TileSize)
-    
-    fmt.Printf("=== TILE DEBUG for %s at (%.1f, %.1f) = tile (%d, %d) ===\n", 
-               g.GhostType, g.X, g.Y, currentTileX, currentTileY)
-    
-    // Check 3x3 area around ghost
-    for dy := -1; dy <= 1; dy++ {
-        for dx := -1; dx <= 1; dx++ {
+
+    var b strings.Builder
+    for dy := -radius; dy <= radius; dy++ {
+        b.WriteByte('\n')
+        for dx := -radius; dx <= radius; dx++ {
             tx := currentTileX + dx
             ty := currentTileY + dy
-            
+            symbol := "X"
             if ty >= 0 && ty < len(gameState.Level) && tx >= 0 && tx < len(gameState.Level[0]) {
-                tileType := gameState.Level[ty][tx]
-                symbol := "?"
-                switch tileType {
+                switch gameState.Level[ty][tx] {
                 case TileEmpty:
                     symbol = "."
                 case TileWall:
@@ -1133,16 +1233,18 @@ func (g *Ghost) debugSurroundingTiles(gameState *GameStateStruct) {
                     symbol = "o"
                 case TilePowerPellet:
                     symbol = "O"
+                default:
+                    symbol = "?"
                 }
-                fmt.Printf("%s ", symbol)
+            }
+            if dx == 0 && dy == 0 {
+                b.WriteString("[" + symbol + "]")
             } else {
-                fmt.Printf("X ")
+                b.WriteString(" " + symbol + " ")
             }
         }
-        fmt.Printf("\n")
     }
-    fmt.Printf("Current tile type: %d\n", gameState.Level[currentTileY][currentTileX])
-    fmt.Printf("===================\n")
+    return b.String()
 }
 
 // Tunnel handling for screen wrapping
@@ -1211,7 +1313,7 @@ func (g *Ghost) handleStuckState(gameState *GameStateStruct) {
 		
 		// Emergency random direction
 		directions := []string{"up", "down", "left", "right"}
-		g.Direction = directions[rand.Intn(len(directions))]
+		g.Direction = directions[gameState.rng().Intn(len(directions))]
 	}
 }
 
@@ -1221,35 +1323,108 @@ func (g *Ghost) shouldUpdateAI() bool {
 	return true// Update every 4 frames
 }
 
+// Difficulty replaces the per-level scaling of ghost speed and fright time,
+// for modes that ramp up on their own terms
+type Difficulty struct {
+	SpeedMultiplier float64 // of the base speed of 0.8
+	MaxFright       int     // frames a ghost stays frightened at most
+}
+
 // Advanced difficulty scaling
 func (g *Ghost) updateDifficultyScaling(gameState *GameStateStruct) {
 	baseSpeed := 0.8
+	if g.SpeedFactor > 0 {
+		baseSpeed *= g.SpeedFactor
+	}
 	
 	// Increase speed and reduce scatter time as level increases
 	speedMultiplier := 1.0 + (float64(gameState.CurrentLevel-1) * 0.1)
-	g.BaseSpeed = baseSpeed * speedMultiplier
-	
-	if g.Mode != FrightenedMode {
-		g.Speed = g.BaseSpeed
-	}
-	
 	// Reduce fright mode duration on higher levels
 	maxFrightTime := 600 - (gameState.CurrentLevel-1)*30
 	if maxFrightTime < 120 {
 		maxFrightTime = 120
 	}
+	if d := gameState.Difficulty; d != nil {
+		speedMultiplier, maxFrightTime = d.SpeedMultiplier, d.MaxFright
+	}
+
+	g.BaseSpeed = baseSpeed * speedMultiplier
+	if g.Mode != FrightenedMode {
+		g.Speed = g.BaseSpeed
+	}
 	
 	if g.Mode == FrightenedMode && g.FrightTimer > maxFrightTime {
 		g.FrightTimer = maxFrightTime
 	}
 }
 
-// Debug visualization for AI development
-func (g *Ghost) drawAIDebug(screen *ebiten.Image) {
-	// Draw target position
-	// Draw current path
-	// Draw AI state information
-	// This would use your text/debug rendering system
+// Debug visualization for AI development: the scatter corner, the route to
+// the target (g.Path when the ghost follows one, otherwise the A* route),
+// the target tile, the collision circle, the isValidPosition probes one step
+// ahead and a label with the mode and timers. Drawn in maze pixels.
+func (g *Ghost) drawAIDebug(screen *ebiten.Image, gameState *GameStateStruct, clr color.RGBA) {
+	faint := clr
+	faint.A = 90
+
+	// Scatter corner, clamped so corners outside the maze still show
+	sx, sy := clampTile(gameState.Level, g.ScatterTarget[0], g.ScatterTarget[1])
+	vector.StrokeRect(screen, float32(sx*TileSize)+4, float32(sy*TileSize)+4, TileSize-8, TileSize-8, 1, faint, false)
+	ebitenutil.DebugPrintAt(screen, "S", sx*TileSize+12, sy*TileSize+8)
+
+	// Route to the target
+	path := g.Path
+	if len(path) == 0 && g.Mode != InHouseMode {
+		path = findPath(gameState.Level, int(g.X/TileSize), int(g.Y/TileSize), g.TargetX, g.TargetY)
+	}
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		vector.StrokeLine(screen,
+			float32(a.X*TileSize+TileSize/2), float32(a.Y*TileSize+TileSize/2),
+			float32(b.X*TileSize+TileSize/2), float32(b.Y*TileSize+TileSize/2),
+			2, faint, false)
+	}
+
+	// Target tile
+	vector.StrokeRect(screen, float32(g.TargetX*TileSize)+1, float32(g.TargetY*TileSize)+1, TileSize-2, TileSize-2, 2, clr, false)
+	vector.StrokeLine(screen, float32(g.TargetX*TileSize), float32(g.TargetY*TileSize),
+		float32(g.TargetX*TileSize+TileSize), float32(g.TargetY*TileSize+TileSize), 1, clr, false)
+
+	// Collision circle as CollideWithPlayer sees it
+	cx, cy := float32(g.X)+float32(g.Size)/2, float32(g.Y)+float32(g.Size)/2
+	vector.StrokeCircle(screen, cx, cy, float32(g.collisionRadius()), 1, clr, false)
+
+	// Probe points for the next step in the current direction
+	nx, ny := g.X, g.Y
+	switch g.Direction {
+	case "up":
+		ny -= g.Speed
+	case "down":
+		ny += g.Speed
+	case "left":
+		nx -= g.Speed
+	case "right":
+		nx += g.Speed
+	}
+	for _, p := range g.probePoints(nx, ny) {
+		probe := color.RGBA{0, 255, 0, 255}
+		tx, ty := int(p[0]/TileSize), int(p[1]/TileSize)
+		if ty < 0 || ty >= len(gameState.Level) || tx < 0 || tx >= len(gameState.Level[0]) || gameState.Level[ty][tx] == TileWall {
+			probe = color.RGBA{255, 0, 0, 255}
+		}
+		vector.DrawFilledRect(screen, float32(p[0])-1.5, float32(p[1])-1.5, 3, 3, probe, false)
+	}
+
+	// Mode and timers
+	label := fmt.Sprintf("%s %s\nF%d S%d C%d R%d", g.GhostType, g.Mode,
+		g.FrightTimer, g.ScatterTimer, g.ChaseTimer, g.ReleaseTimer)
+	ebitenutil.DebugPrintAt(screen, label, int(g.X)-8, int(g.Y)-34)
+}
+
+// clampTile pulls a tile position into the level's bounds
+func clampTile(level [][]int, x, y int) (int, int) {
+	x = max(0, min(x, len(level[0])-1))
+	y = max(0, min(y, len(level)-1))
+	return x, y
 }
 
 // Sound effect triggers
@@ -1288,20 +1463,28 @@ func (g *Ghost) LoadState(state map[string]interface{}) {
 	// ... load other state variables
 }
 
-// Network synchronization for multiplayer
+// Network synchronization for multiplayer, in the snapshot encoding (see snapshot.go)
 func (g *Ghost) GetNetworkState() []byte {
-	// Serialize minimal state for network transmission
-	// Return compressed ghost state
-	return nil
+	s := g.State()
+	return appendGhost([]byte{snapshotVersion}, &GhostState{}, &s)
 }
 
-func (g *Ghost) SetNetworkState(data []byte) {
-	// Deserialize and apply network state
+func (g *Ghost) SetNetworkState(data []byte) error {
+	r := &snapReader{data: data}
+	if r.byte() != snapshotVersion {
+		return errSnapshotVersion
+	}
+	var s GhostState
+	r.ghost(nil, &s)
+	if r.err != nil {
+		return r.err
+	}
+	g.SetState(s)
+	return nil
 }
 
 // Add these fields to Ghost struct
 var lastPosition [2]int
-var drawDebugInfo bool
 
 // Helper functions
 func (g *Ghost) updateTimers() {
@@ -1337,7 +1520,7 @@ func (g *Ghost) updateMode(gameState *GameStateStruct) {
             g.Mode = ScatterMode
             g.ScatterTimer = 420 // 7 seconds
             if oldMode != g.Mode {
-                fmt.Printf("Ghost %s released from house, entering scatter mode\n", g.GhostType)
+                aiLog.Debug("released from house", "ghost", g.GhostType)
             }
         } else {
             // Still waiting to be released
@@ -1352,14 +1535,14 @@ func (g *Ghost) updateMode(gameState *GameStateStruct) {
         g.ScatterTimer = 420
         g.Speed = g.BaseSpeed
         if oldMode != g.Mode {
-            fmt.Printf("Ghost %s exiting fright mode, entering scatter\n", g.GhostType)
+            aiLog.Debug("fright over, scattering", "ghost", g.GhostType)
         }
     }
      if g.ChaseTimer <= 0 && g.Mode == ChaseMode {
         g.Mode = ScatterMode
         g.ScatterTimer = 420
         if oldMode != g.Mode {
-            fmt.Printf("Ghost %s switching to scatter mode\n", g.GhostType)
+            aiLog.Debug("switching to scatter", "ghost", g.GhostType)
         }
     }
     
@@ -1367,22 +1550,28 @@ func (g *Ghost) updateMode(gameState *GameStateStruct) {
         g.Mode = ChaseMode
         g.ChaseTimer = 1200
         if oldMode != g.Mode {
-            fmt.Printf("Ghost %s switching to chase mode\n", g.GhostType)
+            aiLog.Debug("switching to chase", "ghost", g.GhostType)
         }
     }
 }
 
-func (g *Ghost) isValidPosition(gameState *GameStateStruct, pixelX, pixelY float64) bool {
-    // Check all four corners of the ghost, not just center
+// probePoints are the pixels isValidPosition looks at for a ghost at
+// (pixelX, pixelY): the centre first, then the four corners
+func (g *Ghost) probePoints(pixelX, pixelY float64) [5][2]float64 {
     margin := 1.0 // Small margin to prevent wall clipping
     size:=float64(g.Size)
-    checkPoints := [][2]float64{
+    return [5][2]float64{
         {pixelX + size/2, pixelY + size/2},           // Center (most important)
         {pixelX + margin, pixelY + margin},           // Top-left
         {pixelX + size - margin, pixelY + margin},    // Top-right
         {pixelX + margin, pixelY + size - margin},    // Bottom-left
         {pixelX + size - margin, pixelY + size - margin}, // Bottom-right
     }
+}
+
+func (g *Ghost) isValidPosition(gameState *GameStateStruct, pixelX, pixelY float64) bool {
+    // Check all four corners of the ghost, not just center
+    checkPoints := g.probePoints(pixelX, pixelY)
     
     for i, point := range checkPoints {
         tileX := int(point[0] / TileSize)
@@ -1542,37 +1731,7 @@ func (g *Ghost) getDistance(targetX, targetY float64) float64 {
 }
 
 func (g *Ghost) printLocalArea(gameState *GameStateStruct) {
-    currentTileX := int(g.X / TileSize)
-    currentTileY := int(g.Y / TileSize)
-    
-    fmt.Printf("=== AREA AROUND %s at (%d,%d) ===\n", g.GhostType, currentTileX, currentTileY)
-    
-    for dy := -3; dy <= 3; dy++ {
-        for dx := -3; dx <= 3; dx++ {
-            tx := currentTileX + dx
-            ty := currentTileY + dy
-            
-            if ty >= 0 && ty < len(gameState.Level) && tx >= 0 && tx < len(gameState.Level[0]) {
-                tile := gameState.Level[ty][tx]
-                switch {
-                case dx == 0 && dy == 0:
-                    fmt.Printf("[%d]", tile) // Ghost position
-                case tile == TileWall:
-                    fmt.Printf(" # ")
-                case tile == TileEmpty:
-                    fmt.Printf(" . ")
-                case tile == TilePellet:
-                    fmt.Printf(" o ")
-                case tile == TilePowerPellet:
-                    fmt.Printf(" O ")
-                default:
-                    fmt.Printf(" ? ")
-                }
-            } else {
-                fmt.Printf(" X ") // Out of bounds
-            }
-        }
-        fmt.Printf("\n")
+    if debugEnabled(aiLog) {
+        aiLog.Debug("area", "ghost", g.GhostType, "tiles", g.localArea(gameState, 3))
     }
-    fmt.Printf("===============================\n")
 }
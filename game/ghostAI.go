@@ -274,3 +274,65 @@ func findSimplePath(level [][]int, startX, startY, endX, endY, tileSize int) []N
 
     return path
 }
+
+// ChaseStrategy sets a chasing ghost's target tile
+type ChaseStrategy func(g *Ghost, gameState *GameStateStruct)
+
+// Chase strategies by name, for comparing them in tournaments
+var chaseStrategies = map[string]ChaseStrategy{
+	"current": (*Ghost).updateChaseTarget,
+	"arcade":  (*Ghost).arcadeChaseTarget,
+	"direct":  (*Ghost).directChaseTarget,
+}
+
+// directChaseTarget sends every ghost straight for the player
+func (g *Ghost) directChaseTarget(gameState *GameStateStruct) {
+	g.TargetX, g.TargetY = pacmanTile(gameState, 0)
+}
+
+// arcadeChaseTarget gives each curse its arcade role: jogo chases, sukuna
+// ambushes four tiles ahead, kenjaku flanks off jogo and mahito backs off
+// to his corner when he gets within eight tiles
+func (g *Ghost) arcadeChaseTarget(gameState *GameStateStruct) {
+	switch g.GhostType {
+	case "sukuna":
+		g.TargetX, g.TargetY = pacmanTile(gameState, 4)
+	case "kenjaku":
+		x, y := pacmanTile(gameState, 2)
+		for _, other := range gameState.Ghosts {
+			if other.GhostType == "jogo" {
+				jx := int((other.X + float64(other.Size)/2) / TileSize)
+				jy := int((other.Y + float64(other.Size)/2) / TileSize)
+				x, y = 2*x-jx, 2*y-jy
+				break
+			}
+		}
+		g.TargetX, g.TargetY = x, y
+	case "mahito":
+		x, y := pacmanTile(gameState, 0)
+		if g.getDistance(float64(x), float64(y)) > 8 {
+			g.TargetX, g.TargetY = x, y
+		} else {
+			g.TargetX, g.TargetY = g.ScatterTarget[0], g.ScatterTarget[1]
+		}
+	default:
+		g.TargetX, g.TargetY = pacmanTile(gameState, 0)
+	}
+}
+
+// pacmanTile is the player's tile, or the tile ahead tiles in front of it
+func pacmanTile(gameState *GameStateStruct, ahead int) (int, int) {
+	x := int((gameState.PacmanX + 16) / TileSize)
+	y := int((gameState.PacmanY + 16) / TileSize)
+	switch gameState.PacmanDirection {
+	case "up":
+		y -= ahead
+	case "down":
+		y += ahead
+	case "left":
+		x -= ahead
+	case "right":
+		x += ahead
+	}
+	return x, y
+}
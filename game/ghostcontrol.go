@@ -0,0 +1,67 @@
+package main
+
+// GhostController steers a ghost in place of UpdateTarget/moveToTarget.
+// Mode timers, speed changes and collisions still apply as for AI ghosts.
+type GhostController interface {
+	Steer(g *Ghost, gameState *GameStateStruct)
+}
+
+// HumanGhostController lets a local player drive a ghost. Like an arcade
+// ghost it never stops: the last requested direction is buffered and taken
+// as soon as the maze allows it, otherwise the ghost keeps going.
+type HumanGhostController struct {
+	Input *InputState
+	want  string
+}
+
+var steerActions = []struct {
+	action    Action
+	direction string
+}{
+	{ActionUp, "up"},
+	{ActionDown, "down"},
+	{ActionLeft, "left"},
+	{ActionRight, "right"},
+}
+
+func (c *HumanGhostController) Steer(g *Ghost, gameState *GameStateStruct) {
+	for _, s := range steerActions {
+		if c.Input.Pressed(s.action) {
+			c.want = s.direction
+		}
+	}
+	if c.want != "" && g.tryMove(gameState, c.want) {
+		return
+	}
+	g.tryMove(gameState, g.Direction)
+}
+
+// tryMove moves the ghost one step at its current speed if the way is free
+func (g *Ghost) tryMove(gameState *GameStateStruct, direction string) bool {
+	newX, newY := g.X, g.Y
+	switch direction {
+	case "up":
+		newY -= g.Speed
+	case "down":
+		newY += g.Speed
+	case "left":
+		newX -= g.Speed
+	case "right":
+		newX += g.Speed
+	default:
+		return false
+	}
+	if !g.isValidPosition(gameState, newX, newY) {
+		return false
+	}
+	g.X, g.Y = newX, newY
+	g.Direction = direction
+	return true
+}
+
+// isControlled reports whether the ghost is currently steered by its
+// controller. Going home after being eaten and leaving the house stay with
+// the AI so a controlled ghost follows the same house rules.
+func (g *Ghost) isControlled() bool {
+	return g.Controller != nil && g.Mode != DeadMode && g.Mode != InHouseMode
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"testing"
+
+	"jk/gym"
+	"jk/maze"
+)
+
+// The gym package models the game's rules without Ebiten. These tests run
+// it next to the headless matches of tournament.go on the same seed and
+// check that the two still agree where the model claims to be exact.
+
+// route steers the player along a fixed list of moves
+type route struct {
+	legs  []routeLeg
+	frame int
+}
+
+type routeLeg struct {
+	dir    string
+	frames int
+}
+
+func (r *route) next() string {
+	f := r.frame
+	r.frame++
+	for _, leg := range r.legs {
+		if f < leg.frames {
+			return leg.dir
+		}
+		f -= leg.frames
+	}
+	return ""
+}
+
+func (r *route) Act(*Observation) string { return r.next() }
+
+var gymActions = map[string]gym.Action{"": gym.Stay, "up": gym.Up, "down": gym.Down, "left": gym.Left, "right": gym.Right}
+
+// gymTile finds the tile set in channel c, or false if there's none
+func gymTile(obs gym.Observation, c int) ([2]int, bool) {
+	for y := 0; y < obs.Height; y++ {
+		for x := 0; x < obs.Width; x++ {
+			if obs.At(c, x, y) != 0 {
+				return [2]int{x, y}, true
+			}
+		}
+	}
+	return [2]int{}, false
+}
+
+func TestGymPlayerMatchesGame(t *testing.T) {
+	// From the start, along the top to the long column, down to the tunnel,
+	// out through its left end and in at the right, then down the column on
+	// that side. It ends moving down, when the game and the model have
+	// eaten the same pellets.
+	legs := []routeLeg{{"right", 80}, {"down", 192}, {"left", 240}, {"down", 48}}
+	const seed = 1
+
+	m := newMatch(TournamentConfig{Name: "gym"}, seed)
+	game := &route{legs: legs}
+	m.player.Controller = game
+
+	env := gym.New(gym.Config{FrameSkip: 1})
+	obs := env.Reset(seed)
+	model := &route{legs: legs}
+
+	total := 0
+	for _, leg := range legs {
+		total += leg.frames
+	}
+	wrapped := false
+	for frame := 1; frame <= total; frame++ {
+		m.step()
+		var info gym.Info
+		obs, _, _, info = env.Step(gymActions[model.next()])
+		if m.lives < 3 || info.Lives < 3 {
+			t.Fatalf("frame %d: a curse caught the player (game lives %d, model lives %d), pick another seed", frame, m.lives, info.Lives)
+		}
+
+		want := [2]int{int(m.player.X+TileSize/2) / TileSize, int(m.player.Y+TileSize/2) / TileSize}
+		got, _ := gymTile(obs, gym.ChannelPlayer)
+		if got != want {
+			t.Fatalf("frame %d: player on tile %v in the model, %v in the game", frame, got, want)
+		}
+		wrapped = wrapped || want[0] == len(m.level[0])-1
+	}
+	if !wrapped {
+		t.Fatal("the route never went through the tunnel")
+	}
+
+	for y, row := range m.level {
+		for x, tile := range row {
+			game := tile == TilePellet || tile == TilePowerPellet
+			model := obs.At(gym.ChannelPellets, x, y) != 0 || obs.At(gym.ChannelPowerPellets, x, y) != 0
+			if game != model {
+				t.Errorf("pellet at (%d, %d): game %v, model %v", x, y, game, model)
+			}
+		}
+	}
+}
+
+// cruiseFrames is the most common number of frames between a chasing or
+// scattering ghost's tile changes, which is a tile over its cruising speed
+func cruiseFrames(intervals map[int]int) int {
+	best := 0
+	for frames, n := range intervals {
+		if n > intervals[best] {
+			best = frames
+		}
+	}
+	return best
+}
+
+func TestGymGhostSpeedMatchesGame(t *testing.T) {
+	const seed, frames = 1, 3000
+
+	game := map[int]int{}
+	m := newMatch(TournamentConfig{Name: "gym"}, seed)
+	last := make([][2]int, len(m.ghosts))
+	since := make([]int, len(m.ghosts))
+	for i := 0; i < frames && !m.over; i++ {
+		m.step()
+		for g, ghost := range m.ghosts {
+			tile := [2]int{int(ghost.X+float64(ghost.Size)/2) / TileSize, int(ghost.Y+float64(ghost.Size)/2) / TileSize}
+			since[g]++
+			if tile != last[g] {
+				if ghost.Mode == ChaseMode || ghost.Mode == ScatterMode {
+					game[since[g]]++
+				}
+				last[g], since[g] = tile, 0
+			}
+		}
+	}
+
+	model := map[int]int{}
+	env := gym.New(gym.Config{FrameSkip: 1})
+	obs := env.Reset(seed)
+	last = make([][2]int, 4)
+	since = make([]int, 4)
+	moves := []gym.Action{gym.Right, gym.Down, gym.Left, gym.Up}
+	for i := 0; i < frames; i++ {
+		var done bool
+		obs, _, done, _ = env.Step(moves[i/120%len(moves)])
+		if done {
+			obs = env.Reset(seed + int64(i))
+		}
+		for g := range last {
+			since[g]++
+			tile, ok := gymTile(obs, gym.ChannelGhost0+g)
+			if !ok || tile == last[g] {
+				continue
+			}
+			if obs.At(gym.ChannelFright, tile[0], tile[1]) == 0 {
+				model[since[g]]++
+			}
+			last[g], since[g] = tile, 0
+		}
+	}
+
+	if gf, mf := cruiseFrames(game), cruiseFrames(model); gf != mf {
+		t.Fatalf("ghosts cross a tile in %d frames in the game, %d in the model", gf, mf)
+	}
+}
+
+func TestGymStartsMatchGame(t *testing.T) {
+	l, err := maze.Generate(3, 31, 27)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := snapshotTestGame(t)
+	classic := g.currentSetup()
+	t.Cleanup(func() { g.restoreSetup(classic) })
+	g.setLevel(l)
+	g.resetGhosts()
+
+	obs := gym.New(gym.Config{Level: l}).Reset(1)
+	want := [2]int{int(g.playerStartX) / TileSize, int(g.playerStartY) / TileSize}
+	if got, _ := gymTile(obs, gym.ChannelPlayer); got != want {
+		t.Errorf("player starts on %v in the model, %v in the game", got, want)
+	}
+	for i, ghost := range g.Ghosts {
+		want := [2]int{int(ghost.X) / TileSize, int(ghost.Y) / TileSize}
+		if got, _ := gymTile(obs, gym.ChannelGhost0+i); got != want {
+			t.Errorf("%s starts on %v in the model, %v in the game", ghost.GhostType, got, want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+)
+
+// TextSize picks one of the PressStart2P faces
+type TextSize int
+
+const (
+	TextSmall  TextSize = 10
+	TextMedium TextSize = 16
+	TextLarge  TextSize = 24
+	TextTitle  TextSize = 40
+)
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextStyle describes how DrawText renders a string. Zero values mean no
+// outline, no glow and no animation.
+type TextStyle struct {
+	Size    TextSize
+	Align   Align
+	Color   color.RGBA
+	Outline color.RGBA // drawn one pixel around the glyphs when alpha > 0
+	Glow    color.RGBA // soft halo when alpha > 0
+	Pulse   float64    // brightness pulse speed in radians per frame, 0 disables it
+}
+
+// Faces are created lazily per size and dropped when the font is reloaded
+var fontFaces = map[TextSize]font.Face{}
+
+func resetFontFaces() {
+	fontFaces = map[TextSize]font.Face{}
+}
+
+// fontFace returns the PressStart2P face of the given size, or the built-in
+// bitmap font when the TTF could not be loaded.
+func fontFace(size TextSize) font.Face {
+	if face, ok := fontFaces[size]; ok {
+		return face
+	}
+	var face font.Face = basicfont.Face7x13
+	if PressStartFont != nil {
+		f, err := opentype.NewFace(PressStartFont, &opentype.FaceOptions{
+			Size:    float64(size),
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err == nil {
+			face = f
+		}
+	}
+	fontFaces[size] = face
+	return face
+}
+
+// MeasureText returns the width and line height of s in the given size
+func MeasureText(s string, size TextSize) (int, int) {
+	face := fontFace(size)
+	return font.MeasureString(face, s).Ceil(), face.Metrics().Height.Ceil()
+}
+
+// DrawText draws s with its top edge at y; x is the left edge, center or
+// right edge depending on the alignment. timer drives the pulse animation.
+func DrawText(screen *ebiten.Image, s string, x, y int, style TextStyle, timer int) {
+	face := fontFace(style.Size)
+	width := font.MeasureString(face, s).Ceil()
+	switch style.Align {
+	case AlignCenter:
+		x -= width / 2
+	case AlignRight:
+		x -= width
+	}
+	y += face.Metrics().Ascent.Ceil()
+
+	clr := style.Color
+	if style.Pulse != 0 {
+		clr = pulseColor(clr, timer, style.Pulse)
+	}
+
+	if style.Glow.A > 0 {
+		glow := style.Glow
+		glow.A /= 3
+		for _, d := range [][2]int{{-3, 0}, {3, 0}, {0, -3}, {0, 3}, {-2, -2}, {2, -2}, {-2, 2}, {2, 2}} {
+			text.Draw(screen, s, face, x+d[0], y+d[1], glow)
+		}
+	}
+	if style.Outline.A > 0 {
+		for _, d := range [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+			text.Draw(screen, s, face, x+d[0], y+d[1], style.Outline)
+		}
+	}
+	text.Draw(screen, s, face, x, y, clr)
+}
+
+// pulseColor scales the brightness of c between 60% and 100%
+func pulseColor(c color.RGBA, timer int, speed float64) color.RGBA {
+	k := 0.8 + 0.2*math.Sin(float64(timer)*speed)
+	return color.RGBA{uint8(float64(c.R) * k), uint8(float64(c.G) * k), uint8(float64(c.B) * k), c.A}
+}
+
+// lerpColor blends from a to b, t in [0,1]
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t))
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
+
+// Common HUD styles
+var (
+	hudLabelStyle = TextStyle{Size: TextSmall, Color: color.RGBA{255, 80, 80, 255}}
+	hudValueStyle = TextStyle{Size: TextMedium, Color: color.RGBA{255, 255, 255, 255}}
+	overlayTitle  = TextStyle{Size: TextTitle, Align: AlignCenter, Color: color.RGBA{255, 215, 0, 255},
+		Outline: color.RGBA{0, 0, 0, 255}, Glow: color.RGBA{148, 0, 211, 255}}
+	overlayText = TextStyle{Size: TextMedium, Align: AlignCenter, Color: color.RGBA{255, 255, 255, 255},
+		Outline: color.RGBA{0, 0, 0, 255}}
+	overlayHint = TextStyle{Size: TextSmall, Align: AlignCenter, Color: color.RGBA{150, 200, 255, 255}, Pulse: 0.1}
+)
+
+// Number of rounds shown in the fruit row
+const maxFruitIcons = 7
+
+// drawHUD fills the strips above and below the playfield
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	field := playfieldRect()
+	black := color.RGBA{0, 0, 0, 255}
+	ebitenutil.DrawRect(screen, 0, 0, logicalWidth, hudTopHeight, black)
+	ebitenutil.DrawRect(screen, 0, float64(field.Max.Y), logicalWidth, hudBottomHeight, black)
+
+	// Top strip: score, high score, round
+	score := hudValueStyle
+	if g.Player.Score > 1000 {
+		score.Color = color.RGBA{255, 255, 100, 255}
+		score.Pulse = 0.2
+	}
+	if g.twoPlayer {
+		// Both players' scores, the label of the one playing blinks like in the arcade
+		for i, b := range g.boards {
+			x := 40 + i*220
+			value, points := hudValueStyle, b.Score
+			if i == g.currentPlayer {
+				value, points = score, g.Player.Score
+			}
+			if i != g.currentPlayer || g.globalTimer/20%2 == 0 {
+				DrawText(screen, fmt.Sprintf("%dUP", i+1), x, 8, hudLabelStyle, g.globalTimer)
+			}
+			DrawText(screen, fmt.Sprintf("%d", points), x, 26, value, g.globalTimer)
+		}
+	} else {
+		DrawText(screen, "1UP", 40, 8, hudLabelStyle, g.globalTimer)
+		DrawText(screen, fmt.Sprintf("%d", g.Player.Score), 40, 26, score, g.globalTimer)
+	}
+
+	center := hudLabelStyle
+	center.Align = AlignCenter
+	DrawText(screen, "HIGH SCORE", logicalWidth/2, 8, center, g.globalTimer)
+	high := hudValueStyle
+	high.Align = AlignCenter
+	DrawText(screen, fmt.Sprintf("%d", g.highScore()), logicalWidth/2, 26, high, g.globalTimer)
+
+	right := hudLabelStyle
+	right.Align = AlignRight
+	DrawText(screen, fmt.Sprintf("ROUND %d", g.RoundNumber), logicalWidth-40, 8, right, g.globalTimer)
+	pellets := hudValueStyle
+	pellets.Size = TextSmall
+	pellets.Align = AlignRight
+	DrawText(screen, fmt.Sprintf("PELLETS %d", g.pelletCount), logicalWidth-40, 30, pellets, g.globalTimer)
+
+	// Power pellet countdown turns red and flashes in the last two seconds
+	if g.powerPelletActive {
+		timeLeft := g.powerPelletTimer / 60 // Convert to seconds
+		power := TextStyle{Size: TextSmall, Align: AlignCenter, Color: color.RGBA{255, 255, 100, 255}}
+		if timeLeft <= 2 {
+			power.Color = color.RGBA{255, 50, 50, 255}
+			power.Pulse = 0.5
+		}
+		DrawText(screen, fmt.Sprintf("CURSED POWER %ds", timeLeft), logicalWidth/2+260, 20, power, g.globalTimer)
+	}
+
+	// Bottom strip: remaining lives on the left, fruit row on the right
+	iconSize := float64(hudBottomHeight - 16)
+	iconY := float64(field.Max.Y + 8)
+	if g.Player != nil {
+		for i := 0; i < g.lives-1; i++ {
+			drawIcon(screen, g.Player.Image, 40+float64(i)*(iconSize+8), iconY, iconSize)
+		}
+	}
+	if g.versus != nil {
+		g.drawVersusHUD(screen)
+	}
+	if g.boss != nil {
+		g.drawBossHUD(screen)
+	} else if g.endless != nil {
+		g.drawEndlessHUD(screen)
+	}
+	g.ruleset().DrawHUD(g, screen)
+	fruit := Assets.Image("fruit.cherry")
+	for i := 0; i < min(g.RoundNumber, maxFruitIcons); i++ {
+		drawIcon(screen, fruit, logicalWidth-40-iconSize-float64(i)*(iconSize+8), iconY, iconSize)
+	}
+}
+
+// drawIcon draws img scaled to a size x size square at x, y
+func drawIcon(screen, img *ebiten.Image, x, y, size float64) {
+	b := img.Bounds()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(size/float64(b.Dx()), size/float64(b.Dy()))
+	op.GeoM.Translate(x, y)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(img, op)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Action is something the player wants to do, independent of the device
+type Action int
+
+const (
+	ActionUp Action = iota
+	ActionDown
+	ActionLeft
+	ActionRight
+	ActionConfirm
+	ActionBack
+	ActionPause
+	ActionMuteMusic
+	ActionMuteSFX
+	ActionVolumeUp
+	ActionVolumeDown
+	ActionQuickSave
+	ActionQuickLoad
+	ActionDebugOverlay
+	ActionStepFrame
+	ActionSlowMotion
+	actionCount
+)
+
+// Names used for actions in the config file
+var actionNames = [actionCount]string{
+	ActionUp:         "up",
+	ActionDown:       "down",
+	ActionLeft:       "left",
+	ActionRight:      "right",
+	ActionConfirm:    "confirm",
+	ActionBack:       "back",
+	ActionPause:      "pause",
+	ActionMuteMusic:  "mute_music",
+	ActionMuteSFX:    "mute_sfx",
+	ActionVolumeUp:   "volume_up",
+	ActionVolumeDown: "volume_down",
+	ActionQuickSave:  "quick_save",
+	ActionQuickLoad:  "quick_load",
+
+	ActionDebugOverlay: "debug_overlay",
+	ActionStepFrame:    "step_frame",
+	ActionSlowMotion:   "slow_motion",
+}
+
+func (a Action) String() string {
+	if a >= 0 && a < actionCount {
+		return actionNames[a]
+	}
+	return fmt.Sprintf("action(%d)", int(a))
+}
+
+// GamepadButton is a button of Ebiten's standard gamepad layout with a
+// readable name in the config file
+type GamepadButton ebiten.StandardGamepadButton
+
+var gamepadButtonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "A",
+	ebiten.StandardGamepadButtonRightRight:       "B",
+	ebiten.StandardGamepadButtonRightLeft:        "X",
+	ebiten.StandardGamepadButtonRightTop:         "Y",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "LB",
+	ebiten.StandardGamepadButtonFrontTopRight:    "RB",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "LT",
+	ebiten.StandardGamepadButtonFrontBottomRight: "RT",
+	ebiten.StandardGamepadButtonCenterLeft:       "Back",
+	ebiten.StandardGamepadButtonCenterRight:      "Start",
+	ebiten.StandardGamepadButtonLeftStick:        "LeftStick",
+	ebiten.StandardGamepadButtonRightStick:       "RightStick",
+	ebiten.StandardGamepadButtonLeftTop:          "DpadUp",
+	ebiten.StandardGamepadButtonLeftBottom:       "DpadDown",
+	ebiten.StandardGamepadButtonLeftLeft:         "DpadLeft",
+	ebiten.StandardGamepadButtonLeftRight:        "DpadRight",
+	ebiten.StandardGamepadButtonCenterCenter:     "Home",
+}
+
+func (b GamepadButton) MarshalText() ([]byte, error) {
+	if name, ok := gamepadButtonNames[ebiten.StandardGamepadButton(b)]; ok {
+		return []byte(name), nil
+	}
+	return nil, fmt.Errorf("unknown gamepad button %d", int(b))
+}
+
+func (b *GamepadButton) UnmarshalText(text []byte) error {
+	for button, name := range gamepadButtonNames {
+		if name == string(text) {
+			*b = GamepadButton(button)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown gamepad button %q", text)
+}
+
+// Binding lists the physical inputs that trigger one action
+type Binding struct {
+	Keys    []ebiten.Key    `json:"keys,omitempty"`
+	Buttons []GamepadButton `json:"buttons,omitempty"`
+}
+
+// Bindings maps every action to its inputs. In the config file it is an
+// object keyed by action name.
+type Bindings map[Action]Binding
+
+func (b Bindings) MarshalJSON() ([]byte, error) {
+	out := make(map[string]Binding, len(b))
+	for action, binding := range b {
+		out[action.String()] = binding
+	}
+	return json.Marshal(out)
+}
+
+func (b *Bindings) UnmarshalJSON(data []byte) error {
+	var in map[string]Binding
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*b = Bindings{}
+	for name, binding := range in {
+		action, ok := actionByName(name)
+		if !ok {
+			return fmt.Errorf("unknown input action %q", name)
+		}
+		(*b)[action] = binding
+	}
+	return nil
+}
+
+func actionByName(name string) (Action, bool) {
+	for a, n := range actionNames {
+		if n == name {
+			return Action(a), true
+		}
+	}
+	return 0, false
+}
+
+// defaultBindings covers arrows, WASD and a standard gamepad
+func defaultBindings() Bindings {
+	return Bindings{
+		ActionUp:         {Keys: []ebiten.Key{ebiten.KeyArrowUp, ebiten.KeyW}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftTop)}},
+		ActionDown:       {Keys: []ebiten.Key{ebiten.KeyArrowDown, ebiten.KeyS}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftBottom)}},
+		ActionLeft:       {Keys: []ebiten.Key{ebiten.KeyArrowLeft, ebiten.KeyA}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftLeft)}},
+		ActionRight:      {Keys: []ebiten.Key{ebiten.KeyArrowRight, ebiten.KeyD}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftRight)}},
+		ActionConfirm:    {Keys: []ebiten.Key{ebiten.KeyEnter, ebiten.KeySpace}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonRightBottom)}},
+		ActionBack:       {Keys: []ebiten.Key{ebiten.KeyEscape, ebiten.KeyBackspace}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonRightRight)}},
+		ActionPause:      {Keys: []ebiten.Key{ebiten.KeyEscape, ebiten.KeyP}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonCenterRight)}},
+		ActionMuteMusic:  {Keys: []ebiten.Key{ebiten.KeyM}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonCenterLeft)}},
+		ActionMuteSFX:    {Keys: []ebiten.Key{ebiten.KeyN}},
+		ActionVolumeUp:   {Keys: []ebiten.Key{ebiten.KeyEqual}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonFrontTopRight)}},
+		ActionVolumeDown: {Keys: []ebiten.Key{ebiten.KeyMinus}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonFrontTopLeft)}},
+		ActionQuickSave:  {Keys: []ebiten.Key{ebiten.KeyF5}},
+		ActionQuickLoad:  {Keys: []ebiten.Key{ebiten.KeyF9}},
+
+		ActionDebugOverlay: {Keys: []ebiten.Key{ebiten.KeyF3}},
+		ActionStepFrame:    {Keys: []ebiten.Key{ebiten.KeyPeriod}},
+		ActionSlowMotion:   {Keys: []ebiten.Key{ebiten.KeyF4}},
+	}
+}
+
+// withDefaults fills in actions missing from a (possibly partial) config
+func (b Bindings) withDefaults() Bindings {
+	out := defaultBindings()
+	for action, binding := range b {
+		out[action] = binding
+	}
+	return out
+}
+
+// without returns a copy of b minus every key that other also uses
+func (b Bindings) without(other Bindings) Bindings {
+	taken := map[ebiten.Key]bool{}
+	for _, binding := range other {
+		for _, key := range binding.Keys {
+			taken[key] = true
+		}
+	}
+	out := Bindings{}
+	for action, binding := range b {
+		var keys []ebiten.Key
+		for _, key := range binding.Keys {
+			if !taken[key] {
+				keys = append(keys, key)
+			}
+		}
+		out[action] = Binding{Keys: keys, Buttons: binding.Buttons}
+	}
+	return out
+}
+
+// defaultGhostBindings is what the ghost player uses in versus mode
+func defaultGhostBindings() Bindings {
+	return Bindings{
+		ActionUp:    {Keys: []ebiten.Key{ebiten.KeyW}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftTop)}},
+		ActionDown:  {Keys: []ebiten.Key{ebiten.KeyS}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftBottom)}},
+		ActionLeft:  {Keys: []ebiten.Key{ebiten.KeyA}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftLeft)}},
+		ActionRight: {Keys: []ebiten.Key{ebiten.KeyD}, Buttons: []GamepadButton{GamepadButton(ebiten.StandardGamepadButtonLeftRight)}},
+	}
+}
+
+// Left stick deflection that counts as a direction press
+const stickDeadzone = 0.5
+
+// InputState turns the bound keys, buttons and left stick into per-frame
+// action states. Update must run once per tick before anything reads it.
+type InputState struct {
+	bindings Bindings
+	held     [actionCount]bool
+	prev     [actionCount]bool
+	gamepads []ebiten.GamepadID
+	pad      int // index into the connected gamepads to read, -1 for all of them
+}
+
+// Input is shared by the menu, the intro and the game
+var Input = NewInputState(defaultBindings())
+
+func NewInputState(bindings Bindings) *InputState {
+	return &InputState{bindings: bindings.withDefaults(), pad: -1}
+}
+
+// NewPlayerInputState reads only the given bindings and the pad-th connected
+// gamepad, so two local players can share the keyboard and each have a pad.
+// Unlike NewInputState it does not fill in missing actions.
+func NewPlayerInputState(bindings Bindings, pad int) *InputState {
+	return &InputState{bindings: bindings, pad: pad}
+}
+
+// SetBindings replaces the bindings, e.g. after loading the config
+func (in *InputState) SetBindings(bindings Bindings) {
+	in.bindings = bindings.withDefaults()
+}
+
+func (in *InputState) Update() {
+	in.prev = in.held
+	pads := len(in.gamepads)
+	in.gamepads = ebiten.AppendGamepadIDs(in.gamepads[:0])
+	if len(in.gamepads) != pads {
+		inputLog.Info("gamepads changed", "count", len(in.gamepads))
+	}
+
+	for a := Action(0); a < actionCount; a++ {
+		in.held[a] = in.sample(a)
+		if in.held[a] && !in.prev[a] {
+			inputLog.Debug("pressed", "action", a)
+		}
+	}
+}
+
+func (in *InputState) sample(action Action) bool {
+	binding := in.bindings[action]
+	for _, key := range binding.Keys {
+		if ebiten.IsKeyPressed(key) {
+			return true
+		}
+	}
+	for i, id := range in.gamepads {
+		if in.pad >= 0 && i != in.pad {
+			continue
+		}
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for _, button := range binding.Buttons {
+			if ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButton(button)) {
+				return true
+			}
+		}
+		if stickPressed(id, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// stickPressed maps the left stick onto the four direction actions
+func stickPressed(id ebiten.GamepadID, action Action) bool {
+	x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	switch action {
+	case ActionUp:
+		return y < -stickDeadzone
+	case ActionDown:
+		return y > stickDeadzone
+	case ActionLeft:
+		return x < -stickDeadzone
+	case ActionRight:
+		return x > stickDeadzone
+	}
+	return false
+}
+
+// Pressed reports whether the action is held this frame
+func (in *InputState) Pressed(action Action) bool {
+	return in.held[action]
+}
+
+// JustPressed reports whether the action started this frame
+func (in *InputState) JustPressed(action Action) bool {
+	return in.held[action] && !in.prev[action]
+}
+
+// Mask packs the held actions into a bitmask, one bit per Action
+func (in *InputState) Mask() uint16 {
+	var m uint16
+	for a := Action(0); a < actionCount; a++ {
+		if in.held[a] {
+			m |= 1 << a
+		}
+	}
+	return m
+}
+
+// SetMask replaces sampling for one frame: the actions in m become held.
+// Used for players whose input arrives over the network.
+func (in *InputState) SetMask(m uint16) {
+	in.prev = in.held
+	for a := Action(0); a < actionCount; a++ {
+		in.held[a] = m&(1<<a) != 0
+	}
+}
@@ -5,7 +5,7 @@ import (
     "fmt"
     "github.com/hajimehoshi/ebiten/v2"
     "github.com/hajimehoshi/ebiten/v2/ebitenutil"
-    "github.com/hajimehoshi/ebiten/v2/inpututil"
+    "github.com/hajimehoshi/ebiten/v2/text"
     "golang.org/x/image/font"
     "golang.org/x/image/font/opentype"
     "image/color"
@@ -72,7 +72,6 @@ func NewIntroSystem() *IntroSystem {
         Timer:          0,
         Alpha:          0.0,
         TextAlpha:      0.0,
-        CharacterNames: []string{"Gojo Satoru", "Sukuna", "Kenjaku", "Mahito"},
         CurrentChar:    0,
         CharRevealTimer: 0,
         ReadyTimer:     0,
@@ -89,22 +88,30 @@ func NewIntroSystem() *IntroSystem {
 func (i *IntroSystem) loadAssets() {
     var err error
     
-    // Load images (create placeholder if files don't exist)
-    i.TitleImage = loadImageWithFallback("assets/jjk_title.png", 400, 200, color.RGBA{255, 100, 100, 255})
-    i.LogoImage = loadImageWithFallback("assets/jjk_logo.png", 300, 150, color.RGBA{200, 50, 50, 255})
-    i.BackgroundImage = loadImageWithFallback("assets/intro_bg.png", 1200, 800, color.RGBA{20, 20, 40, 255})
+    // Names come from the active theme
+    i.CharacterNames = []string{
+        CharacterName("player"),
+        CharacterName("sukuna"),
+        CharacterName("kenjaku"),
+        CharacterName("mahito"),
+    }
+
+    // Load images (the manifest supplies a placeholder if files don't exist)
+    i.TitleImage = Assets.Image("intro.title")
+    i.LogoImage = Assets.Image("intro.logo")
+    i.BackgroundImage = Assets.Image("intro.background")
     
     // Load character images
-    charPaths := []string{
-        "assets/gojo_intro.png",
-        "assets/sukuna_intro.png", 
-        "assets/kenjaku_intro.png",
-        "assets/mahito_intro.png",
+    charNames := []string{
+        "intro.gojo",
+        "intro.sukuna",
+        "intro.kenjaku",
+        "intro.mahito",
     }
     
-    for _, path := range charPaths {
-        img := loadImageWithFallback(path, 300, 400, color.RGBA{100, 100, 200, 255})
-        i.CharacterImages = append(i.CharacterImages, img)
+    i.CharacterImages = nil
+    for _, name := range charNames {
+        i.CharacterImages = append(i.CharacterImages, Assets.Image(name))
     }
     
     // Create fonts
@@ -131,17 +138,6 @@ func (i *IntroSystem) loadAssets() {
     }
 }
 
-func loadImageWithFallback(path string, width, height int, fallbackColor color.RGBA) *ebiten.Image {
-    img, _, err := ebitenutil.NewImageFromFile(path)
-    if err != nil {
-        // Create fallback image
-        fallback := ebiten.NewImage(width, height)
-        fallback.Fill(fallbackColor)
-        return fallback
-    }
-    return img
-}
-
 func (i *IntroSystem) initParticles() {
     // Create cursed energy particles
     for j := 0; j < 50; j++ {
@@ -213,9 +209,7 @@ func (i *IntroSystem) updateTitleScreen() error {
     i.FlashEffect = math.Max(0, i.FlashEffect-0.05)
     
     // Skip to character reveal on input or after delay
-    if inpututil.IsKeyJustPressed(ebiten.KeySpace) || 
-       inpututil.IsKeyJustPressed(ebiten.KeyEnter) ||
-       i.Timer > 300 {
+    if Input.JustPressed(ActionConfirm) || i.Timer > 300 {
         
         i.State = IntroCharacterReveal
         i.Timer = 0
@@ -225,7 +219,7 @@ func (i *IntroSystem) updateTitleScreen() error {
         // Play sound effect
         i.SoundManager.PlaySFX("transition")
         
-        fmt.Println("Transitioning to character reveal")
+        stateLog.Debug("intro: character reveal")
     }
     
     return nil
@@ -249,13 +243,12 @@ func (i *IntroSystem) updateCharacterReveal() error {
             i.State = IntroReadyScreen
             i.Timer = 0
             i.ReadyTimer = 0
-            fmt.Println("All characters revealed, moving to ready screen")
+            stateLog.Debug("intro: ready screen")
         }
     }
     
     // Skip on input
-    if inpututil.IsKeyJustPressed(ebiten.KeySpace) || 
-       inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+    if Input.JustPressed(ActionConfirm) {
         i.State = IntroReadyScreen
         i.Timer = 0
         i.ReadyTimer = 0
@@ -271,12 +264,10 @@ func (i *IntroSystem) updateReadyScreen() error {
     i.ReadyPulse = 0.8 + 0.2*math.Sin(float64(i.ReadyTimer)*0.2)
     
     // Auto-advance after delay or on input
-    if i.ReadyTimer > 180 || 
-       inpututil.IsKeyJustPressed(ebiten.KeySpace) || 
-       inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+    if i.ReadyTimer > 180 || Input.JustPressed(ActionConfirm) {
         
         i.State = IntroComplete
-        fmt.Println("Intro complete, starting game")
+        stateLog.Debug("intro complete")
         
         // Play game start sound
         i.SoundManager.PlaySFX("game_start")
@@ -409,20 +400,19 @@ func (i *IntroSystem) drawParticles(screen *ebiten.Image) {
     }
 }
 
-// Helper function to draw centered text
-func drawTextCentered(screen *ebiten.Image, text string, font font.Face, x, y float64, clr color.Color) {
-    if font == nil {
-        // Fallback to debug print if font is not available
-        ebitenutil.DebugPrintAt(screen, text, int(x-float64(len(text)*6)), int(y))
+// Helper function to draw centered text, y is the top of the line
+func drawTextCentered(screen *ebiten.Image, s string, face font.Face, x, y float64, clr color.Color) {
+    if face == nil {
+        // Fall back to the shared HUD text layer if the intro faces are missing
+        r, g, b, a := clr.RGBA()
+        style := TextStyle{Size: TextMedium, Align: AlignCenter, Color: color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}}
+        DrawText(screen, s, int(x), int(y), style, 0)
         return
     }
     
-    // Calculate text width for centering (approximate)
-    textWidth := float64(len(text)) * 12 // Rough estimation
-    drawX := x - textWidth/2
-    
-    // For now, use debug print with offset - in a real implementation you'd use text.Draw
-    ebitenutil.DebugPrintAt(screen, text, int(drawX), int(y))
+    textWidth := font.MeasureString(face, s).Ceil()
+    drawX := int(x) - textWidth/2
+    text.Draw(screen, s, face, drawX, int(y)+face.Metrics().Ascent.Ceil(), clr)
 }
 
 func (i *IntroSystem) IsComplete() bool {
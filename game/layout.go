@@ -0,0 +1,82 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Every state draws onto a canvas of this fixed logical size. The canvas is
+// then scaled to the window by an integer factor where possible and
+// letterboxed, so switching between menu and play never resizes anything.
+const (
+	logicalWidth  = screenWidth
+	logicalHeight = screenHeight
+
+	hudTopHeight    = 56 // score, high score, round
+	hudBottomHeight = 48 // lives and fruit row
+)
+
+var letterboxColor = color.RGBA{0, 0, 0, 255}
+
+// playfieldRect is the canvas area between the two HUD strips
+func playfieldRect() image.Rectangle {
+	return image.Rect(0, hudTopHeight, logicalWidth, logicalHeight-hudBottomHeight)
+}
+
+// fitScale returns the scale that fits a w x h image into the bounds.
+// Scales of 1 or more are rounded down to an integer to keep pixels sharp;
+// anything that has to shrink keeps its fractional scale.
+func fitScale(w, h int, bounds image.Rectangle) float64 {
+	scale := math.Min(float64(bounds.Dx())/float64(w), float64(bounds.Dy())/float64(h))
+	if scale >= 1 {
+		scale = math.Floor(scale)
+	}
+	return scale
+}
+
+// centerIn returns the GeoM that draws a w x h image scaled and centered inside bounds
+func centerIn(w, h int, bounds image.Rectangle) ebiten.GeoM {
+	scale := fitScale(w, h, bounds)
+	var geo ebiten.GeoM
+	geo.Scale(scale, scale)
+	geo.Translate(
+		math.Floor(float64(bounds.Min.X)+(float64(bounds.Dx())-float64(w)*scale)/2),
+		math.Floor(float64(bounds.Min.Y)+(float64(bounds.Dy())-float64(h)*scale)/2),
+	)
+	return geo
+}
+
+// offscreen returns img if it already has the given size, otherwise a new image
+func offscreen(img *ebiten.Image, w, h int) *ebiten.Image {
+	if img != nil {
+		if b := img.Bounds(); b.Dx() == w && b.Dy() == h {
+			img.Clear()
+			return img
+		}
+		img.Deallocate()
+	}
+	return ebiten.NewImage(w, h)
+}
+
+// drawPlayfield renders the maze and everything on it at its native size and
+// places the result in the middle of the playfield, whatever the maze size.
+func (g *Game) drawPlayfield(canvas *ebiten.Image) {
+	w, h := len(level[0])*TileSize, len(level)*TileSize
+	g.world = offscreen(g.world, w, h)
+	g.drawGame(g.world)
+
+	op := &ebiten.DrawImageOptions{GeoM: centerIn(w, h, playfieldRect())}
+	op.Filter = ebiten.FilterLinear
+	canvas.DrawImage(g.world, op)
+}
+
+// present draws the logical canvas onto the window
+func (g *Game) present(screen, canvas *ebiten.Image) {
+	screen.Fill(letterboxColor)
+	op := &ebiten.DrawImageOptions{GeoM: centerIn(logicalWidth, logicalHeight, screen.Bounds())}
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(canvas, op)
+}
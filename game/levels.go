@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"jk/maze"
+)
+
+// Tiles the curses start on, in the order of newCurses. The classic values
+// are kept as they were tuned; useLevel derives them from a level's house.
+var ghostStartTiles = [][2]int{
+	{13, 13}, // jogo - center of ghost house
+	{12, 13}, // sukuna - left of center
+	{14, 13}, // kenjaku - right of center
+	{13, 14}, // mahito - below center
+}
+
+// useLevel swaps the maze and everything placed in it for l and starts
+// afresh on it
+func (g *Game) useLevel(l *maze.Level) {
+	g.setLevel(l)
+	g.resetGame()
+}
+
+// setLevel swaps the maze for l without touching the game in progress. The
+// ghost house constants, curse starts and fruit spot follow the level's
+// house, door and fruit markers.
+func (g *Game) setLevel(l *maze.Level) {
+	setup := levelSetup{
+		template: maze.Copy(l.Tiles),
+		start:    [2]float64{float64(l.Start.X * TileSize), float64(l.Start.Y * TileSize)},
+		fruit:    [2]int{l.Fruit.X, l.Fruit.Y},
+		houseX:   l.Door.X,
+		houseY:   l.House.Y + l.House.H/2,
+		exitY:    l.HouseExit().Y,
+	}
+	for _, p := range l.GhostStarts() {
+		setup.ghostStarts = append(setup.ghostStarts, [2]int{p.X, p.Y})
+	}
+	for _, ghost := range g.Ghosts {
+		ghost.SetScatterCorner(setup.template)
+		setup.scatter = append(setup.scatter, ghost.ScatterTarget)
+	}
+	g.restoreSetup(setup)
+}
+
+// levelSetup is the maze in play and everything derived from it, so that
+// a mode playing its own mazes can put the previous one back
+type levelSetup struct {
+	template    [][]int
+	start       [2]float64
+	fruit       [2]int
+	ghostStarts [][2]int
+	scatter     [][2]int // per ghost in g.Ghosts
+	houseX      int
+	houseY      int
+	exitY       int
+}
+
+func (g *Game) currentSetup() levelSetup {
+	s := levelSetup{
+		template:    levelTemplate,
+		start:       [2]float64{g.playerStartX, g.playerStartY},
+		fruit:       fruitTile,
+		ghostStarts: ghostStartTiles,
+		houseX:      GHOST_HOUSE_X,
+		houseY:      GHOST_HOUSE_Y,
+		exitY:       GHOST_HOUSE_EXIT_Y,
+	}
+	for _, ghost := range g.Ghosts {
+		s.scatter = append(s.scatter, ghost.ScatterTarget)
+	}
+	return s
+}
+
+// restoreSetup makes s the maze in play, with all of its pellets back
+func (g *Game) restoreSetup(s levelSetup) {
+	level = maze.Copy(s.template)
+	levelTemplate = s.template
+	g.gameState.Level = level
+	g.mazeImage = nil
+
+	g.playerStartX, g.playerStartY = s.start[0], s.start[1]
+	fruitTile = s.fruit
+	ghostStartTiles = s.ghostStarts
+	GHOST_HOUSE_X, GHOST_HOUSE_Y, GHOST_HOUSE_EXIT_Y = s.houseX, s.houseY, s.exitY
+	for i, ghost := range g.Ghosts {
+		if i < len(s.scatter) {
+			ghost.ScatterTarget = s.scatter[i]
+		}
+	}
+}
+
+// loadLevel reads a level file for play. Warnings are logged; errors make
+// the level unplayable and are all returned.
+func loadLevel(path string) (*maze.Level, error) {
+	l, err := maze.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, p := range maze.Validate(l) {
+		if p.Severity == maze.Error {
+			errs = append(errs, fmt.Errorf("%s: %s", path, p))
+		} else {
+			stateLog.Warn("level problem", "path", path, "problem", p.String())
+		}
+	}
+	return l, errors.Join(errs...)
+}
+
+// runValidateLevel is the validate-level subcommand. It prints the problems
+// in each level file and exits non-zero if any level has errors.
+func runValidateLevel(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: validate-level <level file>...")
+		return 2
+	}
+	status := 0
+	for _, path := range args {
+		l, err := maze.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "validate-level: %v\n", err)
+			status = 2
+			continue
+		}
+		problems := maze.Validate(l)
+		for _, p := range problems {
+			fmt.Printf("%s: %s\n", path, p)
+		}
+		if maze.HasErrors(problems) {
+			status = max(status, 1)
+		} else {
+			fmt.Printf("%s: ok\n", path)
+		}
+	}
+	return status
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Debug output goes through one slog logger per category. Everything below
+// the category's level is dropped before any formatting, so the loggers
+// cost next to nothing in the frame loop unless asked for with --log or
+// JK_LOG, e.g. JK_LOG=ai=debug,audio=info.
+var (
+	aiLog     = newCategoryLogger("ai", defaultLogLevel, defaultLogHandler())
+	audioLog  = newCategoryLogger("audio", defaultLogLevel, defaultLogHandler())
+	inputLog  = newCategoryLogger("input", defaultLogLevel, defaultLogHandler())
+	stateLog  = newCategoryLogger("state", defaultLogLevel, defaultLogHandler())
+	assetsLog = newCategoryLogger("assets", defaultLogLevel, defaultLogHandler())
+)
+
+// logCategories lets setupLogging swap the loggers by name
+var logCategories = map[string]**slog.Logger{
+	"ai":     &aiLog,
+	"audio":  &audioLog,
+	"input":  &inputLog,
+	"state":  &stateLog,
+	"assets": &assetsLog,
+}
+
+// Quiet by default: only warnings and errors
+const defaultLogLevel = slog.LevelWarn
+
+// logOff is above every level slog uses, so nothing gets through
+const logOff = slog.Level(100)
+
+func defaultLogHandler() slog.Handler {
+	return slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+}
+
+// levelFilter drops records below its level before they reach the handler
+type levelFilter struct {
+	level slog.Level
+	slog.Handler
+}
+
+func (f levelFilter) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= f.level && f.Handler.Enabled(ctx, level)
+}
+
+func (f levelFilter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelFilter{f.level, f.Handler.WithAttrs(attrs)}
+}
+
+func (f levelFilter) WithGroup(name string) slog.Handler {
+	return levelFilter{f.level, f.Handler.WithGroup(name)}
+}
+
+func newCategoryLogger(category string, level slog.Level, out slog.Handler) *slog.Logger {
+	return slog.New(levelFilter{level, out.WithAttrs([]slog.Attr{slog.String("category", category)})})
+}
+
+// debugEnabled reports whether l logs debug records, for guarding output
+// that is costly to build
+func debugEnabled(l *slog.Logger) bool {
+	return l.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// parseLogSpec reads a comma separated list of levels (debug, info, warn,
+// error or off). A bare level applies to every category and category=level
+// to one, later entries winning.
+func parseLogSpec(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level, len(logCategories))
+	for name := range logCategories {
+		levels[name] = defaultLogLevel
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		category, value, scoped := strings.Cut(entry, "=")
+		if !scoped {
+			category, value = "", entry
+		}
+		level, err := parseLogLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		if !scoped {
+			for name := range levels {
+				levels[name] = level
+			}
+			continue
+		}
+		category = strings.ToLower(strings.TrimSpace(category))
+		if _, ok := logCategories[category]; !ok {
+			return nil, fmt.Errorf("unknown log category %q (want ai, audio, input, state or assets)", category)
+		}
+		levels[category] = level
+	}
+	return levels, nil
+}
+
+func parseLogLevel(s string) (slog.Level, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "off") {
+		return logOff, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (want debug, info, warn, error or off)", s)
+	}
+	return level, nil
+}
+
+// setupLogging sets each category's level from spec and sends the output to
+// stderr as text, or to jsonPath as JSON lines when it is set. The returned
+// function closes the file.
+func setupLogging(spec, jsonPath string) (func(), error) {
+	levels, err := parseLogSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	out := defaultLogHandler()
+	closeFn := func() {}
+	if jsonPath != "" {
+		f, err := os.OpenFile(jsonPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("log file: %w", err)
+		}
+		out = slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})
+		closeFn = func() { f.Close() }
+	}
+
+	for name, logger := range logCategories {
+		*logger = newCategoryLogger(name, levels[name], out)
+	}
+	return closeFn, nil
+}
@@ -104,22 +104,140 @@
 package main
 
 import (
+    "flag"
     "github.com/hajimehoshi/ebiten/v2"
+    "io"
     "log"
+    "os"
 )
 
 func main() {
+    if len(os.Args) > 1 {
+        // Subcommands take their logging setup from the environment. They
+        // exit without closing the log file, which is fine as it's unbuffered.
+        if _, err := setupLogging(os.Getenv("JK_LOG"), os.Getenv("JK_LOG_JSON")); err != nil {
+            log.Fatalf("Logging: %v", err)
+        }
+        switch os.Args[1] {
+        case "validate-assets":
+            os.Exit(runValidateAssets(os.Args[2:]))
+        case "tournament":
+            os.Exit(runTournament(os.Args[2:]))
+        case "validate-level":
+            os.Exit(runValidateLevel(os.Args[2:]))
+        }
+    }
+
+    assetDir := flag.String("assets", "", "directory of files that override the embedded assets")
+    hostAddr := flag.String("host", "", "host a networked match on this address, e.g. :7777")
+    joinAddr := flag.String("join", "", "join a networked match at this address, e.g. localhost:7777")
+    clients := flag.Int("clients", 1, "number of players to wait for when hosting (1-4)")
+    curse := flag.String("curse", "", "curse to play when joining (jogo, sukuna, kenjaku, mahito)")
+    recordPath := flag.String("record", "", "record a replay of every game to this file")
+    replayPath := flag.String("replay", "", "play back a replay recorded with --record")
+    serveSpectate := flag.String("serve-spectate", "", "stream games to spectators on this address, e.g. :8080")
+    spectateAddr := flag.String("spectate", "", "watch a game streamed with --serve-spectate, e.g. localhost:8080")
+    botName := flag.String("bot", "", "let a bot play Gojo: greedy, exec:<command> or unix:<socket path>")
+    botOpts := defaultBotOptions()
+    flag.DurationVar(&botOpts.Timeout, "bot-timeout", botOpts.Timeout, "how long to wait for each move of an external bot")
+    flag.StringVar(&botOpts.Fallback, "bot-fallback", botOpts.Fallback, "move used when an external bot is late: keep, up, down, left, right or empty to stop")
+    levelPath := flag.String("level", "", "play this level file instead of the built-in maze, see levels/classic.txt")
+    editPath := flag.String("edit", "", "open this level file in the level editor; it is created when saved")
+    endlessSeed := flag.Int64("endless", -1, "start an endless run on this seed, e.g. to replay someone's run")
+    consoleScript := flag.String("console-script", "", "run developer console commands from this file at startup, e.g. to set up a scenario")
+    logSpec := flag.String("log", os.Getenv("JK_LOG"), "log levels, e.g. debug or ai=debug,audio=info (categories: ai, audio, input, state, assets)")
+    logJSON := flag.String("log-json", os.Getenv("JK_LOG_JSON"), "write the log to this file as JSON lines instead of stderr")
+    flag.Parse()
+
+    closeLog, err := setupLogging(*logSpec, *logJSON)
+    if err != nil {
+        log.Fatalf("Logging: %v", err)
+    }
+    defer closeLog()
+
+    assetOverrideDir = *assetDir
+    config := LoadConfig()
+    Input.SetBindings(config.Bindings)
+    themes := DiscoverThemes()
+
     // First load basic game assets
-    LoadAssets()
+    LoadAssets(findTheme(themes, config.Theme))
     
     // Create the game instance
     game := NewGame()
+    game.config = config
+    game.themes = themes
+
+    if *levelPath != "" {
+        l, err := loadLevel(*levelPath)
+        if err != nil {
+            log.Fatalf("Level: %v", err)
+        }
+        game.useLevel(l)
+    }
+    if *editPath != "" {
+        if err = game.openEditor(*editPath); err != nil {
+            log.Fatalf("Editor: %v", err)
+        }
+    }
+
+    // Networked play skips the intro and waits in the lobby
+    switch {
+    case *hostAddr != "":
+        game.net, err = HostNetSession(*hostAddr, *clients)
+    case *joinAddr != "":
+        game.net, err = JoinNetSession(*joinAddr, *curse)
+    }
+    if err != nil {
+        log.Fatalf("Netplay: %v", err)
+    }
+    if game.net != nil {
+        game.State = StateNetLobby
+    }
+
+    if *recordPath != "" {
+        if game.recorder, err = NewReplayRecorder(*recordPath, mazeHash(levelTemplate)); err != nil {
+            log.Fatalf("Replay: %v", err)
+        }
+    }
+    if *replayPath != "" {
+        if game.replay, err = OpenReplay(*replayPath); err != nil {
+            log.Fatalf("Replay: %v", err)
+        }
+    }
+
+    if *botName != "" {
+        bot, err := NewBot(*botName, botOpts)
+        if err != nil {
+            log.Fatalf("Bot: %v", err)
+        }
+        game.SetBot(bot)
+    }
+
+    if *serveSpectate != "" {
+        if game.spectateServer, err = ServeSpectate(*serveSpectate, ghostNames(game.Ghosts)); err != nil {
+            log.Fatalf("Spectator server: %v", err)
+        }
+    }
+    if *endlessSeed >= 0 {
+        game.startEndless(*endlessSeed)
+    }
+    if *consoleScript != "" {
+        if err := game.RunConsoleScript(*consoleScript); err != nil {
+            log.Fatalf("Console script: %v", err)
+        }
+    }
+
+    if *spectateAddr != "" {
+        game.spectating = Spectate(*spectateAddr, len(game.Ghosts))
+        game.State = StatePlaying
+    }
     
     // Load UI-specific images/gifs for the menu
-    logo := loadImage("assets/jogo.png")
+    logo := Assets.Image("menu.logo")
     
     // Load character GIF frames for menu background
-    characterFrames, err := LoadGIF("assets/gojo.gif")
+    characterFrames, err := Assets.Animation("menu.character")
     if err != nil {
         log.Printf("Failed to load character GIF: %v", err)
         // Continue without the GIF - will show placeholder
@@ -127,21 +245,27 @@ func main() {
     }
     
     // Load background image (this will be the main background)
-    bg := loadImage("assets/cursed_bg.png")
-    if bg == nil {
-        log.Printf("Warning: Background image not found, using gradient background")
-    }
+    bg := Assets.Image("menu.background")
     
     // Set the images in the UI
     // The GIF will now appear as background in the menu area
     game.menuUI.SetImages(logo, characterFrames, bg)
     
     // Set window properties
-    ebiten.SetWindowSize(1200, 800)
+    ebiten.SetWindowSize(logicalWidth, logicalHeight)
     ebiten.SetWindowTitle("Jujutsu Kaisen Pac-Man")
+    ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
     
     // Run the game
-    if err := ebiten.RunGame(game); err != nil {
+    err = ebiten.RunGame(game)
+    game.stopRecording()
+    if closer, ok := game.Player.Controller.(io.Closer); ok {
+        closer.Close()
+    }
+    if game.spectateServer != nil {
+        game.spectateServer.Close()
+    }
+    if err != nil {
         log.Fatal(err)
     }
 }
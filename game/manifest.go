@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const assetManifestFile = "manifest.json"
+
+// AssetFallback describes the placeholder drawn when an image can't be loaded
+type AssetFallback struct {
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Color  [4]uint8 `json:"color"`
+}
+
+// AssetEntry maps one logical asset name to a file under the asset root
+type AssetEntry struct {
+	File     string         `json:"file"`
+	Fallback *AssetFallback `json:"fallback,omitempty"`
+}
+
+// AtlasSpec lists small images that get packed into one shared texture
+type AtlasSpec struct {
+	Name   string   `json:"name"`
+	Size   int      `json:"size"`
+	Images []string `json:"images"`
+}
+
+// AssetManifest is the decoded form of assets/manifest.json
+type AssetManifest struct {
+	Images     map[string]AssetEntry `json:"images"`
+	Animations map[string]AssetEntry `json:"animations"`
+	Music      map[string]AssetEntry `json:"music"`
+	SFX        map[string]AssetEntry `json:"sfx"`
+	Fonts      map[string]AssetEntry `json:"fonts"`
+	Atlases    []AtlasSpec           `json:"atlases"`
+}
+
+// Default placeholder for images without an explicit fallback
+var defaultAssetFallback = AssetFallback{Width: 100, Height: 100, Color: [4]uint8{128, 128, 128, 255}}
+
+func parseAssetManifest(data []byte) (*AssetManifest, error) {
+	m := &AssetManifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid asset manifest: %v", err)
+	}
+	return m, nil
+}
+
+func loadAssetManifest(fsys fs.FS) (*AssetManifest, error) {
+	data, err := fs.ReadFile(fsys, assetManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseAssetManifest(data)
+}
+
+// fallbackFor returns the placeholder settings for an image entry
+func (e AssetEntry) fallbackFor() AssetFallback {
+	if e.Fallback != nil && e.Fallback.Width > 0 && e.Fallback.Height > 0 {
+		return *e.Fallback
+	}
+	return defaultAssetFallback
+}
+
+func (f AssetFallback) rgba() color.RGBA {
+	return color.RGBA{f.Color[0], f.Color[1], f.Color[2], f.Color[3]}
+}
+
+// sections returns every name->entry table in the manifest, keyed by section name
+func (m *AssetManifest) sections() map[string]map[string]AssetEntry {
+	return map[string]map[string]AssetEntry{
+		"images":     m.Images,
+		"animations": m.Animations,
+		"music":      m.Music,
+		"sfx":        m.SFX,
+		"fonts":      m.Fonts,
+	}
+}
+
+// ReferencedFiles returns every file the manifest points at (slash separated, relative to the root)
+func (m *AssetManifest) ReferencedFiles() map[string][]string {
+	refs := make(map[string][]string)
+	for section, entries := range m.sections() {
+		for name, entry := range entries {
+			file := path.Clean(entry.File)
+			refs[file] = append(refs[file], section+"/"+name)
+		}
+	}
+	return refs
+}
+
+// AssetReport is the result of checking a manifest against the files on disk
+type AssetReport struct {
+	Missing []string // "file (used by section/name, ...)"
+	Unused  []string // files present on disk that no entry references
+	Atlas   []string // atlas entries that point at unknown image names
+}
+
+func (r *AssetReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Atlas) == 0
+}
+
+// ValidateAssets compares the manifest in fsys with the files actually present
+func ValidateAssets(fsys fs.FS) (*AssetReport, error) {
+	manifest, err := loadAssetManifest(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]bool)
+	err = fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			present[file] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AssetReport{}
+	refs := manifest.ReferencedFiles()
+	for file, users := range refs {
+		if !present[file] {
+			sort.Strings(users)
+			report.Missing = append(report.Missing, fmt.Sprintf("%s (used by %s)", file, strings.Join(users, ", ")))
+		}
+	}
+	for file := range present {
+		if file == assetManifestFile {
+			continue
+		}
+		if _, ok := refs[file]; !ok {
+			report.Unused = append(report.Unused, file)
+		}
+	}
+	for _, atlas := range manifest.Atlases {
+		for _, name := range atlas.Images {
+			if _, ok := manifest.Images[name]; !ok {
+				report.Atlas = append(report.Atlas, fmt.Sprintf("atlas %s: unknown image %q", atlas.Name, name))
+			}
+		}
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Unused)
+	sort.Strings(report.Atlas)
+	return report, nil
+}
+
+// runValidateAssets implements the "validate-assets" command. Without an
+// argument it checks the assets embedded in the binary; with a directory it
+// checks that directory, e.g. the source tree before a build.
+func runValidateAssets(args []string) int {
+	fsys := embeddedAssetFS()
+	if len(args) > 0 {
+		fsys = os.DirFS(args[0])
+	}
+
+	report, err := ValidateAssets(fsys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "validate-assets: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("Missing files (%d):\n", len(report.Missing))
+	for _, m := range report.Missing {
+		fmt.Printf("  - %s\n", m)
+	}
+	fmt.Printf("Unused files (%d):\n", len(report.Unused))
+	for _, u := range report.Unused {
+		fmt.Printf("  - %s\n", u)
+	}
+	if len(report.Atlas) > 0 {
+		fmt.Printf("Atlas problems (%d):\n", len(report.Atlas))
+		for _, a := range report.Atlas {
+			fmt.Printf("  - %s\n", a)
+		}
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Wall outlines are drawn this far inside the wall tiles, so a one tile thick
+// wall becomes two parallel lines like in the arcade maze.
+const (
+	wallOutlineInset = TileSize / 4
+	wallOutlineWidth = 3
+	wallFillShade    = 0.35 // wall texture brightness under the outline
+)
+
+// whitePixel is the source image for DrawTriangles when stroking paths
+var whitePixel = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(color.White)
+	return img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+}()
+
+// isWallAt treats everything outside the level as open, so the border gets an
+// outline on its outer side too.
+func isWallAt(level [][]int, x, y int) bool {
+	if y < 0 || y >= len(level) || x < 0 || x >= len(level[y]) {
+		return false
+	}
+	return level[y][x] == TileWall
+}
+
+// renderMaze draws the static part of the level (floor, wall texture and wall
+// outlines) into one image, so a frame costs a single DrawImage instead of one
+// per tile. Only walls matter, so it stays valid while pellets are eaten.
+func renderMaze(level [][]int, tileSize int) *ebiten.Image {
+	if len(level) == 0 || len(level[0]) == 0 {
+		return ebiten.NewImage(1, 1)
+	}
+	img := ebiten.NewImage(len(level[0])*tileSize, len(level)*tileSize)
+
+	for y, row := range level {
+		for x, tile := range row {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(x*tileSize), float64(y*tileSize))
+			if tile == TileWall {
+				op.ColorScale.Scale(wallFillShade, wallFillShade, wallFillShade, 1)
+				img.DrawImage(WallImage, op)
+			} else {
+				img.DrawImage(FloorImage, op)
+			}
+		}
+	}
+
+	for y, row := range level {
+		for x := range row {
+			if isWallAt(level, x, y) {
+				strokePath(img, wallOutline(level, x, y, float32(tileSize)), wallOutlineWidth, palette.Wall)
+			}
+		}
+	}
+	return img
+}
+
+// wallOutline builds the outline of one wall tile from its 8-neighbour mask.
+// Each quarter of the tile looks at its horizontal, vertical and diagonal
+// neighbour and becomes an outer corner, a straight edge, an inner corner or
+// nothing. Edges, corners, T-junctions and crosses all fall out of that, and
+// the pieces line up with the ones drawn for the neighbouring tiles.
+func wallOutline(level [][]int, x, y int, size float32) *vector.Path {
+	path := &vector.Path{}
+	half := size / 2
+	cx := float32(x)*size + half
+	cy := float32(y)*size + half
+	r := half - wallOutlineInset // radius of outer corners
+	in := float32(wallOutlineInset)
+
+	for _, q := range [4][2]int{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		dx, dy := float32(q[0]), float32(q[1])
+		horiz := isWallAt(level, x+q[0], y)
+		vert := isWallAt(level, x, y+q[1])
+		diag := isWallAt(level, x+q[0], y+q[1])
+
+		switch {
+		case !horiz && !vert:
+			// Outer corner: quarter circle around the tile center
+			path.MoveTo(cx, cy+dy*r)
+			path.ArcTo(cx+dx*r, cy+dy*r, cx+dx*r, cy, r)
+		case horiz && !vert:
+			// Open above/below: horizontal edge
+			path.MoveTo(cx, cy+dy*r)
+			path.LineTo(cx+dx*half, cy+dy*r)
+		case !horiz && vert:
+			// Open left/right: vertical edge
+			path.MoveTo(cx+dx*r, cy)
+			path.LineTo(cx+dx*r, cy+dy*half)
+		case !diag:
+			// Inner corner: small arc around the open diagonal corner
+			kx, ky := cx+dx*half, cy+dy*half
+			path.MoveTo(kx, ky-dy*in)
+			path.ArcTo(kx-dx*in, ky-dy*in, kx-dx*in, ky, in)
+		}
+	}
+	return path
+}
+
+// strokePath draws the outline of path onto dst
+func strokePath(dst *ebiten.Image, path *vector.Path, width float32, clr color.RGBA) {
+	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+		Width:    width,
+		LineCap:  vector.LineCapRound,
+		LineJoin: vector.LineJoinRound,
+	})
+	r, g, b, a := float32(clr.R)/255, float32(clr.G)/255, float32(clr.B)/255, float32(clr.A)/255
+	for i := range vs {
+		vs[i].SrcX, vs[i].SrcY = 1, 1
+		vs[i].ColorR, vs[i].ColorG, vs[i].ColorB, vs[i].ColorA = r, g, b, a
+	}
+	op := &ebiten.DrawTrianglesOptions{AntiAlias: true}
+	dst.DrawTriangles(vs, is, whitePixel, op)
+}
@@ -4,7 +4,6 @@ import (
 	"image/color"
 	"math"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/font/basicfont"
 	"github.com/hajimehoshi/ebiten/v2/text"
@@ -144,7 +143,7 @@ type NebulaCloud struct {
 func NewUIPage() *UIPage {
 	ui := &UIPage{
 		selectedOption:      0,
-		menuOptions:        []string{"START GAME", "SETTINGS", "GALLERY", "EXIT"},
+		menuOptions:        []string{"START GAME", "ENDLESS", "TIME ATTACK", "PELLET RUSH", "2 PLAYERS", "VERSUS", "EDITOR", "SETTINGS", "GALLERY", "EXIT"},
 		pacmanX:           -150,
 		cursedEnergy:      make([]CursedEnergyParticle, 120),
 		backgroundParticles: make([]BackgroundParticle, 80),
@@ -276,12 +275,12 @@ func (ui *UIPage) Update() error {
 	ui.transitionOffset = math.Sin(ui.animationTime*0.6) * 15
 	
 	// Smooth selection transition
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+	if Input.JustPressed(ActionUp) {
 		ui.selectedOption = (ui.selectedOption - 1 + len(ui.menuOptions)) % len(ui.menuOptions)
 		ui.selectionTransition = 1.0
 		ui.screenShake = 5.0
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+	if Input.JustPressed(ActionDown) {
 		ui.selectedOption = (ui.selectedOption + 1) % len(ui.menuOptions)
 		ui.selectionTransition = 1.0
 		ui.screenShake = 5.0
@@ -867,7 +866,8 @@ func (ui *UIPage) drawEnhancedLogoArea(screen *ebiten.Image) {
 
 func (ui *UIPage) drawEnhancedMenu(screen *ebiten.Image) {
 	menuStartY := 320
-	menuSpacing := 90
+	// Shrink the spacing when more options would push the panel off screen
+	menuSpacing := min(90, (screenHeight-menuStartY-60)/len(ui.menuOptions))
 	menuWidth := 450
 	menuX := screenWidth/2 - menuWidth/2
 	
@@ -1566,7 +1566,7 @@ func (ui *UIPage) GetSelectedOption() int {
 
 // IsEnterPressed checks if enter key was just pressed
 func (ui *UIPage) IsEnterPressed() bool {
-	return inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace)
+	return Input.JustPressed(ActionConfirm)
 }
 
 // SetImages allows setting the images/gifs for the UI with enhanced handling
@@ -0,0 +1,605 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Netplay is deterministic lockstep over TCP. Every peer runs the full
+// simulation; only inputs travel. Clients send their input for a frame to the
+// host, the host adds its own, and once a frame is complete it broadcasts the
+// inputs of every player back. A frame is simulated only when its inputs are
+// known, so all peers step through identical frames. Peers periodically send
+// a hash of their state and the host reports a desync when one differs.
+//
+// The host always plays Gojo ("player"); each client drives one of the curses.
+
+const (
+	netProtocolVersion = 1
+	netInputDelay      = 3  // frames between sampling an input and simulating it
+	netHashInterval    = 30 // frames between state hash checks
+	netMaxCatchUp      = 3  // frames simulated per tick when behind
+	netDialTimeout     = 5 * time.Second
+	netWriteTimeout    = 2 * time.Second
+)
+
+// Curses a client can drive, in the order free ones are handed out
+var netCurses = []string{"jogo", "sukuna", "kenjaku", "mahito"}
+
+// netMessage is one JSON line on the wire. Type selects which fields are used.
+type netMessage struct {
+	Type    string            `json:"type"`
+	Version int               `json:"version,omitempty"`
+	Frame   int               `json:"frame,omitempty"`
+	Mask    uint16            `json:"mask,omitempty"`
+	Hash    uint64            `json:"hash,omitempty"`
+	Seed    int64             `json:"seed,omitempty"`
+	Curse   string            `json:"curse,omitempty"`
+	Reason  string            `json:"reason,omitempty"`
+	Roster  []string          `json:"roster,omitempty"`
+	Inputs  map[string]uint16 `json:"inputs,omitempty"`
+	Left    []string          `json:"left,omitempty"`
+}
+
+// netPeer is one TCP connection. Reads happen on a goroutine that forwards
+// to the session's event channel; writes happen on the game goroutine.
+type netPeer struct {
+	conn net.Conn
+	enc  *json.Encoder
+	name string // character the peer controls, "" until welcomed
+}
+
+type netEvent struct {
+	peer *netPeer
+	msg  netMessage
+	err  error // set once when the connection closes
+}
+
+func newNetPeer(conn net.Conn, events chan<- netEvent) *netPeer {
+	p := &netPeer{conn: conn, enc: json.NewEncoder(conn)}
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var msg netMessage
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				events <- netEvent{peer: p, err: fmt.Errorf("bad message: %v", err)}
+				return
+			}
+			events <- netEvent{peer: p, msg: msg}
+		}
+		err := scanner.Err()
+		if err == nil {
+			err = errors.New("connection closed")
+		}
+		events <- netEvent{peer: p, err: err}
+	}()
+	return p
+}
+
+func (p *netPeer) send(msg netMessage) error {
+	p.conn.SetWriteDeadline(time.Now().Add(netWriteTimeout))
+	return p.enc.Encode(msg)
+}
+
+// NetSession is the state of a hosted or joined game
+type NetSession struct {
+	host     bool
+	addr     string
+	name     string // character controlled on this machine
+	expected int    // host: number of clients to wait for
+
+	listener net.Listener
+	peers    []*netPeer // host: all clients; client: the host
+	events   chan netEvent
+
+	started bool
+	roster  []string               // characters controlled by humans
+	inputs  map[string]*InputState // per character, fed from frame messages
+
+	sentFrame      int                       // next frame to send local input for
+	simFrame       int                       // next frame to simulate
+	broadcastFrame int                       // host: next frame to complete and broadcast
+	pending        map[int]map[string]uint16 // host: inputs received per frame
+	ready          map[int]netMessage        // complete frames waiting to be simulated
+	leaving        []string                  // host: players to drop in the next broadcast frame
+	active         map[string]bool           // host: characters still connected
+
+	hashes      map[int]map[string]uint64 // host: state hashes per frame and player
+	desyncFrame int                       // first frame a hash differed, -1 if none
+	stalled     int                       // ticks without a frame to simulate
+	status      string
+	err         error // connection lost
+}
+
+func newNetSession(host bool, addr string) *NetSession {
+	return &NetSession{
+		host:        host,
+		addr:        addr,
+		events:      make(chan netEvent, 256),
+		inputs:      map[string]*InputState{},
+		pending:     map[int]map[string]uint16{},
+		ready:       map[int]netMessage{},
+		active:      map[string]bool{},
+		hashes:      map[int]map[string]uint64{},
+		desyncFrame: -1,
+	}
+}
+
+// HostNetSession listens on addr and waits for the given number of clients
+func HostNetSession(addr string, clients int) (*NetSession, error) {
+	if clients < 1 || clients > len(netCurses) {
+		return nil, fmt.Errorf("clients must be between 1 and %d", len(netCurses))
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := newNetSession(true, ln.Addr().String())
+	s.name = "player"
+	s.expected = clients
+	s.listener = ln
+	s.status = fmt.Sprintf("HOSTING ON %s", s.addr)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			newNetPeer(conn, s.events)
+		}
+	}()
+	return s, nil
+}
+
+// JoinNetSession connects to a host and asks to drive the given curse
+// ("" takes any free one)
+func JoinNetSession(addr, curse string) (*NetSession, error) {
+	conn, err := net.DialTimeout("tcp", addr, netDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	s := newNetSession(false, addr)
+	peer := newNetPeer(conn, s.events)
+	s.peers = []*netPeer{peer}
+	s.status = "CONNECTED, WAITING FOR WELCOME"
+	if err := peer.send(netMessage{Type: "hello", Version: netProtocolVersion, Curse: curse}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *NetSession) Close() {
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for _, p := range s.peers {
+		p.conn.Close()
+	}
+}
+
+// Failed returns the error that ended the session, if any
+func (s *NetSession) Failed() error {
+	return s.err
+}
+
+func (s *NetSession) fail(err error) {
+	if s.err == nil {
+		s.err = err
+		s.status = "CONNECTION LOST: " + err.Error()
+		stateLog.Warn("connection lost", "err", err)
+	}
+}
+
+// broadcast sends msg to every welcomed client
+func (s *NetSession) broadcast(msg netMessage) {
+	for _, p := range s.peers {
+		if p.name == "" {
+			continue
+		}
+		if err := p.send(msg); err != nil {
+			p.conn.Close() // the reader reports the disconnect
+		}
+	}
+}
+
+// hostHandle processes one event on the host
+func (s *NetSession) hostHandle(g *Game, ev netEvent) {
+	p := ev.peer
+	if ev.err != nil {
+		s.removePeer(p)
+		if p.name != "" {
+			stateLog.Info("peer left", "curse", p.name, "err", ev.err)
+			if s.started && s.active[p.name] {
+				delete(s.active, p.name)
+				s.leaving = append(s.leaving, p.name)
+			}
+		}
+		return
+	}
+
+	switch ev.msg.Type {
+	case "hello":
+		if reason := s.admit(p, ev.msg); reason != "" {
+			p.send(netMessage{Type: "reject", Reason: reason})
+			p.conn.Close()
+			return
+		}
+		p.send(netMessage{Type: "welcome", Curse: p.name})
+		stateLog.Info("peer joined", "addr", p.conn.RemoteAddr(), "curse", p.name)
+		if len(s.peers) == s.expected {
+			s.start(g, time.Now().UnixNano())
+		}
+	case "input":
+		if p.name == "" || ev.msg.Frame < s.broadcastFrame {
+			return
+		}
+		s.pendingFrame(ev.msg.Frame)[p.name] = ev.msg.Mask
+	case "hash":
+		if p.name != "" {
+			s.recordHash(ev.msg.Frame, p.name, ev.msg.Hash)
+		}
+	}
+}
+
+// admit assigns a curse to a new client, or returns why it can't join
+func (s *NetSession) admit(p *netPeer, hello netMessage) string {
+	if hello.Version != netProtocolVersion {
+		return fmt.Sprintf("protocol version %d, host has %d", hello.Version, netProtocolVersion)
+	}
+	if s.started {
+		return "game already started"
+	}
+	taken := map[string]bool{}
+	for _, other := range s.peers {
+		taken[other.name] = true
+	}
+	name := ""
+	if hello.Curse != "" {
+		for _, c := range netCurses {
+			if c == hello.Curse && !taken[c] {
+				name = c
+			}
+		}
+	}
+	for _, c := range netCurses {
+		if name == "" && !taken[c] {
+			name = c
+		}
+	}
+	if name == "" {
+		return "game is full"
+	}
+	p.name = name
+	s.peers = append(s.peers, p)
+	return ""
+}
+
+func (s *NetSession) removePeer(p *netPeer) {
+	for i, other := range s.peers {
+		if other == p {
+			s.peers = append(s.peers[:i], s.peers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *NetSession) pendingFrame(frame int) map[string]uint16 {
+	m, ok := s.pending[frame]
+	if !ok {
+		m = map[string]uint16{}
+		s.pending[frame] = m
+	}
+	return m
+}
+
+// clientHandle processes one event on a client
+func (s *NetSession) clientHandle(g *Game, ev netEvent) {
+	if ev.err != nil {
+		s.fail(ev.err)
+		return
+	}
+	switch ev.msg.Type {
+	case "welcome":
+		s.name = ev.msg.Curse
+		s.status = fmt.Sprintf("JOINED AS %s, WAITING FOR PLAYERS", CharacterName(s.name))
+	case "reject":
+		s.fail(fmt.Errorf("rejected by host: %s", ev.msg.Reason))
+	case "start":
+		s.roster = ev.msg.Roster
+		s.start(g, ev.msg.Seed)
+	case "frame":
+		s.ready[ev.msg.Frame] = ev.msg
+	case "desync":
+		if s.desyncFrame < 0 {
+			s.desyncFrame = ev.msg.Frame
+		}
+	}
+}
+
+// start begins the match on this peer. The host picks the seed and roster
+// and tells the clients.
+func (s *NetSession) start(g *Game, seed int64) {
+	if s.host {
+		s.roster = []string{"player"}
+		for _, p := range s.peers {
+			s.roster = append(s.roster, p.name)
+			s.active[p.name] = true
+		}
+		s.active["player"] = true
+		sort.Strings(s.roster[1:])
+		s.broadcast(netMessage{Type: "start", Seed: seed, Roster: s.roster})
+	}
+	s.started = true
+	s.status = ""
+	stateLog.Info("match starting", "seed", seed, "players", s.roster)
+
+	// The first frames have no input yet on any peer
+	for f := 0; f < netInputDelay; f++ {
+		s.ready[f] = netMessage{Type: "frame", Frame: f}
+	}
+	s.sentFrame = netInputDelay
+	s.broadcastFrame = netInputDelay
+
+	simRand = rand.New(rand.NewSource(seed))
+	g.resetGame()
+	g.powerPelletActive = false
+	g.powerPelletTimer = 0
+	g.globalTimer = 0
+	for _, name := range s.roster {
+		in := NewPlayerInputState(nil, -1)
+		s.inputs[name] = in
+		if name == "player" {
+			g.Player.Input = in
+			continue
+		}
+		for _, ghost := range g.Ghosts {
+			if ghost.GhostType == name {
+				ghost.Controller = &HumanGhostController{Input: in}
+			}
+		}
+	}
+	g.State = StateRoundReady
+	g.ShowRoundReady = true
+	g.RoundReadyTimer = 0
+}
+
+// hostCollect broadcasts every frame whose inputs are all in
+func (s *NetSession) hostCollect() {
+	for {
+		inputs := s.pending[s.broadcastFrame]
+		for name := range s.active {
+			if _, ok := inputs[name]; !ok {
+				return
+			}
+		}
+		msg := netMessage{Type: "frame", Frame: s.broadcastFrame, Inputs: inputs, Left: s.leaving}
+		s.leaving = nil
+		delete(s.pending, s.broadcastFrame)
+		s.broadcast(msg)
+		s.ready[msg.Frame] = msg
+		s.broadcastFrame++
+	}
+}
+
+// recordHash stores a peer's state hash and compares it with the host's.
+// Either side may report a frame first.
+func (s *NetSession) recordHash(frame int, who string, hash uint64) {
+	m, ok := s.hashes[frame]
+	if !ok {
+		if frame < s.simFrame-netHashInterval*20 {
+			return // too old to compare
+		}
+		m = map[string]uint64{}
+		s.hashes[frame] = m
+	}
+	m[who] = hash
+	own, ok := m["player"]
+	if !ok || s.desyncFrame >= 0 {
+		return
+	}
+	for name, h := range m {
+		if h != own {
+			s.desyncFrame = frame
+			stateLog.Warn("desync", "frame", frame, "peer", name, "hash", fmt.Sprintf("%016x", h), "host_hash", fmt.Sprintf("%016x", own))
+			s.broadcast(netMessage{Type: "desync", Frame: frame})
+			return
+		}
+	}
+}
+
+// updateNetplay replaces the normal per-tick update while a session is open
+func (g *Game) updateNetplay() error {
+	s := g.net
+	for {
+		select {
+		case ev := <-s.events:
+			if s.host {
+				s.hostHandle(g, ev)
+			} else {
+				s.clientHandle(g, ev)
+			}
+			continue
+		default:
+		}
+		break
+	}
+
+	// Leaving: after game over, or at any time once the connection is gone
+	if g.State == StateGameOver || s.err != nil {
+		if Input.JustPressed(ActionConfirm) || Input.JustPressed(ActionBack) {
+			g.leaveNetplay()
+		}
+		return nil
+	}
+	if !s.started {
+		if Input.JustPressed(ActionBack) {
+			g.leaveNetplay()
+		}
+		return nil
+	}
+
+	// Send this tick's input for a frame netInputDelay ahead
+	if s.sentFrame < s.simFrame+netInputDelay+1 {
+		mask := Input.Mask()
+		if s.host {
+			s.pendingFrame(s.sentFrame)["player"] = mask
+		} else if err := s.peers[0].send(netMessage{Type: "input", Frame: s.sentFrame, Mask: mask}); err != nil {
+			s.fail(err)
+			return nil
+		}
+		s.sentFrame++
+	}
+	if s.host {
+		s.hostCollect()
+	}
+
+	// Simulate every frame that is ready, up to a small catch-up limit
+	stepped := 0
+	for stepped < netMaxCatchUp {
+		msg, ok := s.ready[s.simFrame]
+		if !ok {
+			break
+		}
+		delete(s.ready, s.simFrame)
+		g.applyNetFrame(msg)
+		if err := g.step(); err != nil {
+			return err
+		}
+		g.frameDone()
+		if s.simFrame%netHashInterval == 0 {
+			hash := g.stateHash()
+			if s.host {
+				s.recordHash(s.simFrame, "player", hash)
+				delete(s.hashes, s.simFrame-netHashInterval*20)
+			} else if err := s.peers[0].send(netMessage{Type: "hash", Frame: s.simFrame, Hash: hash}); err != nil {
+				s.fail(err)
+			}
+		}
+		s.simFrame++
+		stepped++
+		if g.State == StateGameOver {
+			break
+		}
+	}
+	if stepped == 0 {
+		s.stalled++
+	} else {
+		s.stalled = 0
+	}
+	return nil
+}
+
+// applyNetFrame feeds a frame's inputs to the players it names
+func (g *Game) applyNetFrame(msg netMessage) {
+	s := g.net
+	for _, name := range msg.Left {
+		if in, ok := s.inputs[name]; ok {
+			in.SetMask(0)
+		}
+		for _, ghost := range g.Ghosts {
+			if ghost.GhostType == name {
+				ghost.Controller = nil // back to the AI
+			}
+		}
+		delete(s.inputs, name)
+	}
+	for name, in := range s.inputs {
+		in.SetMask(msg.Inputs[name])
+	}
+}
+
+func (g *Game) leaveNetplay() {
+	g.net.Close()
+	g.net = nil
+	g.Player.Input = nil
+	for _, ghost := range g.Ghosts {
+		ghost.Controller = nil
+	}
+	g.resetGame()
+	g.State = StateMenu
+}
+
+// stateHash summarizes the simulation for desync detection
+func (g *Game) stateHash() uint64 {
+	h := fnv.New64a()
+	put := func(v uint64) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], v)
+		h.Write(b[:])
+	}
+	putF := func(f float64) { put(math.Float64bits(f)) }
+
+	for _, row := range level {
+		for _, tile := range row {
+			put(uint64(tile))
+		}
+	}
+	putF(g.Player.X)
+	putF(g.Player.Y)
+	put(uint64(g.Player.Score))
+	put(uint64(g.lives))
+	put(uint64(g.RoundNumber))
+	put(uint64(g.State))
+	put(uint64(g.powerPelletTimer))
+	for _, ghost := range g.Ghosts {
+		putF(ghost.X)
+		putF(ghost.Y)
+		put(uint64(ghost.Mode))
+		h.Write([]byte(ghost.Direction))
+	}
+	return h.Sum64()
+}
+
+func (g *Game) drawNetLobby(screen *ebiten.Image) {
+	s := g.net
+	cx := logicalWidth / 2
+	title := overlayTitle
+	title.Size = TextLarge
+	if s.host {
+		DrawText(screen, "HOSTING MATCH", cx, 200, title, g.globalTimer)
+		DrawText(screen, s.status, cx, 280, overlayText, g.globalTimer)
+		DrawText(screen, fmt.Sprintf("PLAYERS %d/%d", len(s.peers)+1, s.expected+1), cx, 320, overlayText, g.globalTimer)
+		line := 380
+		DrawText(screen, "HOST - "+CharacterName("player"), cx, line, overlayHint, g.globalTimer)
+		for _, p := range s.peers {
+			line += 30
+			DrawText(screen, fmt.Sprintf("%s - %s", p.conn.RemoteAddr(), CharacterName(p.name)), cx, line, overlayHint, g.globalTimer)
+		}
+	} else {
+		DrawText(screen, "JOINING MATCH", cx, 200, title, g.globalTimer)
+		DrawText(screen, s.addr, cx, 280, overlayText, g.globalTimer)
+		DrawText(screen, s.status, cx, 320, overlayText, g.globalTimer)
+	}
+	DrawText(screen, "ESC TO LEAVE", cx, 600, overlayHint, g.globalTimer)
+}
+
+// drawNetStatus shows problems on top of the running match
+func (g *Game) drawNetStatus(screen *ebiten.Image) {
+	s := g.net
+	warn := overlayText
+	warn.Color = color.RGBA{255, 60, 60, 255}
+	y := playfieldRect().Min.Y + 10
+	switch {
+	case s.err != nil:
+		DrawText(screen, s.status, logicalWidth/2, y, warn, g.globalTimer)
+		DrawText(screen, "PRESS SPACE TO RETURN TO MENU", logicalWidth/2, y+30, overlayHint, g.globalTimer)
+	case s.desyncFrame >= 0:
+		DrawText(screen, fmt.Sprintf("DESYNC AT FRAME %d", s.desyncFrame), logicalWidth/2, y, warn, g.globalTimer)
+	case s.stalled > 30:
+		DrawText(screen, "WAITING FOR PLAYERS...", logicalWidth/2, y, overlayText, g.globalTimer)
+	}
+}
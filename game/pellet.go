@@ -5,7 +5,6 @@ import (
     //"github.com/hajimehoshi/ebiten/v2/ebitenutil"
     "math"
 
-    "image/color"
     
 )
 
@@ -65,9 +64,9 @@ func createRegularPelletImage() *ebiten.Image {
             dy := float64(y - 4)
             dist := math.Hypot(dx, dy)
             if dist < 2 {
-                img.Set(x, y, color.RGBA{255, 0, 0, 255}) // Core red
+                img.Set(x, y, palette.PelletCore) // Core
             } else if dist < 3.5 {
-                img.Set(x, y, color.RGBA{255, 0, 0, 128}) // Outer glow
+                img.Set(x, y, palette.PelletGlow) // Outer glow
             }
         }
     }
@@ -81,7 +80,7 @@ func createPowerPelletImage() *ebiten.Image {
             dy := float64(y - 5)
             dist := math.Hypot(dx, dy)
             if dist > 3.2 && dist < 4.8 {
-                img.Set(x, y, color.RGBA{128, 0, 255, 220}) // Hollow purple ring
+                img.Set(x, y, palette.PowerPellet) // Hollow purple ring
             }
         }
     }
@@ -3,13 +3,9 @@ package main
 import (
     // "image"
     // "log"
-    "fmt"
     "github.com/hajimehoshi/ebiten/v2"
 	//"github.com/hajimehoshi/ebiten/v2/inpututil"
-	 
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 
-	
 )
 
 
@@ -22,15 +18,46 @@ type Player struct {
     Height int
     Score  int
     Size   int
+    Input  *InputState // nil reads the shared Input
+
+    // A bot playing instead of the keyboard, see bot.go
+    Controller Controller
+    Observe    func() *Observation
 }
 
-func NewPlayer(x, y float64, spritePath string) *Player {
-    img, _, err := ebitenutil.NewImageFromFile(spritePath)
-    if err != nil {
-        panic(err)
+// input returns the controls this player reads
+func (p *Player) input() *InputState {
+    if p.Input != nil {
+        return p.Input
     }
+    return Input
+}
 
-    w, h := img.Size()
+// steering returns what Update checks for each direction this frame: the
+// held keys, or the single direction the bot chose
+func (p *Player) steering() func(Action) bool {
+    if p.Controller == nil || p.Observe == nil {
+        return p.input().Pressed
+    }
+    want := p.Controller.Act(p.Observe())
+    return func(a Action) bool {
+        for _, s := range steerActions {
+            if s.action == a {
+                return s.direction == want
+            }
+        }
+        return false
+    }
+}
+
+func NewPlayer(x, y float64, spriteName string) *Player {
+    // The player is a tile in size whatever the theme's sprite is, which
+    // Draw scales to fit. Headless games run without assets.
+    var img *ebiten.Image
+    w, h := TileSize, TileSize
+    if Assets != nil {
+        img = Assets.Image(spriteName)
+    }
 
     return &Player{
         X:         x,
@@ -47,6 +74,9 @@ func NewPlayer(x, y float64, spritePath string) *Player {
 
 func (p *Player) Draw(screen *ebiten.Image){
     op := &ebiten.DrawImageOptions{}
+    // Scale factor to make image exactly Width × Height
+    b := p.Image.Bounds()
+    op.GeoM.Scale(float64(p.Width)/float64(b.Dx()), float64(p.Height)/float64(b.Dy()))
     op.GeoM.Translate(p.X, p.Y)
     screen.DrawImage(p.Image, op)
         
@@ -56,26 +86,29 @@ func (p *Player) Draw(screen *ebiten.Image){
 
 func (p *Player) Update(level [][]int, TileSize int) {
     nextX, nextY := p.X, p.Y
+    pressed := p.steering()
 
-    if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+    if pressed(ActionRight) {
         nextX += p.Speed
         p.Direction = "right"
     }
-    if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+    if pressed(ActionLeft) {
         nextX -= p.Speed
         p.Direction = "left"
     }
-    if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+    if pressed(ActionUp) {
         nextY -= p.Speed
         p.Direction = "up"
     }
-    if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+    if pressed(ActionDown) {
         nextY += p.Speed
         p.Direction = "down"
     }
 
     //  collision check
-    if !isWallCollidingStrict(level, nextX, p.Y, p.Width, TileSize) {
+    if x, ok := tunnelMove(level, nextX, p.Y, p.Width, TileSize); ok {
+        p.X = x
+    } else if !isWallCollidingStrict(level, nextX, p.Y, p.Width, TileSize) {
         p.X = nextX
     }
     if !isWallCollidingStrict(level, p.X, nextY, p.Height, TileSize) {
@@ -88,9 +121,8 @@ func (p *Player) Update(level [][]int, TileSize int) {
     if level[gridY][gridX]== TilePellet{
         level[gridY][gridX] = TileEmpty
         p.Score++
-        fmt.Println("Pellet eaten! Score:", p.Score)
+        stateLog.Debug("pellet eaten", "score", p.Score)
     }
 
-    fmt.Println("Player pos:", p.X, p.Y)
 }
 
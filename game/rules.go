@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Ruleset is what a one-player game is played for. updateGame asks it when
+// the round is cleared and when the game is over, instead of checking the
+// pellets and lives itself. The classic rules go round after round until
+// the lives run out; the others end on a clear or a clock.
+type Ruleset interface {
+	Name() string
+	// Start is called once the maze and score are reset for a new game
+	Start(g *Game)
+	// Update runs on every frame of play, before the checks
+	Update(g *Game)
+	// Over reports whether the game has ended
+	Over(g *Game) bool
+	// Cleared reports whether the round is done and the next one starts
+	Cleared(g *Game) bool
+	// Ghosts reports whether the curses are in play at all
+	Ghosts() bool
+	// Finish is called when the game ends, to keep any record it set
+	Finish(g *Game)
+	// DrawHUD adds to the bottom HUD strip
+	DrawHUD(g *Game, screen *ebiten.Image)
+	// DrawSummary fills the game over screen, or reports false to leave
+	// the usual one
+	DrawSummary(g *Game, screen *ebiten.Image, cx, cy int) bool
+}
+
+// ruleset is the rules of the game in progress
+func (g *Game) ruleset() Ruleset {
+	if g.rules == nil {
+		return ClassicRules{}
+	}
+	return g.rules
+}
+
+// startRuleset starts a one-player game under r
+func (g *Game) startRuleset(r Ruleset) {
+	g.startGame()
+	g.rules = r
+	stateLog.Info("starting game", "rules", r.Name())
+	r.Start(g)
+}
+
+// ClassicRules: clear the maze for the next round, until the lives run out
+type ClassicRules struct{}
+
+func (ClassicRules) Name() string                 { return "classic" }
+func (ClassicRules) Start(g *Game)                {}
+func (ClassicRules) Update(g *Game)               {}
+func (ClassicRules) Over(g *Game) bool            { return g.lives <= 0 }
+func (ClassicRules) Cleared(g *Game) bool         { return g.pelletCount <= 0 }
+func (ClassicRules) Ghosts() bool                 { return true }
+func (ClassicRules) Finish(g *Game)               { g.saveHighScore() }
+func (ClassicRules) DrawHUD(*Game, *ebiten.Image) {}
+func (ClassicRules) DrawSummary(*Game, *ebiten.Image, int, int) bool {
+	return false
+}
+
+// formatFrames shows a frame count as minutes, seconds and hundredths
+func formatFrames(frames int) string {
+	return fmt.Sprintf("%d:%05.2f", frames/3600, float64(frames%3600)/60)
+}
+
+// Time attack
+
+// quadrantNames label the quarters of the maze for the split timer
+var quadrantNames = [4]string{"NW", "NE", "SW", "SE"}
+
+// TimeAttack clears one maze as fast as possible. Each quarter of the maze
+// gets a split time when its last pellet goes. Practice runs leave the
+// curses out and don't count for the best time.
+type TimeAttack struct {
+	ClassicRules
+	Practice bool
+
+	frames int
+	splits [4]int // frame each quarter was cleared, 0 while it has pellets
+	done   bool   // the maze was cleared
+	best   bool   // the run set a new best time
+}
+
+func (t *TimeAttack) Name() string {
+	if t.Practice {
+		return "time attack practice"
+	}
+	return "time attack"
+}
+
+func (t *TimeAttack) Start(g *Game) {
+	*t = TimeAttack{Practice: t.Practice}
+	// Quarters without pellets count as cleared from the start
+	for q, n := range quadrantPellets(level) {
+		if n == 0 {
+			t.splits[q] = -1
+		}
+	}
+}
+
+func (t *TimeAttack) Update(g *Game) {
+	t.frames++
+	t.done = true
+	for q, n := range quadrantPellets(level) {
+		if n == 0 && t.splits[q] == 0 {
+			t.splits[q] = t.frames
+		}
+		t.done = t.done && n == 0
+	}
+}
+
+func (t *TimeAttack) Over(g *Game) bool    { return t.done || g.lives <= 0 }
+func (t *TimeAttack) Cleared(g *Game) bool { return false }
+func (t *TimeAttack) Ghosts() bool         { return !t.Practice }
+
+func (t *TimeAttack) Finish(g *Game) {
+	if !t.done || t.Practice || g.config == nil {
+		return
+	}
+	if g.config.TimeAttackBest > 0 && t.frames >= g.config.TimeAttackBest {
+		return
+	}
+	t.best = true
+	g.config.TimeAttackBest = t.frames
+	if err := g.config.Save(); err != nil {
+		stateLog.Error("failed to save time attack best", "err", err)
+	}
+}
+
+// splitLine lists the quarters' split times
+func (t *TimeAttack) splitLine() string {
+	parts := make([]string, 4)
+	for q, f := range t.splits {
+		switch {
+		case f < 0:
+			parts[q] = quadrantNames[q] + " -"
+		case f == 0:
+			parts[q] = quadrantNames[q] + " --:--"
+		default:
+			parts[q] = quadrantNames[q] + " " + formatFrames(f)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+func (t *TimeAttack) DrawHUD(g *Game, screen *ebiten.Image) {
+	style := hudLabelStyle
+	style.Align = AlignCenter
+	y := playfieldRect().Max.Y
+	line := "TIME " + formatFrames(t.frames)
+	if t.Practice {
+		line += "  PRACTICE"
+	} else if g.config != nil && g.config.TimeAttackBest > 0 {
+		line += "  BEST " + formatFrames(g.config.TimeAttackBest)
+	}
+	DrawText(screen, line, logicalWidth/2, y+8, style, g.globalTimer)
+	style.Color = color.RGBA{200, 200, 255, 255}
+	DrawText(screen, t.splitLine(), logicalWidth/2, y+28, style, g.globalTimer)
+}
+
+func (t *TimeAttack) DrawSummary(g *Game, screen *ebiten.Image, cx, cy int) bool {
+	title := overlayTitle
+	if t.done {
+		DrawText(screen, "CLEARED", cx, cy-110, title, g.globalTimer)
+		DrawText(screen, "TIME "+formatFrames(t.frames), cx, cy-40, overlayText, g.globalTimer)
+	} else {
+		title.Color = color.RGBA{255, 50, 50, 255}
+		DrawText(screen, "GAME OVER", cx, cy-110, title, g.globalTimer)
+	}
+	splits := overlayHint
+	splits.Pulse = 0
+	DrawText(screen, t.splitLine(), cx, cy, splits, g.globalTimer)
+	if t.best {
+		record := overlayText
+		record.Color = color.RGBA{255, 215, 0, 255}
+		record.Pulse = 0.2
+		DrawText(screen, "NEW BEST TIME!", cx, cy+40, record, g.globalTimer)
+	}
+	DrawText(screen, "PRESS SPACE TO RETURN TO MENU", cx, cy+80, overlayHint, g.globalTimer)
+	return true
+}
+
+// quadrantPellets counts the pellets left in each quarter of the maze
+func quadrantPellets(level [][]int) [4]int {
+	var counts [4]int
+	h := len(level)
+	for y, row := range level {
+		for x, tile := range row {
+			if tile != TilePellet && tile != TilePowerPellet {
+				continue
+			}
+			q := 0
+			if x >= len(row)/2 {
+				q++
+			}
+			if y >= h/2 {
+				q += 2
+			}
+			counts[q]++
+		}
+	}
+	return counts
+}
+
+// Pellet rush
+
+const pelletRushFrames = 3 * 60 * 60 // three minutes
+
+// PelletRush eats as many pellets as possible before the clock runs out.
+// A cleared maze fills up again straight away.
+type PelletRush struct {
+	ClassicRules
+
+	left      int // frames on the clock
+	eaten     int
+	lastCount int // pellets in the maze on the previous frame
+	best      bool
+}
+
+func (r *PelletRush) Name() string { return "pellet rush" }
+
+func (r *PelletRush) Start(g *Game) {
+	*r = PelletRush{left: pelletRushFrames, lastCount: countPelletsIn(level)}
+}
+
+// Update counts what is left in the maze rather than trusting pelletCount,
+// which misses the pellets Player.Update eats by itself
+func (r *PelletRush) Update(g *Game) {
+	r.left--
+	left := countPelletsIn(level)
+	r.eaten += max(0, r.lastCount-left)
+	if left == 0 {
+		g.refillMaze()
+		left = g.pelletCount
+		if g.AudioSystem != nil {
+			g.AudioSystem.PlaySFX("round_complete")
+		}
+	}
+	r.lastCount = left
+}
+
+func (r *PelletRush) Over(g *Game) bool    { return r.left <= 0 || g.lives <= 0 }
+func (r *PelletRush) Cleared(g *Game) bool { return false }
+
+func (r *PelletRush) Finish(g *Game) {
+	if g.config == nil || r.eaten <= g.config.PelletRushBest {
+		return
+	}
+	r.best = true
+	g.config.PelletRushBest = r.eaten
+	if err := g.config.Save(); err != nil {
+		stateLog.Error("failed to save pellet rush best", "err", err)
+	}
+}
+
+func (r *PelletRush) DrawHUD(g *Game, screen *ebiten.Image) {
+	style := hudLabelStyle
+	style.Align = AlignCenter
+	if r.left < 10*60 && g.globalTimer/15%2 == 0 {
+		style.Color = color.RGBA{255, 255, 255, 255}
+	}
+	line := fmt.Sprintf("TIME %s  PELLETS %d", formatFrames(max(0, r.left)), r.eaten)
+	if g.config != nil && g.config.PelletRushBest > 0 {
+		line += fmt.Sprintf("  BEST %d", g.config.PelletRushBest)
+	}
+	DrawText(screen, line, logicalWidth/2, playfieldRect().Max.Y+18, style, g.globalTimer)
+}
+
+func (r *PelletRush) DrawSummary(g *Game, screen *ebiten.Image, cx, cy int) bool {
+	title := "TIME UP"
+	if r.left > 0 {
+		title = "GAME OVER"
+	}
+	DrawText(screen, title, cx, cy-110, overlayTitle, g.globalTimer)
+	DrawText(screen, fmt.Sprintf("%d PELLETS  %d PTS", r.eaten, g.Player.Score), cx, cy-30, overlayText, g.globalTimer)
+	if r.best {
+		record := overlayText
+		record.Color = color.RGBA{255, 215, 0, 255}
+		record.Pulse = 0.2
+		DrawText(screen, "NEW BEST!", cx, cy+20, record, g.globalTimer)
+	}
+	DrawText(screen, "PRESS SPACE TO RETURN TO MENU", cx, cy+80, overlayHint, g.globalTimer)
+	return true
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Save files and replays are snapshots on disk (see snapshot.go). A save file
+// is one full snapshot; a replay is a stream of snapshots, one per frame,
+// each a delta against the one before with a full keyframe every
+// replayKeyframeInterval frames. Both start with their magic and the
+// mazeHash of the maze they were taken in, since snapshots only hold the
+// pellets and can't be put back into a different maze.
+
+var (
+	saveMagic   = []byte("JKSAVE")
+	replayMagic = []byte("JKREPLAY")
+)
+
+const (
+	replayKeyframeInterval = 300
+	maxReplayRecord        = 1 << 20
+)
+
+var errOtherMaze = errors.New("it was made in a different maze")
+
+func savePath() string {
+	return filepath.Join(configDir(), "save.bin")
+}
+
+// mazeHash identifies a maze by its size and starting tiles
+func mazeHash(tiles [][]int) uint64 {
+	h := fnv.New64a()
+	var buf [binary.MaxVarintLen64]byte
+	h.Write(buf[:binary.PutUvarint(buf[:], uint64(len(tiles)))])
+	for _, row := range tiles {
+		h.Write(buf[:binary.PutUvarint(buf[:], uint64(len(row)))])
+		for _, tile := range row {
+			h.Write(buf[:binary.PutVarint(buf[:], int64(tile))])
+		}
+	}
+	return h.Sum64()
+}
+
+// snapshotsUnsupported reports why the game in progress can't be saved or
+// recorded, or nil if it can. A snapshot holds the classic one-player game:
+// not the other peers of a networked match, the board of the player
+// waiting their turn, the boss, the generated maze of an endless run or the
+// clocks and records of the other rulesets.
+func (g *Game) snapshotsUnsupported() error {
+	switch {
+	case g.net != nil:
+		return errors.New("not available in networked matches")
+	case g.twoPlayer:
+		return errors.New("not available in two-player games")
+	case g.boss != nil:
+		return errors.New("not available during a boss round")
+	case g.endless != nil:
+		return errors.New("not available in endless runs")
+	case g.ruleset().Name() != (ClassicRules{}).Name():
+		return fmt.Errorf("not available in %s", g.ruleset().Name())
+	}
+	return nil
+}
+
+// SaveGame writes the current game to path
+func (g *Game) SaveGame(path string) error {
+	if err := g.snapshotsUnsupported(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data := binary.LittleEndian.AppendUint64(append([]byte(nil), saveMagic...), mazeHash(levelTemplate))
+	data = AppendSnapshot(data, nil, g.Snapshot())
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadGame restores a game written by SaveGame in the same maze
+func (g *Game) LoadGame(path string) error {
+	if err := g.snapshotsUnsupported(); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(data, saveMagic) || len(data) < len(saveMagic)+8 {
+		return fmt.Errorf("%s is not a save file", path)
+	}
+	data = data[len(saveMagic):]
+	if binary.LittleEndian.Uint64(data) != mazeHash(levelTemplate) {
+		return fmt.Errorf("can't load %s: %w", path, errOtherMaze)
+	}
+	s, err := DecodeSnapshot(data[8:], nil)
+	if err != nil {
+		return err
+	}
+	return g.Restore(s)
+}
+
+// ReplayRecorder writes a replay as the game runs
+type ReplayRecorder struct {
+	Maze uint64 // mazeHash of the maze being recorded
+
+	file   *os.File
+	w      *bufio.Writer
+	prev   *Snapshot
+	frames int
+	buf    []byte
+}
+
+// NewReplayRecorder starts a replay of a game played in the maze with the
+// given mazeHash
+func NewReplayRecorder(path string, maze uint64) (*ReplayRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	header := binary.LittleEndian.AppendUint64(append([]byte(nil), replayMagic...), maze)
+	if _, err := w.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &ReplayRecorder{Maze: maze, file: f, w: w}, nil
+}
+
+// Record appends one frame
+func (r *ReplayRecorder) Record(s *Snapshot) error {
+	base := r.prev
+	if r.frames%replayKeyframeInterval == 0 {
+		base = nil
+	}
+	r.buf = AppendSnapshot(r.buf[:0], base, s)
+	var size [binary.MaxVarintLen64]byte
+	if _, err := r.w.Write(size[:binary.PutUvarint(size[:], uint64(len(r.buf)))]); err != nil {
+		return err
+	}
+	if _, err := r.w.Write(r.buf); err != nil {
+		return err
+	}
+	r.prev = s
+	r.frames++
+	return nil
+}
+
+func (r *ReplayRecorder) Close() error {
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// ReplayReader reads a replay back one frame at a time
+type ReplayReader struct {
+	Maze uint64 // mazeHash of the maze it was recorded in
+
+	file *os.File
+	r    *bufio.Reader
+	prev *Snapshot
+}
+
+func OpenReplay(path string) (*ReplayReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(f)
+	header := make([]byte, len(replayMagic)+8)
+	if _, err := io.ReadFull(r, header); err != nil || !bytes.HasPrefix(header, replayMagic) {
+		f.Close()
+		return nil, fmt.Errorf("%s is not a replay", path)
+	}
+	maze := binary.LittleEndian.Uint64(header[len(replayMagic):])
+	return &ReplayReader{Maze: maze, file: f, r: r}, nil
+}
+
+// Next returns the next frame, or io.EOF at the end of the replay
+func (r *ReplayReader) Next() (*Snapshot, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err // io.EOF between records is the normal end
+	}
+	if size > maxReplayRecord {
+		return nil, fmt.Errorf("replay record of %d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	s, err := DecodeSnapshot(data, r.prev)
+	if err != nil {
+		return nil, err
+	}
+	r.prev = s
+	return s, nil
+}
+
+func (r *ReplayReader) Close() error {
+	return r.file.Close()
+}
+
+// handleQuickSave saves or loads the quick save slot. Networked matches
+// can't load on one peer only, so the keys do nothing there.
+func (g *Game) handleQuickSave() {
+	if g.net != nil {
+		return
+	}
+	switch {
+	case Input.JustPressed(ActionQuickSave):
+		if err := g.SaveGame(savePath()); err != nil {
+			stateLog.Warn("quick save failed", "err", err)
+			return
+		}
+		stateLog.Info("saved", "path", savePath())
+	case Input.JustPressed(ActionQuickLoad):
+		if err := g.LoadGame(savePath()); err != nil {
+			stateLog.Warn("quick load failed", "err", err)
+			return
+		}
+		stateLog.Info("loaded", "path", savePath())
+	}
+}
+
+// recordFrame adds the frame just simulated to the replay being recorded.
+// Snapshots hold no boss, so boss rounds are left out and the replay jumps
+// from the round before to the one after. Recording ends when the game
+// moves on to anything else a replay can't hold.
+func (g *Game) recordFrame() {
+	if g.recorder == nil || !g.inMatch() || g.boss != nil {
+		return
+	}
+	err := g.snapshotsUnsupported()
+	if err == nil && g.recorder.Maze != mazeHash(levelTemplate) {
+		err = errors.New("the maze changed")
+	}
+	if err == nil {
+		err = g.recorder.Record(g.Snapshot())
+	}
+	if err != nil {
+		stateLog.Warn("replay recording stopped", "err", err)
+		g.stopRecording()
+	}
+}
+
+func (g *Game) stopRecording() {
+	if g.recorder == nil {
+		return
+	}
+	if err := g.recorder.Close(); err != nil {
+		stateLog.Warn("could not finish replay", "err", err)
+	}
+	g.recorder = nil
+}
+
+// updateReplay shows the next recorded frame instead of simulating one
+func (g *Game) updateReplay() error {
+	if Input.JustPressed(ActionBack) {
+		g.replay.Close()
+		g.replay = nil
+		g.resetGame()
+		g.State = StateMenu
+		return nil
+	}
+	g.globalTimer++ // keeps animations running once the replay has ended
+	s, err := g.replay.Next()
+	if err == io.EOF {
+		return nil
+	}
+	if err == nil && g.replay.Maze != mazeHash(levelTemplate) {
+		err = errOtherMaze
+	}
+	if err == nil {
+		err = g.Restore(s)
+		g.publishFrame()
+	}
+	if err != nil {
+		stateLog.Warn("replay stopped", "err", err)
+		g.replay.Close()
+		g.replay = nil
+		g.State = StateMenu
+	}
+	return nil
+}
+
+func (g *Game) drawReplayStatus(screen *ebiten.Image) {
+	style := overlayHint
+	style.Align = AlignLeft
+	DrawText(screen, "REPLAY - ESC TO LEAVE", 16, playfieldRect().Min.Y+10, style, g.globalTimer)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Rows of the settings screen
+const (
+	SettingTheme = iota
+	SettingPractice
+	SettingBack
+	settingCount
+)
+
+// SettingsPage is the screen behind the SETTINGS menu entry
+type SettingsPage struct {
+	selected   int
+	themeIndex int // index into Game.themeChoices()
+	message    string
+}
+
+func NewSettingsPage() *SettingsPage {
+	return &SettingsPage{}
+}
+
+// themeChoices lists the selectable themes, the built-in one (nil) first
+func (g *Game) themeChoices() []*ThemePack {
+	return append([]*ThemePack{nil}, g.themes...)
+}
+
+func (g *Game) openSettings() {
+	g.settings.selected = SettingTheme
+	g.settings.message = ""
+	for i, theme := range g.themeChoices() {
+		if theme.Name() == ActiveTheme.Name() {
+			g.settings.themeIndex = i
+		}
+	}
+	g.State = StateSettings
+}
+
+func (g *Game) updateSettings() error {
+	s := g.settings
+	// Keep the animated menu running behind the settings panel
+	if g.menuUI != nil {
+		g.menuUI.Update()
+	}
+
+	if Input.JustPressed(ActionUp) {
+		s.selected = (s.selected - 1 + settingCount) % settingCount
+	}
+	if Input.JustPressed(ActionDown) {
+		s.selected = (s.selected + 1) % settingCount
+	}
+
+	if s.selected == SettingTheme {
+		choices := g.themeChoices()
+		if Input.JustPressed(ActionLeft) {
+			s.themeIndex = (s.themeIndex - 1 + len(choices)) % len(choices)
+		}
+		if Input.JustPressed(ActionRight) {
+			s.themeIndex = (s.themeIndex + 1) % len(choices)
+		}
+	}
+
+	confirm := Input.JustPressed(ActionConfirm)
+	if s.selected == SettingPractice && (confirm || Input.JustPressed(ActionLeft) || Input.JustPressed(ActionRight)) {
+		g.togglePractice()
+	}
+	if confirm && s.selected == SettingTheme {
+		g.applyTheme(g.themeChoices()[s.themeIndex])
+		if g.AudioSystem != nil {
+			g.AudioSystem.PlaySFX("menu_select")
+		}
+	}
+	if Input.JustPressed(ActionBack) || (confirm && s.selected == SettingBack) {
+		g.State = StateMenu
+	}
+	return nil
+}
+
+// applyTheme switches theme at runtime and remembers the choice in the config
+func (g *Game) applyTheme(theme *ThemePack) {
+	if theme.Name() == ActiveTheme.Name() {
+		return
+	}
+	assetsLog.Info("switching theme", "theme", theme.Name())
+
+	LoadAssets(theme)
+	g.reloadThemedAssets()
+
+	g.settings.message = "THEME: " + theme.Name()
+	if g.config != nil {
+		g.config.Theme = theme.Name()
+		if err := g.config.Save(); err != nil {
+			g.settings.message = "COULD NOT SAVE SETTINGS"
+			stateLog.Error("failed to save config", "err", err)
+		}
+	}
+}
+
+// togglePractice switches time attack practice (no curses) on or off
+func (g *Game) togglePractice() {
+	if g.config == nil {
+		return
+	}
+	g.config.Practice = !g.config.Practice
+	g.settings.message = ""
+	if err := g.config.Save(); err != nil {
+		g.settings.message = "COULD NOT SAVE SETTINGS"
+		stateLog.Error("failed to save config", "err", err)
+	}
+	if g.AudioSystem != nil {
+		g.AudioSystem.PlaySFX("menu_select")
+	}
+}
+
+// reloadThemedAssets hands the freshly loaded assets to everything that cached them
+func (g *Game) reloadThemedAssets() {
+	PlayerImage = Assets.Image("player")
+	if g.Player != nil {
+		g.Player.Image = PlayerImage // Draw scales it to the tile-sized player
+	}
+	for _, ghost := range g.Ghosts {
+		ghost.Image = Assets.Image("ghost." + ghost.GhostType)
+	}
+
+	if g.menuUI != nil {
+		frames, err := Assets.Animation("menu.character")
+		if err != nil {
+			frames = []*ebiten.Image{}
+		}
+		g.menuUI.SetImages(Assets.Image("menu.logo"), frames, Assets.Image("menu.background"))
+	}
+	if g.IntroSystem != nil {
+		g.IntroSystem.loadAssets()
+	}
+	InitPellets(level, TileSize)
+	g.mazeImage = nil
+
+	if g.AudioSystem != nil {
+		g.AudioSystem.LoadErrors = g.AudioSystem.LoadErrors[:0]
+		g.AudioSystem.LoadAllAudio()
+		g.AudioSystem.StopBGM()
+		g.AudioSystem.PlayMenuMusic()
+	}
+}
+
+func (g *Game) drawSettings(screen *ebiten.Image) {
+	if g.menuUI != nil {
+		g.menuUI.Draw(screen)
+	}
+	s := g.settings
+
+	panelW, panelH := float32(560), float32(300)
+	panelX := float32(screenWidth)/2 - panelW/2
+	panelY := float32(screenHeight)/2 - panelH/2
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, 150}, false)
+	vector.DrawFilledRect(screen, panelX, panelY, panelW, panelH, color.RGBA{10, 15, 30, 230}, false)
+	vector.StrokeRect(screen, panelX, panelY, panelW, panelH, 2, color.RGBA{255, 215, 0, 200}, false)
+
+	x := int(panelX) + 40
+	y := int(panelY) + 30
+	title := overlayTitle
+	title.Size = TextLarge
+	title.Align = AlignLeft
+	DrawText(screen, "SETTINGS", x, y, title, g.globalTimer)
+
+	theme := g.themeChoices()[s.themeIndex]
+	practice := "OFF"
+	if g.config != nil && g.config.Practice {
+		practice = "ON"
+	}
+	rows := []string{
+		fmt.Sprintf("THEME:  < %s >", theme.Name()),
+		fmt.Sprintf("TIME ATTACK PRACTICE:  < %s >", practice),
+		"BACK",
+	}
+	for i, row := range rows {
+		style := TextStyle{Size: TextMedium, Color: color.RGBA{180, 190, 230, 220}}
+		if i == s.selected {
+			style.Color = color.RGBA{255, 255, 255, 255}
+			style.Glow = color.RGBA{148, 0, 211, 255}
+			row = "> " + row
+		}
+		DrawText(screen, row, x, y+70+i*40, style, g.globalTimer)
+	}
+
+	small := TextStyle{Size: TextSmall, Color: color.RGBA{150, 150, 150, 255}}
+	if theme != nil && theme.Manifest.Description != "" {
+		DrawText(screen, theme.Manifest.Description, x, y+190, small, g.globalTimer)
+	}
+	if s.message != "" {
+		small.Color = color.RGBA{148, 0, 211, 255}
+		DrawText(screen, s.message, x, y+215, small, g.globalTimer)
+	}
+	hint := overlayHint
+	hint.Align = AlignLeft
+	DrawText(screen, "LEFT/RIGHT CHANGE  ENTER APPLY  ESC BACK", x, int(panelY+panelH)-30, hint, g.globalTimer)
+}
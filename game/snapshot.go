@@ -0,0 +1,710 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Snapshots are the binary form of the simulation, used for ghost network
+// state, save files and replays. A snapshot is either full or a delta against
+// an earlier one; both use the same layout:
+//
+//	version  byte
+//	kind     byte    snapshotFull or snapshotDelta
+//	frame    uvarint
+//	game     fields
+//	player   fields
+//	ghosts   uvarint count, then fields and path per ghost
+//	pellets  see appendPellets
+//
+// "fields" is a fixed list of int64s: a uvarint bitmask of the fields that
+// differ from the base, then the zigzag varint difference for each of them.
+// A full snapshot uses all zeros as its base, so unchanged and zero fields
+// cost nothing either way. Positions are fixed point in snapshotSubpixels,
+// so a frame's move is a byte or two; other floats, which rarely change,
+// are their bits.
+
+const snapshotVersion = 2
+
+// snapshotSubpixels is how finely positions are kept, per pixel
+const snapshotSubpixels = 256
+
+const (
+	snapshotFull  = 0
+	snapshotDelta = 1
+)
+
+// Decoding limits, so a corrupt snapshot can't ask for huge allocations
+const (
+	maxSnapshotGhosts = 64
+	maxSnapshotPath   = 4096
+	maxSnapshotTiles  = 1 << 16
+)
+
+var (
+	errSnapshotShort   = errors.New("snapshot: unexpected end of data")
+	errSnapshotNoBase  = errors.New("snapshot: delta without a base snapshot")
+	errSnapshotVersion = errors.New("snapshot: unsupported version")
+)
+
+// GameVars is the part of the game state that isn't an actor or the maze
+type GameVars struct {
+	Lives           int
+	Round           int
+	State           GameState
+	PowerActive     bool
+	PowerTimer      int
+	RoundReadyTimer int
+	ShowRoundReady  bool
+	CurrentPlayer   int
+}
+
+func (v *GameVars) fields() []int64 {
+	return []int64{
+		int64(v.Lives), int64(v.Round), int64(v.State), boolField(v.PowerActive),
+		int64(v.PowerTimer), int64(v.RoundReadyTimer), boolField(v.ShowRoundReady), int64(v.CurrentPlayer),
+	}
+}
+
+func (v *GameVars) setFields(f []int64) error {
+	switch state := GameState(f[2]); {
+	case state != StatePlaying && state != StatePaused && state != StateRoundReady && state != StateGameOver:
+		return fmt.Errorf("snapshot: game state %d isn't one of play", f[2])
+	case f[0] < 0 || f[1] < 0:
+		return fmt.Errorf("snapshot: %d lives in round %d", f[0], f[1])
+	case f[7] != 0 && f[7] != 1:
+		return fmt.Errorf("snapshot: invalid current player %d", f[7])
+	}
+	*v = GameVars{
+		Lives: int(f[0]), Round: int(f[1]), State: GameState(f[2]), PowerActive: f[3] != 0,
+		PowerTimer: int(f[4]), RoundReadyTimer: int(f[5]), ShowRoundReady: f[6] != 0, CurrentPlayer: int(f[7]),
+	}
+	return nil
+}
+
+// PlayerState is everything about Gojo that changes during play
+type PlayerState struct {
+	X, Y      float64
+	Speed     float64
+	Direction string
+	Score     int
+}
+
+func (p *PlayerState) fields() []int64 {
+	return []int64{posField(p.X), posField(p.Y), floatField(p.Speed), directionCode(p.Direction), int64(p.Score)}
+}
+
+func (p *PlayerState) setFields(f []int64) error {
+	dir, err := directionName(f[3])
+	if err != nil {
+		return err
+	}
+	*p = PlayerState{X: fieldPos(f[0]), Y: fieldPos(f[1]), Speed: fieldFloat(f[2]), Direction: dir, Score: int(f[4])}
+	return nil
+}
+
+// GhostState is everything about a ghost that changes during play
+type GhostState struct {
+	X, Y              float64
+	Speed             float64
+	BaseSpeed         float64
+	Direction         string
+	PreviousDirection string
+	Mode              GhostMode
+	ModeTimer         int
+	FrightTimer       int
+	ScatterTimer      int
+	ChaseTimer        int
+	ReleaseTimer      int
+	PersonalityMode   int
+	CruiseElroyMode   int
+	StuckCounter      int
+	TargetX, TargetY  int
+	LastTileX         int
+	LastTileY         int
+	LastPosition      [2]int
+	Visible           bool
+	Path              [][2]int
+	PathIndex         int
+}
+
+func (s *GhostState) fields() []int64 {
+	return []int64{
+		posField(s.X), posField(s.Y), floatField(s.Speed), floatField(s.BaseSpeed),
+		directionCode(s.Direction), directionCode(s.PreviousDirection), int64(s.Mode),
+		int64(s.ModeTimer), int64(s.FrightTimer), int64(s.ScatterTimer), int64(s.ChaseTimer),
+		int64(s.ReleaseTimer), int64(s.PersonalityMode), int64(s.CruiseElroyMode), int64(s.StuckCounter),
+		int64(s.TargetX), int64(s.TargetY), int64(s.LastTileX), int64(s.LastTileY),
+		int64(s.LastPosition[0]), int64(s.LastPosition[1]), boolField(s.Visible), int64(s.PathIndex),
+	}
+}
+
+func (s *GhostState) setFields(f []int64) error {
+	dir, err := directionName(f[4])
+	if err != nil {
+		return err
+	}
+	prev, err := directionName(f[5])
+	if err != nil {
+		return err
+	}
+	if f[6] < int64(ChaseMode) || f[6] > int64(InHouseMode) {
+		return fmt.Errorf("snapshot: invalid ghost mode %d", f[6])
+	}
+	path := s.Path // decoded separately
+	*s = GhostState{
+		X: fieldPos(f[0]), Y: fieldPos(f[1]), Speed: fieldFloat(f[2]), BaseSpeed: fieldFloat(f[3]),
+		Direction: dir, PreviousDirection: prev, Mode: GhostMode(f[6]),
+		ModeTimer: int(f[7]), FrightTimer: int(f[8]), ScatterTimer: int(f[9]), ChaseTimer: int(f[10]),
+		ReleaseTimer: int(f[11]), PersonalityMode: int(f[12]), CruiseElroyMode: int(f[13]), StuckCounter: int(f[14]),
+		TargetX: int(f[15]), TargetY: int(f[16]), LastTileX: int(f[17]), LastTileY: int(f[18]),
+		LastPosition: [2]int{int(f[19]), int(f[20])}, Visible: f[21] != 0, PathIndex: int(f[22]),
+		Path: path,
+	}
+	return nil
+}
+
+// PelletState records which tiles still hold a pellet. Tiles holds one of
+// pelletNone, pelletNormal or pelletPower per tile, row by row.
+type PelletState struct {
+	Width, Height int
+	Tiles         []byte
+}
+
+const (
+	pelletNone = iota
+	pelletNormal
+	pelletPower
+)
+
+// Snapshot is the whole simulation at one frame
+type Snapshot struct {
+	Frame   int
+	Game    GameVars
+	Player  PlayerState
+	Ghosts  []GhostState
+	Pellets PelletState
+}
+
+// snapshotFields is implemented by the fixed-layout parts of a snapshot
+type snapshotFields interface {
+	fields() []int64
+	setFields([]int64) error
+}
+
+// Ghost state
+
+func (g *Ghost) State() GhostState {
+	s := GhostState{
+		X: g.X, Y: g.Y, Speed: g.Speed, BaseSpeed: g.BaseSpeed,
+		Direction: g.Direction, PreviousDirection: g.PreviousDirection, Mode: g.Mode,
+		ModeTimer: g.ModeTimer, FrightTimer: g.FrightTimer, ScatterTimer: g.ScatterTimer, ChaseTimer: g.ChaseTimer,
+		ReleaseTimer: g.ReleaseTimer, PersonalityMode: g.PersonalityMode, CruiseElroyMode: g.CruiseElroyMode,
+		StuckCounter: g.StuckCounter, TargetX: g.TargetX, TargetY: g.TargetY,
+		LastTileX: g.LastTileX, LastTileY: g.LastTileY, LastPosition: g.LastPosition,
+		Visible: g.Visible, PathIndex: g.PathIndex,
+	}
+	for _, n := range g.Path {
+		s.Path = append(s.Path, [2]int{n.X, n.Y})
+	}
+	return s
+}
+
+func (g *Ghost) SetState(s GhostState) {
+	g.X, g.Y = s.X, s.Y
+	g.Speed, g.BaseSpeed = s.Speed, s.BaseSpeed
+	g.Direction, g.PreviousDirection = s.Direction, s.PreviousDirection
+	g.Mode, g.ModeTimer = s.Mode, s.ModeTimer
+	g.FrightTimer, g.ScatterTimer, g.ChaseTimer, g.ReleaseTimer = s.FrightTimer, s.ScatterTimer, s.ChaseTimer, s.ReleaseTimer
+	g.PersonalityMode, g.CruiseElroyMode, g.StuckCounter = s.PersonalityMode, s.CruiseElroyMode, s.StuckCounter
+	g.TargetX, g.TargetY = s.TargetX, s.TargetY
+	g.LastTileX, g.LastTileY, g.LastPosition = s.LastTileX, s.LastTileY, s.LastPosition
+	g.Visible = s.Visible
+	g.Path = g.Path[:0]
+	for _, p := range s.Path {
+		g.Path = append(g.Path, Node{X: p[0], Y: p[1]})
+	}
+	g.PathIndex = s.PathIndex
+}
+
+// Player state
+
+func (p *Player) State() PlayerState {
+	return PlayerState{X: p.X, Y: p.Y, Speed: p.Speed, Direction: p.Direction, Score: p.Score}
+}
+
+func (p *Player) SetState(s PlayerState) {
+	p.X, p.Y, p.Speed, p.Direction, p.Score = s.X, s.Y, s.Speed, s.Direction, s.Score
+}
+
+// Pellet state
+
+func pelletStateOf(level [][]int) PelletState {
+	s := PelletState{Height: len(level)}
+	if len(level) > 0 {
+		s.Width = len(level[0])
+	}
+	s.Tiles = make([]byte, 0, s.Width*s.Height)
+	for _, row := range level {
+		for x := 0; x < s.Width; x++ {
+			tile := TileEmpty
+			if x < len(row) {
+				tile = row[x]
+			}
+			switch tile {
+			case TilePellet:
+				s.Tiles = append(s.Tiles, pelletNormal)
+			case TilePowerPellet:
+				s.Tiles = append(s.Tiles, pelletPower)
+			default:
+				s.Tiles = append(s.Tiles, pelletNone)
+			}
+		}
+	}
+	return s
+}
+
+// applyTo puts the pellets back into a level of the same size and reports
+// whether any tile changed. Walls and other tiles are left alone.
+func (s *PelletState) applyTo(level [][]int) (bool, error) {
+	if len(level) != s.Height || (s.Height > 0 && len(level[0]) != s.Width) {
+		return false, fmt.Errorf("snapshot: pellets are %dx%d, level is %d rows", s.Width, s.Height, len(level))
+	}
+	changed := false
+	set := func(row []int, x, tile int) {
+		if row[x] != tile {
+			row[x] = tile
+			changed = true
+		}
+	}
+	for y, row := range level {
+		for x := range row {
+			if x >= s.Width {
+				break
+			}
+			switch s.Tiles[y*s.Width+x] {
+			case pelletNormal:
+				set(row, x, TilePellet)
+			case pelletPower:
+				set(row, x, TilePowerPellet)
+			default:
+				if row[x] == TilePellet || row[x] == TilePowerPellet {
+					set(row, x, TileEmpty)
+				}
+			}
+		}
+	}
+	return changed, nil
+}
+
+// Game snapshots
+
+// Snapshot captures the simulation
+func (g *Game) Snapshot() *Snapshot {
+	s := &Snapshot{
+		Frame: g.globalTimer,
+		Game: GameVars{
+			Lives: g.lives, Round: g.RoundNumber, State: g.State,
+			PowerActive: g.powerPelletActive, PowerTimer: g.powerPelletTimer,
+			RoundReadyTimer: g.RoundReadyTimer, ShowRoundReady: g.ShowRoundReady,
+			CurrentPlayer: g.currentPlayer,
+		},
+		Player:  g.Player.State(),
+		Pellets: pelletStateOf(level),
+	}
+	for _, ghost := range g.Ghosts {
+		s.Ghosts = append(s.Ghosts, ghost.State())
+	}
+	return s
+}
+
+// Restore puts the simulation back to a snapshot taken in the same maze
+func (g *Game) Restore(s *Snapshot) error {
+	if len(s.Ghosts) != len(g.Ghosts) {
+		return fmt.Errorf("snapshot has %d ghosts, game has %d", len(s.Ghosts), len(g.Ghosts))
+	}
+	changed, err := s.Pellets.applyTo(level)
+	if err != nil {
+		return err
+	}
+	if !onMaze(level, s.Player.X, s.Player.Y) {
+		return fmt.Errorf("snapshot: player at (%g, %g) is off the maze", s.Player.X, s.Player.Y)
+	}
+	for i, ghost := range s.Ghosts {
+		if !onMaze(level, ghost.X, ghost.Y) {
+			return fmt.Errorf("snapshot: %s at (%g, %g) is off the maze", g.Ghosts[i].GhostType, ghost.X, ghost.Y)
+		}
+	}
+	g.globalTimer = s.Frame
+	g.lives, g.RoundNumber, g.State = s.Game.Lives, s.Game.Round, s.Game.State
+	g.powerPelletActive, g.powerPelletTimer = s.Game.PowerActive, s.Game.PowerTimer
+	g.RoundReadyTimer, g.ShowRoundReady = s.Game.RoundReadyTimer, s.Game.ShowRoundReady
+	g.currentPlayer = s.Game.CurrentPlayer
+	g.Player.SetState(s.Player)
+	for i, ghost := range g.Ghosts {
+		ghost.SetState(s.Ghosts[i])
+	}
+	if changed {
+		InitPellets(level, TileSize)
+		g.countPellets()
+	}
+	return nil
+}
+
+// onMaze reports whether the tile an actor at (x, y) stands on is in level
+func onMaze(level [][]int, x, y float64) bool {
+	cx, cy := x+TileSize/2, y+TileSize/2
+	return cy >= 0 && cy < float64(len(level)*TileSize) && cx >= 0 && len(level) > 0 && cx < float64(len(level[0])*TileSize)
+}
+
+// Encoding
+
+// AppendSnapshot appends the encoding of s to dst. With a base it writes a
+// delta that DecodeSnapshot turns back into s given the same base.
+func AppendSnapshot(dst []byte, base, s *Snapshot) []byte {
+	if base != nil && len(base.Ghosts) != len(s.Ghosts) {
+		base = nil // the ghosts don't line up, send everything
+	}
+	kind := byte(snapshotFull)
+	if base != nil {
+		kind = snapshotDelta
+	} else {
+		base = &Snapshot{Ghosts: make([]GhostState, len(s.Ghosts))}
+	}
+
+	dst = append(dst, snapshotVersion, kind)
+	dst = binary.AppendUvarint(dst, uint64(s.Frame))
+	dst = appendFields(dst, base.Game.fields(), s.Game.fields())
+	dst = appendFields(dst, base.Player.fields(), s.Player.fields())
+	dst = binary.AppendUvarint(dst, uint64(len(s.Ghosts)))
+	for i := range s.Ghosts {
+		dst = appendGhost(dst, &base.Ghosts[i], &s.Ghosts[i])
+	}
+	return appendPellets(dst, &base.Pellets, &s.Pellets)
+}
+
+// DecodeSnapshot decodes a snapshot written by AppendSnapshot. Delta
+// snapshots need the base they were encoded against.
+func DecodeSnapshot(data []byte, base *Snapshot) (*Snapshot, error) {
+	r := &snapReader{data: data}
+	if r.byte() != snapshotVersion {
+		return nil, errSnapshotVersion
+	}
+	switch r.byte() {
+	case snapshotFull:
+		base = nil
+	case snapshotDelta:
+		if base == nil {
+			return nil, errSnapshotNoBase
+		}
+	default:
+		return nil, errors.New("snapshot: unknown kind")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	s := &Snapshot{Frame: int(r.uvarint())}
+	var baseGame, basePlayer snapshotFields = &GameVars{}, &PlayerState{}
+	if base != nil {
+		baseGame, basePlayer = &base.Game, &base.Player
+	}
+	r.fields(baseGame, &s.Game)
+	r.fields(basePlayer, &s.Player)
+
+	count := r.uvarint()
+	if count > maxSnapshotGhosts {
+		return nil, fmt.Errorf("snapshot: %d ghosts", count)
+	}
+	if base != nil && int(count) != len(base.Ghosts) {
+		return nil, fmt.Errorf("snapshot: delta has %d ghosts, base has %d", count, len(base.Ghosts))
+	}
+	s.Ghosts = make([]GhostState, count)
+	for i := range s.Ghosts {
+		var bg *GhostState
+		if base != nil {
+			bg = &base.Ghosts[i]
+		}
+		r.ghost(bg, &s.Ghosts[i])
+	}
+
+	var bp *PelletState
+	if base != nil {
+		bp = &base.Pellets
+	}
+	r.pellets(bp, &s.Pellets)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.data) != 0 {
+		return nil, fmt.Errorf("snapshot: %d trailing bytes", len(r.data))
+	}
+	return s, nil
+}
+
+// GetNetworkState encodes the player on its own
+func (p *Player) GetNetworkState() []byte {
+	s := p.State()
+	return appendFields([]byte{snapshotVersion}, (&PlayerState{}).fields(), s.fields())
+}
+
+// SetNetworkState applies state produced by GetNetworkState
+func (p *Player) SetNetworkState(data []byte) error {
+	r := &snapReader{data: data}
+	if r.byte() != snapshotVersion {
+		return errSnapshotVersion
+	}
+	var s PlayerState
+	r.fields(&PlayerState{}, &s)
+	if r.err != nil {
+		return r.err
+	}
+	p.SetState(s)
+	return nil
+}
+
+func appendFields(dst []byte, base, cur []int64) []byte {
+	var mask uint64
+	for i := range cur {
+		if cur[i] != base[i] {
+			mask |= 1 << i
+		}
+	}
+	dst = binary.AppendUvarint(dst, mask)
+	for i := range cur {
+		if mask&(1<<i) != 0 {
+			dst = binary.AppendVarint(dst, cur[i]-base[i])
+		}
+	}
+	return dst
+}
+
+// appendGhost writes the ghost's fields, then its path if it changed
+func appendGhost(dst []byte, base, s *GhostState) []byte {
+	dst = appendFields(dst, base.fields(), s.fields())
+	if slices.Equal(base.Path, s.Path) {
+		return append(dst, 0)
+	}
+	dst = append(dst, 1)
+	dst = binary.AppendUvarint(dst, uint64(len(s.Path)))
+	for _, p := range s.Path {
+		dst = binary.AppendVarint(dst, int64(p[0]))
+		dst = binary.AppendVarint(dst, int64(p[1]))
+	}
+	return dst
+}
+
+// appendPellets writes either every tile, two bits each, or, when the base
+// has the same size, just the tiles that changed as (gap, value) pairs. A
+// frame usually changes no tiles or one.
+func appendPellets(dst []byte, base, s *PelletState) []byte {
+	if base.Width == s.Width && base.Height == s.Height && len(base.Tiles) == len(s.Tiles) {
+		dst = append(dst, 1)
+		var changed []int
+		for i := range s.Tiles {
+			if s.Tiles[i] != base.Tiles[i] {
+				changed = append(changed, i)
+			}
+		}
+		dst = binary.AppendUvarint(dst, uint64(len(changed)))
+		last := 0
+		for _, i := range changed {
+			dst = binary.AppendUvarint(dst, uint64(i-last))
+			dst = append(dst, s.Tiles[i])
+			last = i
+		}
+		return dst
+	}
+
+	dst = append(dst, 0)
+	dst = binary.AppendUvarint(dst, uint64(s.Width))
+	dst = binary.AppendUvarint(dst, uint64(s.Height))
+	var b byte
+	for i, t := range s.Tiles {
+		b |= t << (2 * (i % 4))
+		if i%4 == 3 {
+			dst = append(dst, b)
+			b = 0
+		}
+	}
+	if len(s.Tiles)%4 != 0 {
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+// snapReader reads the encoding back. The first error sticks and later
+// reads return zeros, so callers check err once at the end.
+type snapReader struct {
+	data []byte
+	err  error
+}
+
+func (r *snapReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+	r.data = nil
+}
+
+func (r *snapReader) byte() byte {
+	if len(r.data) == 0 {
+		r.fail(errSnapshotShort)
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *snapReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.fail(errSnapshotShort)
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *snapReader) varint() int64 {
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail(errSnapshotShort)
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *snapReader) fields(base, out snapshotFields) {
+	f := base.fields()
+	mask := r.uvarint()
+	if mask>>len(f) != 0 {
+		r.fail(errors.New("snapshot: unknown fields"))
+		return
+	}
+	for i := range f {
+		if mask&(1<<i) != 0 {
+			f[i] += r.varint()
+		}
+	}
+	if r.err != nil {
+		return
+	}
+	if err := out.setFields(f); err != nil {
+		r.fail(err)
+	}
+}
+
+func (r *snapReader) ghost(base, out *GhostState) {
+	if base == nil {
+		base = &GhostState{}
+	}
+	out.Path = base.Path
+	r.fields(base, out)
+	switch r.byte() {
+	case 0:
+		out.Path = slices.Clone(base.Path)
+	case 1:
+		n := r.uvarint()
+		if n > maxSnapshotPath {
+			r.fail(fmt.Errorf("snapshot: path of %d nodes", n))
+			return
+		}
+		out.Path = make([][2]int, n)
+		for i := range out.Path {
+			out.Path[i] = [2]int{int(r.varint()), int(r.varint())}
+		}
+	default:
+		r.fail(errors.New("snapshot: bad path flag"))
+	}
+}
+
+func (r *snapReader) pellets(base, out *PelletState) {
+	switch r.byte() {
+	case 0:
+		w, h := r.uvarint(), r.uvarint()
+		if w > maxSnapshotTiles || h > maxSnapshotTiles || w*h > maxSnapshotTiles {
+			r.fail(fmt.Errorf("snapshot: %dx%d maze", w, h))
+			return
+		}
+		out.Width, out.Height = int(w), int(h)
+		out.Tiles = make([]byte, w*h)
+		for i := range out.Tiles {
+			if i%4 == 0 {
+				b := r.byte()
+				for j := 0; j < 4 && i+j < len(out.Tiles); j++ {
+					out.Tiles[i+j] = b >> (2 * j) & 3
+				}
+			}
+		}
+	case 1:
+		if base == nil {
+			base = &PelletState{}
+		}
+		out.Width, out.Height = base.Width, base.Height
+		out.Tiles = slices.Clone(base.Tiles)
+		n := r.uvarint()
+		if n > uint64(len(out.Tiles)) {
+			r.fail(fmt.Errorf("snapshot: %d changed tiles", n))
+			return
+		}
+		i := uint64(0)
+		for k := uint64(0); k < n; k++ {
+			i += r.uvarint()
+			v := r.byte()
+			if r.err != nil {
+				return
+			}
+			if i >= uint64(len(out.Tiles)) {
+				r.fail(errors.New("snapshot: tile index out of range"))
+				return
+			}
+			out.Tiles[i] = v
+		}
+	default:
+		r.fail(errors.New("snapshot: bad pellet encoding"))
+		return
+	}
+	for _, t := range out.Tiles {
+		if t > pelletPower {
+			r.fail(fmt.Errorf("snapshot: bad pellet value %d", t))
+			return
+		}
+	}
+}
+
+// Field helpers
+
+func floatField(f float64) int64 { return int64(math.Float64bits(f)) }
+func fieldFloat(v int64) float64 { return math.Float64frombits(uint64(v)) }
+
+func posField(f float64) int64 { return int64(math.Round(f * snapshotSubpixels)) }
+func fieldPos(v int64) float64 { return float64(v) / snapshotSubpixels }
+
+func boolField(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var directionCodes = []string{"", "up", "down", "left", "right"}
+
+func directionCode(dir string) int64 {
+	if i := slices.Index(directionCodes, dir); i >= 0 {
+		return int64(i)
+	}
+	return 0
+}
+
+func directionName(code int64) (string, error) {
+	if code < 0 || code >= int64(len(directionCodes)) {
+		return "", fmt.Errorf("snapshot: invalid direction %d", code)
+	}
+	return directionCodes[code], nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testSnapshot is a snapshot with something in every part, on a small maze
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Frame: 1234,
+		Game: GameVars{
+			Lives: 2, Round: 3, State: StatePlaying, PowerActive: true,
+			PowerTimer: 240, RoundReadyTimer: 7, ShowRoundReady: true, CurrentPlayer: 1,
+		},
+		Player: PlayerState{X: 32.5, Y: -4, Speed: 2, Direction: "left", Score: 4210},
+		Ghosts: []GhostState{
+			{
+				X: 416, Y: 416, Speed: 1.6, BaseSpeed: 0.8, Direction: "up", PreviousDirection: "right",
+				Mode: FrightenedMode, FrightTimer: 90, ReleaseTimer: -1, TargetX: 3, TargetY: 4,
+				LastPosition: [2]int{13, 13}, Visible: true, Path: [][2]int{{13, 12}, {13, 11}}, PathIndex: 1,
+			},
+			{Mode: InHouseMode, ReleaseTimer: 300},
+		},
+		Pellets: PelletState{
+			Width: 5, Height: 3,
+			Tiles: []byte{
+				pelletNone, pelletNormal, pelletNormal, pelletNormal, pelletNone,
+				pelletPower, pelletNone, pelletNone, pelletNone, pelletPower,
+				pelletNone, pelletNormal, pelletNormal, pelletNormal, pelletNone,
+			},
+		},
+	}
+}
+
+// cloneSnapshot deep copies s so a test can change it without touching s
+func cloneSnapshot(s *Snapshot) *Snapshot {
+	c := *s
+	c.Ghosts = append([]GhostState(nil), s.Ghosts...)
+	for i := range c.Ghosts {
+		c.Ghosts[i].Path = append([][2]int(nil), s.Ghosts[i].Path...)
+	}
+	c.Pellets.Tiles = append([]byte(nil), s.Pellets.Tiles...)
+	return &c
+}
+
+// snapshotTestGame is a game on the classic maze with the four curses,
+// without the assets and audio NewGame needs
+func snapshotTestGame(t testing.TB) *Game {
+	t.Cleanup(func() { copyLevelInto(level, levelTemplate) })
+	gs := &GameStateStruct{Level: level, CurrentLevel: 1}
+	g := &Game{
+		Player: NewPlayer(32, 32, "player"), lives: 3, RoundNumber: 1,
+		playerStartX: 32, playerStartY: 32, gameState: gs,
+	}
+	g.ghostManager = NewGhostManager(gs)
+	g.Ghosts = newCurses()
+	for _, ghost := range g.Ghosts {
+		g.ghostManager.AddGhost(ghost)
+	}
+	g.resetGhosts()
+	return g
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	want := testSnapshot()
+	got, err := DecodeSnapshot(AppendSnapshot(nil, nil, want), nil)
+	if err != nil {
+		t.Fatalf("decoding full snapshot: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("full snapshot round trip:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// A chain of deltas, each decoded against the previous decoded frame
+	frames := []*Snapshot{want}
+	for i := 0; i < 5; i++ {
+		s := cloneSnapshot(frames[len(frames)-1])
+		s.Frame++
+		s.Player.X += 2
+		s.Player.Score += 10
+		s.Ghosts[0].Y -= 1.5 // a whole number of subpixels, so it comes back exactly
+		s.Ghosts[0].PathIndex = i % 2
+		s.Pellets.Tiles[1+i%3] = pelletNone
+		if i == 2 {
+			s.Ghosts[1].Mode = ScatterMode
+			s.Ghosts[1].Path = [][2]int{{1, 1}, {1, 2}, {2, 2}}
+			s.Game.PowerActive = false
+		}
+		frames = append(frames, s)
+	}
+	base := got
+	for i := 1; i < len(frames); i++ {
+		data := AppendSnapshot(nil, frames[i-1], frames[i])
+		if data[1] != snapshotDelta {
+			t.Fatalf("frame %d: encoded as kind %d, want a delta", i, data[1])
+		}
+		got, err := DecodeSnapshot(data, base)
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, frames[i]) {
+			t.Fatalf("frame %d:\ngot  %+v\nwant %+v", i, got, frames[i])
+		}
+		base = got
+	}
+}
+
+func TestSnapshotDeltaSize(t *testing.T) {
+	g := snapshotTestGame(t)
+	g.State = StatePlaying
+	prev := g.Snapshot()
+	base := prev
+	for frame := 1; frame <= 120; frame++ {
+		// Everyone on the move, as in most frames of play
+		s := cloneSnapshot(prev)
+		s.Frame++
+		s.Player.X += s.Player.Speed
+		for i := range s.Ghosts {
+			s.Ghosts[i].Y -= 1.6
+		}
+
+		data := AppendSnapshot(nil, prev, s)
+		// Version, kind and frame, the unchanged game, the player's mask and
+		// X, the ghost count, each ghost's mask, Y and path flag, and no
+		// pellets changed
+		budget := 2 + 2 + 1 + 1 + 2 + 1 + len(s.Ghosts)*(1+2+1) + 2
+		if len(data) > budget {
+			t.Fatalf("frame %d: delta is %d bytes, want at most %d", frame, len(data), budget)
+		}
+		got, err := DecodeSnapshot(data, base)
+		if err != nil {
+			t.Fatalf("frame %d: %v", frame, err)
+		}
+		for i := range got.Ghosts {
+			if d := math.Abs(got.Ghosts[i].Y - s.Ghosts[i].Y); d > 0.5/snapshotSubpixels {
+				t.Fatalf("frame %d: ghost %d decoded %v px from where it was", frame, i, d)
+			}
+		}
+		prev, base = s, got
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	g := snapshotTestGame(t)
+	want := g.Snapshot()
+	want.Frame = 600
+	want.Game.State = StatePlaying
+	want.Game.Lives, want.Game.Round, want.Game.PowerActive, want.Game.PowerTimer = 1, 4, true, 120
+	want.Player = PlayerState{X: 224, Y: 320, Speed: 2, Direction: "down", Score: 1500}
+	for i := range want.Ghosts {
+		want.Ghosts[i].X += float64(32 * i)
+		want.Ghosts[i].Mode = FrightenedMode
+		want.Ghosts[i].Path = [][2]int{{1, 1}, {2, 1}}
+	}
+
+	// Restore what came back from a keyframe and a delta on top of it
+	key, err := DecodeSnapshot(AppendSnapshot(nil, nil, want), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := cloneSnapshot(want)
+	next.Frame++
+	next.Player.Y -= 2
+	s, err := DecodeSnapshot(AppendSnapshot(nil, want, next), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Snapshot(); !reflect.DeepEqual(got, next) {
+		t.Fatalf("restored game:\ngot  %+v\nwant %+v", got, next)
+	}
+}
+
+func TestRecordSkipsBossRounds(t *testing.T) {
+	g := snapshotTestGame(t)
+	path := filepath.Join(t.TempDir(), "run.jkr")
+	rec, err := NewReplayRecorder(path, mazeHash(levelTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.recorder = rec
+
+	// A frame before the boss, two during and one after
+	g.State = StatePlaying
+	g.recordFrame()
+	g.State, g.boss = StateBoss, newBoss()
+	g.recordFrame()
+	g.recordFrame()
+	if g.recorder == nil {
+		t.Fatal("recording stopped at the boss round")
+	}
+	g.State, g.boss = StatePlaying, nil
+	g.Player.X += 2
+	g.recordFrame()
+	g.stopRecording()
+
+	r, err := OpenReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	frames := 0
+	for {
+		if _, err := r.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		frames++
+	}
+	if frames != 2 {
+		t.Fatalf("recorded %d frames, want the 2 outside the boss round", frames)
+	}
+}
+
+func FuzzDecodeSnapshot(f *testing.F) {
+	s := testSnapshot()
+	next := cloneSnapshot(s)
+	next.Frame++
+	next.Ghosts[1].Path = [][2]int{{4, 4}}
+	next.Pellets.Tiles[2] = pelletNone
+	f.Add(AppendSnapshot(nil, nil, s))
+	f.Add(AppendSnapshot(nil, s, next))
+	f.Add([]byte{snapshotVersion, snapshotDelta})
+	f.Add([]byte{})
+
+	// Snapshots of a live game, which can be restored into it
+	g := snapshotTestGame(f)
+	g.State = StatePlaying
+	setup := g.currentSetup()
+	live := g.Snapshot()
+	f.Add(AppendSnapshot(nil, nil, live))
+	editor := cloneSnapshot(live)
+	editor.Game.State = StateEditor
+	f.Add(AppendSnapshot(nil, nil, editor))
+	moved := cloneSnapshot(live)
+	moved.Player.X = -1000
+	f.Add(AppendSnapshot(nil, nil, moved))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Errors are fine, panics and huge allocations are not
+		if got, err := DecodeSnapshot(data, nil); err == nil {
+			// A quick load restores the snapshot and plays on
+			g.restoreSetup(setup)
+			if g.Restore(got) == nil {
+				for i := 0; i < 10; i++ {
+					if err := g.step(); err != nil {
+						t.Fatalf("frame %d after restoring: %v", i, err)
+					}
+				}
+			}
+		}
+		for _, base := range []*Snapshot{nil, s} {
+			got, err := DecodeSnapshot(data, base)
+			if err != nil {
+				continue
+			}
+			// Whatever decodes must survive another round trip. The
+			// encodings are compared, floats decoded from junk can be NaN.
+			enc := AppendSnapshot(nil, nil, got)
+			again, err := DecodeSnapshot(enc, nil)
+			if err != nil {
+				t.Fatalf("re-decoding: %v", err)
+			}
+			if !bytes.Equal(AppendSnapshot(nil, nil, again), enc) {
+				t.Fatalf("re-decoded %+v, want %+v", again, got)
+			}
+		}
+	})
+}
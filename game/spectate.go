@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"jk/maze"
+)
+
+// Spectating streams a running game to read-only viewers as server-sent
+// events on /stream:
+//
+//	event: hello     data: {"version":2,"ghosts":["jogo",...]}
+//	event: maze      data: {"tiles":[[1,1,...],...]}
+//	event: snapshot  data: base64 snapshot, full for the first one, then deltas
+//	event: game      data: {"frame":...,"type":"life_lost",...}
+//
+// Viewers are any SSE client, including this game started with --spectate,
+// which renders the stream without simulating. The maze comes before the
+// first snapshot and again, followed by a full snapshot, whenever the game
+// moves to another maze; snapshots only carry the pellets. Viewers still
+// need the same theme as the server.
+
+const (
+	spectateProtocolVersion = 2
+	spectateClientBuffer    = 256 // messages queued per viewer before it's dropped
+	spectateRetryDelay      = 2 * time.Second
+)
+
+// SpectateEvent is something notable that happened between two frames
+type SpectateEvent struct {
+	Frame int    `json:"frame"`
+	Type  string `json:"type"`
+	Ghost string `json:"ghost,omitempty"`
+	Score int    `json:"score"`
+	Lives int    `json:"lives"`
+	Round int    `json:"round"`
+}
+
+// SpectateServer fans snapshots out to connected viewers
+type SpectateServer struct {
+	mu       sync.Mutex
+	viewers  map[chan []byte]bool
+	last     *Snapshot
+	maze     [][]int // tiles of the maze last sent
+	mazeHash uint64
+	ghosts   []string
+	server   *http.Server
+}
+
+// spectateMaze is the data of a maze event
+type spectateMaze struct {
+	Tiles [][]int `json:"tiles"`
+}
+
+// ServeSpectate starts the spectator server on addr
+func ServeSpectate(addr string, ghosts []string) (*SpectateServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &SpectateServer{viewers: map[chan []byte]bool{}, ghosts: ghosts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream", s.handleStream)
+	s.server = &http.Server{Handler: mux}
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			stateLog.Warn("spectator server stopped", "err", err)
+		}
+	}()
+	stateLog.Info("spectators can watch", "url", fmt.Sprintf("http://%s/stream", ln.Addr()))
+	return s, nil
+}
+
+func (s *SpectateServer) Close() error {
+	return s.server.Close()
+}
+
+func (s *SpectateServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	ch := make(chan []byte, spectateClientBuffer)
+	s.mu.Lock()
+	hello, _ := json.Marshal(map[string]any{"version": spectateProtocolVersion, "ghosts": s.ghosts})
+	ch <- sseMessage("hello", string(hello))
+	// Later deltas are against s.last, so the viewer starts from it in full
+	if s.last != nil {
+		ch <- mazeMessage(s.maze)
+		ch <- snapshotMessage(nil, s.last)
+	}
+	s.viewers[ch] = true
+	s.mu.Unlock()
+	stateLog.Info("spectator connected", "addr", r.RemoteAddr)
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.viewers, ch)
+		s.mu.Unlock()
+		stateLog.Info("spectator left", "addr", r.RemoteAddr)
+	}()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return // fell too far behind
+			}
+			if _, err := w.Write(msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Publish sends a frame played in the maze with the given starting tiles,
+// and any events since the last frame, to every viewer
+func (s *SpectateServer) Publish(snap *Snapshot, tiles [][]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var msgs [][]byte
+	if hash := mazeHash(tiles); s.last == nil || hash != s.mazeHash {
+		// A new maze: send it, then a full snapshot to go with it
+		s.maze, s.mazeHash = maze.Copy(tiles), hash
+		msgs = append(msgs, mazeMessage(s.maze), snapshotMessage(nil, snap))
+	} else {
+		msgs = append(msgs, snapshotMessage(s.last, snap))
+	}
+	for _, ev := range spectateEvents(s.last, snap, s.ghosts) {
+		data, _ := json.Marshal(ev)
+		msgs = append(msgs, sseMessage("game", string(data)))
+	}
+	s.last = snap
+
+	for ch := range s.viewers {
+		for _, msg := range msgs {
+			select {
+			case ch <- msg:
+			default:
+				// A viewer that can't keep up would miss a delta; drop it and
+				// let it reconnect for a fresh full snapshot
+				delete(s.viewers, ch)
+				close(ch)
+			}
+			if !s.viewers[ch] {
+				break
+			}
+		}
+	}
+}
+
+func sseMessage(event, data string) []byte {
+	return []byte("event: " + event + "\ndata: " + data + "\n\n")
+}
+
+func mazeMessage(tiles [][]int) []byte {
+	data, _ := json.Marshal(spectateMaze{Tiles: tiles})
+	return sseMessage("maze", string(data))
+}
+
+func snapshotMessage(base, snap *Snapshot) []byte {
+	return sseMessage("snapshot", base64.StdEncoding.EncodeToString(AppendSnapshot(nil, base, snap)))
+}
+
+// spectateEvents works out what happened between two frames
+func spectateEvents(prev, cur *Snapshot, ghosts []string) []SpectateEvent {
+	if prev == nil {
+		return nil
+	}
+	ev := func(kind, ghost string) SpectateEvent {
+		return SpectateEvent{Frame: cur.Frame, Type: kind, Ghost: ghost, Score: cur.Player.Score, Lives: cur.Game.Lives, Round: cur.Game.Round}
+	}
+	var events []SpectateEvent
+	if cur.Game.Lives < prev.Game.Lives {
+		events = append(events, ev("life_lost", ""))
+	}
+	if cur.Game.Round > prev.Game.Round {
+		events = append(events, ev("round_clear", ""))
+	}
+	if cur.Game.PowerActive && !prev.Game.PowerActive {
+		events = append(events, ev("power_pellet", ""))
+	}
+	if len(cur.Ghosts) == len(prev.Ghosts) {
+		for i := range cur.Ghosts {
+			if cur.Ghosts[i].Mode == DeadMode && prev.Ghosts[i].Mode != DeadMode && i < len(ghosts) {
+				events = append(events, ev("ghost_eaten", ghosts[i]))
+			}
+		}
+	}
+	if cur.Game.State == StateGameOver && prev.Game.State != StateGameOver {
+		events = append(events, ev("game_over", ""))
+	}
+	return events
+}
+
+// publishFrame hands the current frame to the spectator server, if any.
+// Snapshots hold no boss, so spectators wait out boss rounds on the last
+// frame before one.
+func (g *Game) publishFrame() {
+	if g.spectateServer == nil || !g.inMatch() || g.boss != nil {
+		return
+	}
+	g.spectateServer.Publish(g.Snapshot(), levelTemplate)
+}
+
+func ghostNames(ghosts []*Ghost) []string {
+	names := make([]string, len(ghosts))
+	for i, ghost := range ghosts {
+		names[i] = ghost.GhostType
+	}
+	return names
+}
+
+// SpectateClient follows a spectator stream. Decoding happens on its own
+// goroutine; the game picks up the newest frame each tick.
+type SpectateClient struct {
+	url    string
+	ghosts int
+
+	mu     sync.Mutex
+	latest *Snapshot
+	maze   [][]int         // tiles of a new maze, until the game takes it
+	events []SpectateEvent // most recent last
+	status string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+const spectateEventHistory = 4
+
+// Spectate connects to a spectator server. addr is host:port or a URL.
+func Spectate(addr string, ghosts int) *SpectateClient {
+	url := addr
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+	if !strings.HasSuffix(url, "/stream") {
+		url = strings.TrimSuffix(url, "/") + "/stream"
+	}
+	c := &SpectateClient{url: url, ghosts: ghosts, status: "CONNECTING"}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	go c.run()
+	return c
+}
+
+func (c *SpectateClient) Close() {
+	c.cancel()
+}
+
+func (c *SpectateClient) setStatus(status string) {
+	c.mu.Lock()
+	c.status = status
+	c.mu.Unlock()
+}
+
+// run keeps the stream open, reconnecting after errors
+func (c *SpectateClient) run() {
+	for {
+		err := c.follow()
+		if c.ctx.Err() != nil {
+			return
+		}
+		c.setStatus("DISCONNECTED: " + err.Error())
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(spectateRetryDelay):
+		}
+	}
+}
+
+func (c *SpectateClient) follow() error {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server replied %s", resp.Status)
+	}
+	c.setStatus("WAITING FOR A GAME")
+
+	var prev *Snapshot
+	event := ""
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := c.handle(event, strings.TrimPrefix(line, "data: "), &prev); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("stream ended")
+}
+
+func (c *SpectateClient) handle(event, data string, prev **Snapshot) error {
+	switch event {
+	case "hello":
+		var hello struct {
+			Version int      `json:"version"`
+			Ghosts  []string `json:"ghosts"`
+		}
+		if err := json.Unmarshal([]byte(data), &hello); err != nil {
+			return err
+		}
+		if hello.Version != spectateProtocolVersion {
+			return fmt.Errorf("server speaks version %d", hello.Version)
+		}
+		if len(hello.Ghosts) != c.ghosts {
+			return fmt.Errorf("server has %d ghosts, this game has %d", len(hello.Ghosts), c.ghosts)
+		}
+	case "maze":
+		var m spectateMaze
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			return err
+		}
+		if err := checkSpectateMaze(m.Tiles); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.maze = m.Tiles
+		c.latest = nil // frames of the old maze don't fit the new one
+		c.mu.Unlock()
+	case "snapshot":
+		raw, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return err
+		}
+		snap, err := DecodeSnapshot(raw, *prev)
+		if err != nil {
+			return err
+		}
+		*prev = snap
+		c.mu.Lock()
+		c.latest = snap
+		c.status = ""
+		c.mu.Unlock()
+	case "game":
+		var ev SpectateEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.events = append(c.events, ev)
+		if len(c.events) > spectateEventHistory {
+			c.events = c.events[1:]
+		}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// checkSpectateMaze rejects a maze the game couldn't show
+func checkSpectateMaze(tiles [][]int) error {
+	if len(tiles) == 0 || len(tiles[0]) == 0 || len(tiles)*len(tiles[0]) > maxSnapshotTiles {
+		return errors.New("server sent an unusable maze")
+	}
+	for _, row := range tiles {
+		if len(row) != len(tiles[0]) {
+			return errors.New("server sent a ragged maze")
+		}
+		for _, tile := range row {
+			if tile < TileEmpty || tile > TilePowerPellet {
+				return fmt.Errorf("server sent unknown tile %d", tile)
+			}
+		}
+	}
+	return nil
+}
+
+// take returns the maze the server moved to, if it changed, and the newest
+// unseen frame, if any
+func (c *SpectateClient) take() ([][]int, *Snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tiles, s := c.maze, c.latest
+	c.maze, c.latest = nil, nil
+	return tiles, s
+}
+
+// updateSpectating shows the newest streamed frame instead of simulating
+func (g *Game) updateSpectating() error {
+	if Input.JustPressed(ActionBack) {
+		g.spectating.Close()
+		g.spectating = nil
+		g.resetGame()
+		g.State = StateMenu
+		return nil
+	}
+	g.globalTimer++
+	tiles, s := g.spectating.take()
+	if tiles != nil {
+		setup := g.currentSetup()
+		setup.template = tiles
+		g.restoreSetup(setup)
+	}
+	if s != nil {
+		if err := g.Restore(s); err != nil {
+			g.spectating.setStatus(err.Error())
+		}
+	}
+	return nil
+}
+
+func (g *Game) drawSpectateStatus(screen *ebiten.Image) {
+	c := g.spectating
+	c.mu.Lock()
+	status := c.status
+	events := append([]SpectateEvent(nil), c.events...)
+	c.mu.Unlock()
+
+	style := overlayHint
+	style.Align = AlignLeft
+	top := playfieldRect().Min.Y + 10
+	DrawText(screen, "SPECTATING - ESC TO LEAVE", 16, top, style, g.globalTimer)
+	if status != "" {
+		warn := overlayText
+		warn.Color = color.RGBA{255, 60, 60, 255}
+		DrawText(screen, status, logicalWidth/2, top+30, warn, g.globalTimer)
+	}
+
+	style.Align = AlignRight
+	for i, ev := range events {
+		line := strings.ToUpper(strings.ReplaceAll(ev.Type, "_", " "))
+		if ev.Ghost != "" {
+			line += " " + CharacterName(ev.Ghost)
+		}
+		DrawText(screen, line, logicalWidth-16, top+i*20, style, g.globalTimer)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	themeManifestFile = "theme.json"
+	defaultThemeName  = "jjk"
+)
+
+// ThemeManifest is the theme.json at the root of a theme pack. The asset
+// sections use the same format as assets/manifest.json and replace entries
+// by logical name; file paths are relative to the pack.
+type ThemeManifest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	AssetManifest
+	Palette    map[string][4]uint8 `json:"palette"`
+	Characters map[string]string   `json:"characters"`
+}
+
+// ThemePack is a directory or zip file containing a theme.json
+type ThemePack struct {
+	Path     string
+	FS       fs.FS
+	Manifest *ThemeManifest
+}
+
+// Palette collects the colors that used to be hardcoded across the renderer
+type Palette struct {
+	Glow        color.RGBA // power pellet core
+	OuterGlow   color.RGBA // power pellet halo
+	Pellet      color.RGBA // regular pellet
+	PelletCore  color.RGBA // center of the pre-rendered pellet sprite
+	PelletGlow  color.RGBA // halo of the pre-rendered pellet sprite
+	PowerPellet color.RGBA // ring of the pre-rendered power pellet sprite
+	Wall        color.RGBA // maze wall outline
+}
+
+func defaultPalette() Palette {
+	return Palette{
+		Glow:        color.RGBA{255, 50, 50, 255},
+		OuterGlow:   color.RGBA{255, 50, 50, 100},
+		Pellet:      color.RGBA{255, 255, 0, 255},
+		PelletCore:  color.RGBA{255, 0, 0, 255},
+		PelletGlow:  color.RGBA{255, 0, 0, 128},
+		PowerPellet: color.RGBA{128, 0, 255, 220},
+		Wall:        color.RGBA{140, 70, 255, 255},
+	}
+}
+
+// Default display names, keyed by the same ids the manifest uses
+var defaultCharacterNames = map[string]string{
+	"player":  "Gojo Satoru",
+	"jogo":    "Jogo",
+	"sukuna":  "Sukuna",
+	"kenjaku": "Kenjaku",
+	"mahito":  "Mahito",
+}
+
+var (
+	palette        = defaultPalette()
+	characterNames = copyNames(defaultCharacterNames)
+	ActiveTheme    *ThemePack // nil means the built-in JJK theme
+)
+
+func copyNames(names map[string]string) map[string]string {
+	out := make(map[string]string, len(names))
+	for k, v := range names {
+		out[k] = v
+	}
+	return out
+}
+
+// CharacterName returns the display name of a character in the active theme
+func CharacterName(id string) string {
+	if name, ok := characterNames[id]; ok {
+		return name
+	}
+	return id
+}
+
+// Name returns the id used to select the pack in settings and the config
+func (t *ThemePack) Name() string {
+	if t == nil {
+		return defaultThemeName
+	}
+	return t.Manifest.Name
+}
+
+// OpenThemePack reads a theme pack from a directory or a .zip file
+func OpenThemePack(path string) (*ThemePack, error) {
+	var fsys fs.FS
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		// The reader stays open for the lifetime of the process
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		fsys = zr
+	} else {
+		fsys = os.DirFS(path)
+	}
+	return openThemeFS(fsys, path)
+}
+
+// openThemeFS reads the theme pack in fsys; path names it in messages and
+// gives the pack its name if theme.json doesn't
+func openThemeFS(fsys fs.FS, path string) (*ThemePack, error) {
+	data, err := fs.ReadFile(fsys, themeManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ThemeManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", themeManifestFile, err)
+	}
+	if manifest.Name == "" {
+		manifest.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return &ThemePack{Path: path, FS: fsys, Manifest: manifest}, nil
+}
+
+// userThemeDir is where players put theme packs of their own
+func userThemeDir() string {
+	return filepath.Join(configDir(), "themes")
+}
+
+// DiscoverThemes returns the built-in theme packs and every readable pack in
+// the user theme directory, sorted by name. A user pack with the name of a
+// built-in one, or of another user pack, is skipped.
+func DiscoverThemes() []*ThemePack {
+	var packs []*ThemePack
+	seen := map[string]bool{defaultThemeName: true}
+	add := func(pack *ThemePack) {
+		if seen[pack.Name()] {
+			return
+		}
+		seen[pack.Name()] = true
+		packs = append(packs, pack)
+	}
+
+	builtin := builtinThemeFS()
+	entries, _ := fs.ReadDir(builtin, ".")
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		sub, _ := fs.Sub(builtin, entry.Name())
+		pack, err := openThemeFS(sub, "themes/"+entry.Name())
+		if err != nil {
+			assetsLog.Warn("skipping built-in theme pack", "theme", entry.Name(), "err", err)
+			continue
+		}
+		add(pack)
+	}
+
+	// A missing directory just means there are no user packs
+	dir := userThemeDir()
+	entries, _ = os.ReadDir(dir)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && !strings.EqualFold(filepath.Ext(path), ".zip") {
+			continue
+		}
+		pack, err := OpenThemePack(path)
+		if err != nil {
+			assetsLog.Warn("skipping theme pack", "path", path, "err", err)
+			continue
+		}
+		add(pack)
+	}
+
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name() < packs[j].Name() })
+	return packs
+}
+
+// findTheme returns the pack with the given name, or nil for the built-in theme
+func findTheme(packs []*ThemePack, name string) *ThemePack {
+	for _, pack := range packs {
+		if pack.Name() == name {
+			return pack
+		}
+	}
+	if name != defaultThemeName {
+		assetsLog.Warn("theme not found, using default", "theme", name, "default", defaultThemeName)
+	}
+	return nil
+}
+
+// mergedWith returns a copy of m where every entry of over replaces the entry of the same name
+func (m *AssetManifest) mergedWith(over *AssetManifest) *AssetManifest {
+	merge := func(base, top map[string]AssetEntry) map[string]AssetEntry {
+		out := make(map[string]AssetEntry, len(base)+len(top))
+		for k, v := range base {
+			out[k] = v
+		}
+		for k, v := range top {
+			out[k] = v
+		}
+		return out
+	}
+
+	out := &AssetManifest{
+		Images:     merge(m.Images, over.Images),
+		Animations: merge(m.Animations, over.Animations),
+		Music:      merge(m.Music, over.Music),
+		SFX:        merge(m.SFX, over.SFX),
+		Fonts:      merge(m.Fonts, over.Fonts),
+		Atlases:    m.Atlases,
+	}
+	if len(over.Atlases) > 0 {
+		out.Atlases = over.Atlases
+	}
+	return out
+}
+
+// applyThemeStyle sets the palette and character names for a theme (nil resets to the defaults)
+func applyThemeStyle(theme *ThemePack) {
+	palette = defaultPalette()
+	characterNames = copyNames(defaultCharacterNames)
+	if theme == nil {
+		return
+	}
+
+	targets := map[string]*color.RGBA{
+		"glow":         &palette.Glow,
+		"outer_glow":   &palette.OuterGlow,
+		"pellet":       &palette.Pellet,
+		"pellet_core":  &palette.PelletCore,
+		"pellet_glow":  &palette.PelletGlow,
+		"power_pellet": &palette.PowerPellet,
+		"wall":         &palette.Wall,
+	}
+	for key, c := range theme.Manifest.Palette {
+		target, ok := targets[key]
+		if !ok {
+			assetsLog.Warn("unknown palette color", "theme", theme.Name(), "color", key)
+			continue
+		}
+		*target = color.RGBA{c[0], c[1], c[2], c[3]}
+	}
+
+	for id, name := range theme.Manifest.Characters {
+		characterNames[id] = name
+	}
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// Tournaments measure ghost AI changes. Each configuration (chase strategy,
+// speed table, wave pattern) plays the same seeds against the reference bot
+// in headless matches. A match runs the real ghost, player and bot code on
+// its own copy of the maze, following the rules of updateGame without
+// audio, pauses between rounds, versus or two-player handling.
+
+// TournamentConfig is one ghost setup to try
+type TournamentConfig struct {
+	Name     string             `json:"name"`
+	Strategy string             `json:"strategy"` // a chase strategy from ghostAI.go, "current" when empty
+	Speeds   map[string]float64 `json:"speeds"`   // speed factor per ghost, 1 when missing
+	Waves    []GhostWave        `json:"waves"`    // scatter/chase pattern, the classic one when empty
+}
+
+func (c TournamentConfig) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("config without a name")
+	}
+	if _, ok := chaseStrategies[c.strategy()]; !ok {
+		return fmt.Errorf("%s: unknown strategy %q, have %s", c.Name, c.Strategy, strings.Join(strategyNames(), ", "))
+	}
+	for name, f := range c.Speeds {
+		if !isCurse(name) {
+			return fmt.Errorf("%s: unknown ghost %q in speeds", c.Name, name)
+		}
+		if f <= 0 {
+			return fmt.Errorf("%s: speed of %s must be positive", c.Name, name)
+		}
+	}
+	for i, w := range c.Waves {
+		if w.Frames == 0 || w.Frames < -1 {
+			return fmt.Errorf("%s: wave %d lasts %d frames", c.Name, i+1, w.Frames)
+		}
+		if w.Mode != ChaseMode && w.Mode != ScatterMode {
+			return fmt.Errorf("%s: wave %d must be chase or scatter", c.Name, i+1)
+		}
+	}
+	return nil
+}
+
+func (c TournamentConfig) strategy() string {
+	if c.Strategy == "" {
+		return "current"
+	}
+	return c.Strategy
+}
+
+func strategyNames() []string {
+	var names []string
+	for name := range chaseStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isCurse(name string) bool {
+	for _, c := range netCurses {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GhostResult is how one ghost did in a match
+type GhostResult struct {
+	Catches     int     `json:"catches"`
+	AvgDistance float64 `json:"avg_distance"` // tiles from the player, over the whole match
+	StuckFrames int     `json:"stuck_frames"` // frames the ghost should have moved but didn't
+}
+
+// MatchResult is one headless match
+type MatchResult struct {
+	Config   string                 `json:"config"`
+	Seed     int64                  `json:"seed"`
+	Frames   int                    `json:"frames"`   // frames survived
+	Survived bool                   `json:"survived"` // still alive at the frame limit
+	Pellets  int                    `json:"pellets"`
+	Score    int                    `json:"score"`
+	Rounds   int                    `json:"rounds"` // rounds cleared
+	Ghosts   map[string]GhostResult `json:"ghosts"`
+}
+
+// match is a headless game
+type match struct {
+	level       [][]int
+	state       *GameStateStruct
+	manager     *GhostManager
+	ghosts      []*Ghost
+	player      *Player
+	frame       int
+	lives       int
+	round       int
+	pelletCount int
+	powerActive bool
+	powerTimer  int
+	over        bool
+
+	result   MatchResult
+	ghostRes []GhostResult
+	distance []float64 // sum over frames, in tiles
+	lastPos  [][2]float64
+}
+
+func newMatch(cfg TournamentConfig, seed int64) *match {
+	m := &match{
+		level: copyLevel(levelTemplate),
+		lives: 3,
+		round: 1,
+	}
+	m.state = &GameStateStruct{
+		Level:        m.level,
+		CurrentLevel: 1,
+		Rand:         rand.New(rand.NewSource(seed)),
+	}
+	m.manager = NewGhostManager(m.state)
+	if len(cfg.Waves) > 0 {
+		m.manager.Waves = cfg.Waves
+	}
+	m.ghosts = newCurses()
+	for _, ghost := range m.ghosts {
+		ghost.Chase = chaseStrategies[cfg.strategy()]
+		ghost.SpeedFactor = cfg.Speeds[ghost.GhostType]
+		m.manager.AddGhost(ghost)
+	}
+	resetCurses(m.ghosts, m.level)
+
+	m.player = NewPlayer(TileSize, TileSize, "player")
+	m.player.Controller = &GreedyBot{}
+	m.player.Observe = m.observe
+	m.pelletCount = countPelletsIn(m.level)
+
+	m.result = MatchResult{Config: cfg.Name, Seed: seed}
+	m.ghostRes = make([]GhostResult, len(m.ghosts))
+	m.distance = make([]float64, len(m.ghosts))
+	m.lastPos = make([][2]float64, len(m.ghosts))
+	for i, ghost := range m.ghosts {
+		m.lastPos[i] = [2]float64{ghost.X, ghost.Y}
+	}
+	return m
+}
+
+func (m *match) observe() *Observation {
+	obs := newObservation(m.frame, m.level, m.player, m.ghosts)
+	obs.PelletsLeft = m.pelletCount
+	obs.Score = m.player.Score
+	obs.Lives = m.lives
+	obs.Round = m.round
+	if m.powerActive {
+		obs.FrightTimer = m.powerTimer
+	}
+	return obs
+}
+
+// run plays until game over or the frame limit
+func (m *match) run(frames int) MatchResult {
+	for m.frame < frames && !m.over {
+		m.step()
+	}
+	m.result.Frames = m.frame
+	m.result.Survived = !m.over
+	m.result.Score = m.player.Score
+	m.result.Ghosts = map[string]GhostResult{}
+	for i, ghost := range m.ghosts {
+		r := m.ghostRes[i]
+		if m.frame > 0 {
+			r.AvgDistance = m.distance[i] / float64(m.frame)
+		}
+		m.result.Ghosts[ghost.GhostType] = r
+	}
+	return m.result
+}
+
+// step is one frame of updateGame
+func (m *match) step() {
+	m.frame++
+	st := m.state
+	st.PacmanX, st.PacmanY = m.player.X, m.player.Y
+	st.PacmanDirection = m.player.Direction
+	st.DotsRemaining = m.pelletCount
+	st.PowerPelletActive = m.powerActive
+	st.FrightModeActive = m.powerActive
+	st.GlobalTimer = m.frame
+	st.Ghosts = m.ghosts
+
+	score := m.player.Score
+	m.player.Update(m.level, TileSize)
+	m.result.Pellets += m.player.Score - score // Update scores a point per pellet it eats
+
+	if m.powerActive {
+		m.powerTimer--
+		if m.powerTimer <= 0 {
+			m.powerActive = false
+			st.FrightModeActive = false
+			for _, ghost := range m.ghosts {
+				if ghost.Mode == FrightenedMode {
+					ghost.ResetMode()
+				}
+			}
+		}
+	}
+
+	// Like updateGame, the manager updates the ghosts and then they're
+	// updated once more directly
+	m.manager.UpdateAll()
+	for _, ghost := range m.ghosts {
+		ghost.Update(st)
+	}
+	m.measureGhosts()
+
+	switch m.manager.CheckCollisions(m.player.X, m.player.Y) {
+	case "ghost_eaten":
+		m.player.Score += 200
+	case "player_caught":
+		m.lives--
+		for i, ghost := range m.ghosts {
+			if ghost == m.manager.LastCollider {
+				m.ghostRes[i].Catches++
+			}
+		}
+		m.resetPlayer()
+		if m.lives <= 0 {
+			m.over = true
+			return
+		}
+		m.resetGhosts()
+	}
+
+	m.eatPellet()
+	if m.pelletCount <= 0 {
+		m.result.Rounds++
+		m.round++
+		m.resetPlayer()
+		m.resetGhosts()
+		copyLevelInto(m.level, levelTemplate)
+		m.pelletCount = countPelletsIn(m.level)
+	}
+}
+
+// measureGhosts adds this frame to each ghost's distance and stuck time
+func (m *match) measureGhosts() {
+	px := (m.player.X + TileSize/2) / TileSize
+	py := (m.player.Y + TileSize/2) / TileSize
+	for i, ghost := range m.ghosts {
+		gx := (ghost.X + float64(ghost.Size)/2) / TileSize
+		gy := (ghost.Y + float64(ghost.Size)/2) / TileSize
+		m.distance[i] += math.Hypot(gx-px, gy-py)
+
+		waiting := ghost.Mode == InHouseMode && ghost.ReleaseTimer > 0
+		pos := [2]float64{ghost.X, ghost.Y}
+		if pos == m.lastPos[i] && !waiting {
+			m.ghostRes[i].StuckFrames++
+		}
+		m.lastPos[i] = pos
+	}
+}
+
+// eatPellet is checkPelletCollection
+func (m *match) eatPellet() {
+	x, y := int(m.player.X)/TileSize, int(m.player.Y)/TileSize
+	if y < 0 || y >= len(m.level) || x < 0 || x >= len(m.level[0]) {
+		return
+	}
+	switch m.level[y][x] {
+	case TilePellet:
+		m.level[y][x] = TileEmpty
+		m.player.Score += 10
+		m.pelletCount--
+		m.result.Pellets++
+	case TilePowerPellet:
+		m.level[y][x] = TileEmpty
+		m.player.Score += 50
+		m.pelletCount--
+		m.result.Pellets++
+		m.powerActive = true
+		m.powerTimer = 600
+		m.state.FrightModeActive = true
+		for _, ghost := range m.ghosts {
+			if ghost.Visible {
+				ghost.SetFrightened(600)
+			}
+		}
+		m.manager.TriggerFrightMode()
+	}
+}
+
+func (m *match) resetPlayer() {
+	m.player.X, m.player.Y = TileSize, TileSize
+	m.player.Direction = "right"
+}
+
+func (m *match) resetGhosts() {
+	resetCurses(m.ghosts, m.level)
+	m.powerActive = false
+	m.powerTimer = 0
+	m.state.FrightModeActive = false
+}
+
+// RunTournament plays games matches per config with seeds seed, seed+1, ...
+// on workers goroutines. Every config gets the same seeds. Results come back
+// grouped by config, in seed order.
+func RunTournament(configs []TournamentConfig, games, frames int, seed int64, workers int) []MatchResult {
+	results := make([]MatchResult, len(configs)*games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = newMatch(configs[i/games], seed+int64(i%games)).run(frames)
+			}
+		}()
+	}
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// TournamentSummary averages a config's matches
+type TournamentSummary struct {
+	Config       string                 `json:"config"`
+	Games        int                    `json:"games"`
+	SurvivalRate float64                `json:"survival_rate"`
+	Frames       float64                `json:"frames"`
+	Pellets      float64                `json:"pellets"`
+	Score        float64                `json:"score"`
+	Rounds       float64                `json:"rounds"`
+	Ghosts       map[string]GhostResult `json:"ghosts"` // Catches is the total, the rest are means
+}
+
+func summarize(config string, results []MatchResult) TournamentSummary {
+	s := TournamentSummary{Config: config, Games: len(results), Ghosts: map[string]GhostResult{}}
+	if len(results) == 0 {
+		return s
+	}
+	n := float64(len(results))
+	stuck := map[string]float64{}
+	for _, r := range results {
+		if r.Survived {
+			s.SurvivalRate += 1 / n
+		}
+		s.Frames += float64(r.Frames) / n
+		s.Pellets += float64(r.Pellets) / n
+		s.Score += float64(r.Score) / n
+		s.Rounds += float64(r.Rounds) / n
+		for name, g := range r.Ghosts {
+			sum := s.Ghosts[name]
+			sum.Catches += g.Catches
+			sum.AvgDistance += g.AvgDistance / n
+			s.Ghosts[name] = sum
+			stuck[name] += float64(g.StuckFrames) / n
+		}
+	}
+	for name, mean := range stuck {
+		g := s.Ghosts[name]
+		g.StuckFrames = int(math.Round(mean))
+		s.Ghosts[name] = g
+	}
+	return s
+}
+
+// runTournament implements the "tournament" command
+func runTournament(args []string) int {
+	flags := flag.NewFlagSet("tournament", flag.ExitOnError)
+	games := flags.Int("games", 20, "games per config")
+	frames := flags.Int("frames", 60*60*5, "frame limit per game (60 per second)")
+	seed := flags.Int64("seed", 1, "seed of the first game; game n uses seed+n")
+	workers := flags.Int("workers", runtime.NumCPU(), "games to run at once")
+	configPath := flags.String("config", "", "JSON list of configs; defaults to one per chase strategy")
+	outPath := flags.String("out", "", "write every game to this .csv or .json file")
+	flags.Parse(args)
+
+	configs, err := loadTournamentConfigs(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tournament: %v\n", err)
+		return 2
+	}
+	if *games < 1 || *frames < 1 || *workers < 1 {
+		fmt.Fprintln(os.Stderr, "tournament: -games, -frames and -workers must be positive")
+		return 2
+	}
+
+	results := RunTournament(configs, *games, *frames, *seed, *workers)
+
+	var summaries []TournamentSummary
+	for i, cfg := range configs {
+		n := *games
+		summaries = append(summaries, summarize(cfg.Name, results[i*n:(i+1)*n]))
+	}
+	printSummaries(summaries)
+
+	if *outPath != "" {
+		if err := writeTournamentReport(*outPath, summaries, results); err != nil {
+			fmt.Fprintf(os.Stderr, "tournament: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Wrote %s\n", *outPath)
+	}
+	return 0
+}
+
+func loadTournamentConfigs(path string) ([]TournamentConfig, error) {
+	var configs []TournamentConfig
+	if path == "" {
+		for _, name := range strategyNames() {
+			configs = append(configs, TournamentConfig{Name: name, Strategy: name})
+		}
+		return configs, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("%s: no configs", path)
+	}
+	seen := map[string]bool{}
+	for _, c := range configs {
+		if err := c.validate(); err != nil {
+			return nil, err
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("config %q appears twice", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return configs, nil
+}
+
+func printSummaries(summaries []TournamentSummary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "config\tgames\tsurvived\tframes\tpellets\tscore\trounds\t"
+	for _, name := range netCurses {
+		header += name + " catches\t" + name + " dist\t" + name + " stuck\t"
+	}
+	fmt.Fprintln(w, header)
+	for _, s := range summaries {
+		line := fmt.Sprintf("%s\t%d\t%.0f%%\t%.0f\t%.1f\t%.0f\t%.2f\t",
+			s.Config, s.Games, s.SurvivalRate*100, s.Frames, s.Pellets, s.Score, s.Rounds)
+		for _, name := range netCurses {
+			g := s.Ghosts[name]
+			line += fmt.Sprintf("%d\t%.1f\t%d\t", g.Catches, g.AvgDistance, g.StuckFrames)
+		}
+		fmt.Fprintln(w, line)
+	}
+	w.Flush()
+}
+
+// writeTournamentReport writes every game as CSV, or the summaries and
+// games as JSON, depending on the extension of path
+func writeTournamentReport(path string, summaries []TournamentSummary, results []MatchResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(struct {
+			Summaries []TournamentSummary `json:"summaries"`
+			Games     []MatchResult       `json:"games"`
+		}{summaries, results})
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+
+	w := csv.NewWriter(f)
+	header := []string{"config", "seed", "frames", "survived", "pellets", "score", "rounds"}
+	for _, name := range netCurses {
+		header = append(header, name+"_catches", name+"_avg_distance", name+"_stuck_frames")
+	}
+	w.Write(header)
+	for _, r := range results {
+		row := []string{
+			r.Config, strconv.FormatInt(r.Seed, 10), strconv.Itoa(r.Frames), strconv.FormatBool(r.Survived),
+			strconv.Itoa(r.Pellets), strconv.Itoa(r.Score), strconv.Itoa(r.Rounds),
+		}
+		for _, name := range netCurses {
+			g := r.Ghosts[name]
+			row = append(row, strconv.Itoa(g.Catches), strconv.FormatFloat(g.AvgDistance, 'f', 2, 64), strconv.Itoa(g.StuckFrames))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// PlayerBoard is one player's progress in alternating two-player mode. The
+// level grid is a private copy, so pellets eaten by one player stay on the
+// other player's board.
+type PlayerBoard struct {
+	Level [][]int
+	Score int
+	Lives int
+	Round int
+}
+
+func newPlayerBoard() *PlayerBoard {
+	return &PlayerBoard{Level: copyLevel(levelTemplate), Lives: 3, Round: 1}
+}
+
+// Snapshot returns a deep copy of the board
+func (b *PlayerBoard) Snapshot() PlayerBoard {
+	s := *b
+	s.Level = copyLevel(b.Level)
+	return s
+}
+
+func (b *PlayerBoard) Out() bool {
+	return b.Lives <= 0
+}
+
+// startTwoPlayer begins an alternating game with player 1
+func (g *Game) startTwoPlayer() {
+	g.twoPlayer = true
+	g.boards = [2]*PlayerBoard{newPlayerBoard(), newPlayerBoard()}
+	g.currentPlayer = 0
+	g.loadBoard(g.boards[0])
+}
+
+// saveBoard copies the live game into the current player's board
+func (g *Game) saveBoard() {
+	b := g.boards[g.currentPlayer]
+	copyLevelInto(b.Level, level)
+	b.Score = g.Player.Score
+	b.Lives = g.lives
+	b.Round = g.RoundNumber
+}
+
+// loadBoard makes a board the live game
+func (g *Game) loadBoard(b *PlayerBoard) {
+	copyLevelInto(level, b.Level)
+	g.Player.Score = b.Score
+	g.lives = b.Lives
+	g.RoundNumber = b.Round
+	g.resetPlayerPosition()
+	g.resetGhosts()
+	g.powerPelletActive = false
+	g.powerPelletTimer = 0
+	InitPellets(level, TileSize)
+	g.countPellets()
+}
+
+// swapPlayers is called after the current player lost a life. It hands the
+// board to the other player if they still have lives and reports whether the
+// game goes on at all.
+func (g *Game) swapPlayers() bool {
+	g.saveBoard()
+
+	next := 1 - g.currentPlayer
+	if g.boards[next].Out() {
+		next = g.currentPlayer
+	}
+	if g.boards[next].Out() {
+		return false
+	}
+
+	g.currentPlayer = next
+	g.loadBoard(g.boards[next])
+	g.State = StateRoundReady
+	g.ShowRoundReady = true
+	g.RoundReadyTimer = 0
+	stateLog.Info("player ready", "player", next+1)
+	return true
+}
+
+// twoPlayerSummary returns the final snapshot of both boards and the winner
+// (0 or 1, -1 for a draw)
+func (g *Game) twoPlayerSummary() ([2]PlayerBoard, int) {
+	results := [2]PlayerBoard{g.boards[0].Snapshot(), g.boards[1].Snapshot()}
+	switch {
+	case results[0].Score > results[1].Score:
+		return results, 0
+	case results[1].Score > results[0].Score:
+		return results, 1
+	}
+	return results, -1
+}
+
+func (g *Game) drawTwoPlayerSummary(screen *ebiten.Image, cx, cy int) {
+	results, winner := g.twoPlayerSummary()
+
+	title := overlayTitle
+	if winner >= 0 {
+		DrawText(screen, fmt.Sprintf("PLAYER %d WINS", winner+1), cx, cy-110, title, g.globalTimer)
+	} else {
+		DrawText(screen, "DRAW", cx, cy-110, title, g.globalTimer)
+	}
+
+	for i, r := range results {
+		style := overlayText
+		if i == winner {
+			style.Color = color.RGBA{255, 215, 0, 255}
+			style.Pulse = 0.2
+		}
+		line := fmt.Sprintf("PLAYER %d  %7d  ROUND %d", i+1, r.Score, r.Round)
+		DrawText(screen, line, cx, cy-20+i*36, style, g.globalTimer)
+	}
+	DrawText(screen, "PRESS SPACE TO RETURN TO MENU", cx, cy+80, overlayHint, g.globalTimer)
+}
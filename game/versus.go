@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Sides in versus mode
+const (
+	SidePlayer = iota // the human Gojo
+	SideGhost         // the human-driven curse
+)
+
+// Versus is a round of Gojo against a curse driven by a second local
+// player. Clearing the maze wins the round for Gojo, catching him wins it
+// for the curse.
+type Versus struct {
+	Ghost      *Ghost
+	Wins       [2]int
+	GhostScore int // the player's score is the usual Player.Score
+	Rounds     int
+}
+
+// startVersus hands one ghost to the second player. Player 1 keeps the
+// normal bindings minus any keys the ghost player uses.
+func (g *Game) startVersus() {
+	name := "sukuna"
+	ghostBindings := defaultGhostBindings()
+	playerBindings := defaultBindings()
+	if g.config != nil {
+		name = g.config.VersusGhost
+		ghostBindings = g.config.GhostBindings
+		playerBindings = g.config.Bindings
+	}
+
+	var ghost *Ghost
+	for _, gh := range g.Ghosts {
+		if gh.GhostType == name {
+			ghost = gh
+		}
+	}
+	if ghost == nil {
+		stateLog.Warn("versus ghost not found", "ghost", name, "using", g.Ghosts[0].GhostType)
+		ghost = g.Ghosts[0]
+	}
+
+	g.Player.Input = NewPlayerInputState(playerBindings.without(ghostBindings), 0)
+	ghost.Controller = &HumanGhostController{Input: NewPlayerInputState(ghostBindings, 1)}
+	g.versus = &Versus{Ghost: ghost}
+}
+
+// stopVersus gives the ghost back to the AI
+func (g *Game) stopVersus() {
+	if g.versus == nil {
+		return
+	}
+	g.versus.Ghost.Controller = nil
+	g.Player.Input = nil
+	g.versus = nil
+}
+
+// updateVersusInput samples the per-player inputs; the shared Input is
+// updated by Game.Update
+func (g *Game) updateVersusInput() {
+	if g.versus == nil {
+		return
+	}
+	g.Player.Input.Update()
+	if c, ok := g.versus.Ghost.Controller.(*HumanGhostController); ok {
+		c.Input.Update()
+	}
+}
+
+// winRound records who took the round
+func (v *Versus) winRound(side int) {
+	v.Wins[side]++
+	v.Rounds++
+	stateLog.Info("versus round won", "round", v.Rounds, "player", side+1)
+}
+
+// Points the ghost player earns for a catch
+const versusCatchPoints = 1000
+
+// onVersusCatch is called when a ghost catches the player in versus mode.
+// Every lost life is a round for the curses; the ghost player only scores
+// the catches they made themselves.
+func (g *Game) onVersusCatch(ghost *Ghost) {
+	if ghost == g.versus.Ghost {
+		g.versus.GhostScore += versusCatchPoints
+	}
+	g.versus.winRound(SideGhost)
+}
+
+// onVersusClear is called when the player clears the maze
+func (g *Game) onVersusClear() {
+	g.versus.winRound(SidePlayer)
+}
+
+func (g *Game) drawVersusHUD(screen *ebiten.Image) {
+	v := g.versus
+	style := hudLabelStyle
+	style.Align = AlignCenter
+	line := fmt.Sprintf("ROUNDS %d - %d", v.Wins[SidePlayer], v.Wins[SideGhost])
+	DrawText(screen, line, logicalWidth/2, playfieldRect().Max.Y+18, style, g.globalTimer)
+}
+
+func (g *Game) drawVersusSummary(screen *ebiten.Image, cx, cy int) {
+	v := g.versus
+	names := [2]string{
+		"P1 " + CharacterName("player"),
+		"P2 " + CharacterName(v.Ghost.GhostType),
+	}
+
+	winner := -1
+	switch {
+	case v.Wins[SidePlayer] > v.Wins[SideGhost]:
+		winner = SidePlayer
+	case v.Wins[SideGhost] > v.Wins[SidePlayer]:
+		winner = SideGhost
+	}
+	if winner >= 0 {
+		DrawText(screen, fmt.Sprintf("%s WINS", names[winner]), cx, cy-110, overlayTitle, g.globalTimer)
+	} else {
+		DrawText(screen, "DRAW", cx, cy-110, overlayTitle, g.globalTimer)
+	}
+
+	for side := SidePlayer; side <= SideGhost; side++ {
+		style := overlayText
+		if side == winner {
+			style.Color = color.RGBA{255, 215, 0, 255}
+			style.Pulse = 0.2
+		}
+		points := [2]int{g.Player.Score, v.GhostScore}[side]
+		line := fmt.Sprintf("%s  %d ROUNDS  %d PTS", names[side], v.Wins[side], points)
+		DrawText(screen, line, cx, cy-20+side*36, style, g.globalTimer)
+	}
+	DrawText(screen, "PRESS SPACE TO RETURN TO MENU", cx, cy+80, overlayHint, g.globalTimer)
+}
@@ -0,0 +1,241 @@
+// Package gym is a reinforcement-learning environment for Gojo's maze. It
+// runs a headless model of the game's rules (same maze, speeds, scores,
+// fright timer, ghost house and scatter/chase waves) without Ebiten, fast
+// enough for thousands of episodes a second.
+//
+//	env := gym.New(gym.Config{})
+//	obs := env.Reset(1)
+//	for done := false; !done; {
+//		var reward float64
+//		obs, reward, done, _ = env.Step(policy(obs))
+//		learn(reward)
+//	}
+//
+// The model is close to the game but not frame-exact: the ghosts move tile
+// to tile and turn only at tile centres. The player moves exactly as in the
+// game, tunnels included, and the ghosts cruise at the game's speed.
+package gym
+
+import "jk/maze"
+
+// Action is what the agent does for one step
+type Action int
+
+const (
+	Stay Action = iota
+	Up
+	Down
+	Left
+	Right
+	NumActions
+)
+
+var actionDirections = [NumActions]direction{Stay: none, Up: up, Down: down, Left: left, Right: right}
+
+// Observation channels, each a Height x Width plane of the maze
+const (
+	ChannelWalls        = iota
+	ChannelPellets      // 1 where a pellet is left
+	ChannelPowerPellets // 1 where a power pellet is left
+	ChannelPlayer       // 1 at the player's tile
+	ChannelGhost0       // 1 at each ghost's tile, one channel per ghost, 0 while it's dead
+	ChannelGhost1
+	ChannelGhost2
+	ChannelGhost3
+	ChannelFright // fraction of the power pellet left, at frightened ghosts' tiles
+	NumChannels
+)
+
+// Observation is a stack of channels in channel, row, column order, ready to
+// be copied into a tensor. Data belongs to the Env and is overwritten by the
+// next Reset or Step.
+type Observation struct {
+	Channels, Height, Width int
+	Data                    []float32
+}
+
+// At returns the value of channel c at tile (x, y)
+func (o Observation) At(c, x, y int) float32 {
+	return o.Data[(c*o.Height+y)*o.Width+x]
+}
+
+// Rewards shapes the reward for each step. Every field is added once per
+// occurrence during the step.
+type Rewards struct {
+	Pellet      float64
+	PowerPellet float64
+	GhostEaten  float64
+	Death       float64
+	RoundClear  float64
+	Step        float64 // added to every step, usually a small penalty
+}
+
+// DefaultRewards are used when Config.Rewards is left zero
+var DefaultRewards = Rewards{
+	Pellet:      1,
+	PowerPellet: 5,
+	GhostEaten:  20,
+	Death:       -50,
+	RoundClear:  100,
+}
+
+// Config sets up an Env. Zero fields take their defaults.
+type Config struct {
+	Level     *maze.Level // the maze and where the player and ghosts start; the classic level when nil
+	Rewards   Rewards
+	FrameSkip int // frames the action is held for each step, default 4
+	MaxSteps  int // steps before the episode is cut off, 0 for no limit
+	Lives     int // default 3
+}
+
+// Info describes the state after a step
+type Info struct {
+	Score       int
+	Lives       int
+	Round       int
+	Frame       int
+	Steps       int
+	PelletsLeft int
+	Truncated   bool   // the episode ended at MaxSteps rather than game over
+	Events      Events // what happened during the step
+}
+
+// Env is one environment. It is not safe for concurrent use; run one per
+// goroutine.
+type Env struct {
+	cfg   Config
+	world *world
+	steps int
+	obs   Observation
+}
+
+// New makes an environment. Call Reset before the first Step.
+func New(cfg Config) *Env {
+	if cfg.Level == nil {
+		cfg.Level = maze.ClassicLevel()
+	}
+	if cfg.Rewards == (Rewards{}) {
+		cfg.Rewards = DefaultRewards
+	}
+	if cfg.FrameSkip <= 0 {
+		cfg.FrameSkip = 4
+	}
+	if cfg.Lives <= 0 {
+		cfg.Lives = startLives
+	}
+	w := newWorld(cfg.Level)
+	height, width := cfg.Level.Height(), cfg.Level.Width()
+	return &Env{
+		cfg:   cfg,
+		world: w,
+		obs: Observation{
+			Channels: NumChannels,
+			Height:   height,
+			Width:    width,
+			Data:     make([]float32, NumChannels*height*width),
+		},
+	}
+}
+
+// Shape is the observation's channels, height and width
+func (e *Env) Shape() (int, int, int) {
+	return e.obs.Channels, e.obs.Height, e.obs.Width
+}
+
+// Reset starts a new episode. The same seed plays out the same way for
+// the same actions.
+func (e *Env) Reset(seed int64) Observation {
+	e.world.reset(seed, e.cfg.Lives)
+	e.steps = 0
+	e.observe()
+	return e.obs
+}
+
+// Step holds the action for FrameSkip frames and returns the new
+// observation, the reward earned, whether the episode is over, and details.
+func (e *Env) Step(a Action) (Observation, float64, bool, Info) {
+	dir := none
+	if a >= 0 && a < NumActions {
+		dir = actionDirections[a]
+	}
+	var ev Events
+	for i := 0; i < e.cfg.FrameSkip && !e.world.over; i++ {
+		ev.add(e.world.step(dir))
+	}
+	e.steps++
+
+	r := e.cfg.Rewards
+	reward := r.Step +
+		r.Pellet*float64(ev.Pellets) +
+		r.PowerPellet*float64(ev.PowerPellets) +
+		r.GhostEaten*float64(ev.GhostsEaten) +
+		r.Death*float64(ev.Deaths) +
+		r.RoundClear*float64(ev.RoundsCleared)
+
+	truncated := !e.world.over && e.cfg.MaxSteps > 0 && e.steps >= e.cfg.MaxSteps
+	w := e.world
+	info := Info{
+		Score:       w.score,
+		Lives:       w.lives,
+		Round:       w.round,
+		Frame:       w.frame,
+		Steps:       e.steps,
+		PelletsLeft: w.pelletsLeft,
+		Truncated:   truncated,
+		Events:      ev,
+	}
+	e.observe()
+	return e.obs, reward, w.over || truncated, info
+}
+
+// observe fills the observation from the world. The maze channels are
+// redrawn only after a refill; otherwise just the eaten tiles are cleared.
+func (e *Env) observe() {
+	o := &e.obs
+	plane := o.Height * o.Width
+	set := func(c, x, y int, v float32) {
+		if x >= 0 && x < o.Width && y >= 0 && y < o.Height {
+			o.Data[c*plane+y*o.Width+x] = v
+		}
+	}
+
+	w := e.world
+	if w.refilled {
+		clear(o.Data[:ChannelPlayer*plane])
+		for y, row := range w.level {
+			for x, t := range row {
+				switch t {
+				case maze.Wall:
+					set(ChannelWalls, x, y, 1)
+				case maze.Pellet:
+					set(ChannelPellets, x, y, 1)
+				case maze.PowerPellet:
+					set(ChannelPowerPellets, x, y, 1)
+				}
+			}
+		}
+		w.refilled = false
+	} else {
+		for _, t := range w.eaten {
+			set(ChannelPellets, t[0], t[1], 0)
+			set(ChannelPowerPellets, t[0], t[1], 0)
+		}
+	}
+	w.eaten = w.eaten[:0]
+
+	clear(o.Data[ChannelPlayer*plane:])
+	px, py := w.playerTile()
+	set(ChannelPlayer, px, py, 1)
+
+	fright := float32(w.frightTimer) / frightDuration
+	for i, g := range w.ghosts {
+		if i > ChannelGhost3-ChannelGhost0 || g.mode == dead {
+			continue
+		}
+		x, y := int(g.x+tileSize/2)/tileSize, int(g.y+tileSize/2)/tileSize
+		set(ChannelGhost0+i, x, y, 1)
+		if g.mode == frightened {
+			set(ChannelFright, x, y, fright)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+package gym
+
+import (
+	"math"
+	"math/rand"
+
+	"jk/maze"
+)
+
+// Rules of the game, in pixels and frames at 60 frames per second. The
+// game's ghosts have a speed of 0.8 but are moved twice a frame, once by
+// GhostManager.UpdateAll and once more by updateGame, and don't speed up
+// from round to round.
+const (
+	tileSize       = 32
+	playerSpeed    = 2.0
+	ghostBaseSpeed = 1.6
+	frightDuration = 600
+	startLives     = 3
+
+	pelletPoints      = 10
+	powerPelletPoints = 50
+	ghostPoints       = 200
+)
+
+// Scatter/chase waves: frames per wave, -1 for the rest of the round
+var waves = []struct {
+	frames int
+	mode   ghostMode
+}{
+	{420, scatter}, {1200, chase}, {420, scatter}, {1200, chase},
+	{300, scatter}, {1200, chase}, {300, scatter}, {-1, chase},
+}
+
+type ghostMode int
+
+const (
+	chase ghostMode = iota
+	scatter
+	frightened
+	dead
+	inHouse
+)
+
+type direction int
+
+const (
+	none direction = iota
+	up
+	down
+	left
+	right
+)
+
+var steps = [...][2]int{none: {0, 0}, up: {0, -1}, down: {0, 1}, left: {-1, 0}, right: {1, 0}}
+
+func (d direction) reverse() direction {
+	switch d {
+	case up:
+		return down
+	case down:
+		return up
+	case left:
+		return right
+	case right:
+		return left
+	}
+	return none
+}
+
+type ghost struct {
+	name    string
+	start   [2]int
+	release int    // frames the ghost waits in the house after a reset
+	corner  [2]int // scatter target
+
+	x, y       float64 // top-left, pixels
+	tileX      int     // last tile centre reached
+	tileY      int
+	dir        direction
+	mode       ghostMode
+	waitFrames int
+}
+
+// turnAround reverses the ghost. Between tiles it heads back to the tile it
+// came from; on a tile centre it picks a new way at the next update.
+func (g *ghost) turnAround() {
+	if g.dir == none {
+		return
+	}
+	if g.x == float64(g.tileX*tileSize) && g.y == float64(g.tileY*tileSize) {
+		g.dir = none
+		return
+	}
+	g.tileX += steps[g.dir][0]
+	g.tileY += steps[g.dir][1]
+	g.dir = g.dir.reverse()
+}
+
+// The four curses, in the order of the game's ghost list, starting where
+// the game's setLevel puts them in l and scattering to the corners of the
+// game's Ghost.SetScatterCorner
+func newGhosts(l *maze.Level) []*ghost {
+	w, h := l.Width(), l.Height()
+	ghosts := []*ghost{
+		{name: "jogo", release: 0, corner: [2]int{w - 2, 0}},
+		{name: "sukuna", release: 600, corner: [2]int{2, 0}},
+		{name: "kenjaku", release: 900, corner: [2]int{w - 2, h}},
+		{name: "mahito", release: 1200, corner: [2]int{2, h}},
+	}
+	for i, p := range l.GhostStarts() {
+		ghosts[i].start = [2]int{p.X, p.Y}
+	}
+	return ghosts
+}
+
+// Events counts what happened during some frames
+type Events struct {
+	Pellets, PowerPellets, GhostsEaten, Deaths, RoundsCleared int
+}
+
+func (e *Events) add(o Events) {
+	e.Pellets += o.Pellets
+	e.PowerPellets += o.PowerPellets
+	e.GhostsEaten += o.GhostsEaten
+	e.Deaths += o.Deaths
+	e.RoundsCleared += o.RoundsCleared
+}
+
+// world is the headless simulation
+type world struct {
+	template [][]int
+	level    [][]int
+	rng      *rand.Rand
+
+	// Player start and the ghost house, in tiles
+	playerStart [2]int
+	houseCenter [2]int
+	houseDoor   [2]int // only ghosts going home or leaving may use it
+	houseExit   [2]int // first tile outside
+
+	px, py   float64 // player top-left, pixels
+	pdir     direction
+	ghosts   []*ghost
+	homeDist [][]int // walking distance to the house centre
+	exitDist [][]int // walking distance to the tile outside the door
+
+	frame       int
+	score       int
+	lives       int
+	round       int
+	pelletsLeft int
+	frightTimer int
+	wave        int
+	waveTimer   int
+	over        bool
+
+	// For the observation: tiles eaten since it was last drawn, or refilled
+	// when the whole maze has to be redrawn
+	eaten    [][2]int
+	refilled bool
+}
+
+func newWorld(l *maze.Level) *world {
+	exit := l.HouseExit()
+	w := &world{
+		template:    maze.Copy(l.Tiles),
+		level:       maze.Copy(l.Tiles),
+		ghosts:      newGhosts(l),
+		playerStart: [2]int{l.Start.X, l.Start.Y},
+		houseCenter: [2]int{l.Door.X, l.House.Y + l.House.H/2},
+		houseDoor:   [2]int{l.Door.X, l.Door.Y},
+		houseExit:   [2]int{exit.X, exit.Y},
+	}
+	w.homeDist = w.distances(w.houseCenter)
+	w.exitDist = w.distances(w.houseExit)
+	return w
+}
+
+func (w *world) reset(seed int64, lives int) {
+	w.rng = rand.New(rand.NewSource(seed))
+	w.frame, w.score, w.lives, w.round = 0, 0, lives, 1
+	w.over = false
+	w.refill()
+	w.resetActors()
+}
+
+func (w *world) refill() {
+	w.refilled = true
+	w.pelletsLeft = 0
+	for y, row := range w.template {
+		copy(w.level[y], row)
+		for _, t := range row {
+			if t == maze.Pellet || t == maze.PowerPellet {
+				w.pelletsLeft++
+			}
+		}
+	}
+}
+
+// resetActors puts everyone back at the start, as after a death or a
+// cleared round
+func (w *world) resetActors() {
+	w.px, w.py = float64(w.playerStart[0]*tileSize), float64(w.playerStart[1]*tileSize)
+	w.pdir = right
+	w.frightTimer = 0
+	w.wave, w.waveTimer = 0, 0
+	for _, g := range w.ghosts {
+		g.tileX, g.tileY = g.start[0], g.start[1]
+		g.x, g.y = float64(g.tileX*tileSize), float64(g.tileY*tileSize)
+		g.dir = none
+		g.mode = inHouse
+		g.waitFrames = g.release
+	}
+}
+
+// step advances one frame with the player pushing in dir
+func (w *world) step(dir direction) Events {
+	var ev Events
+	if w.over {
+		return ev
+	}
+	w.frame++
+	w.movePlayer(dir)
+	w.eat(&ev)
+	w.updateWaves()
+	if w.frightTimer > 0 {
+		w.frightTimer--
+		if w.frightTimer == 0 {
+			for _, g := range w.ghosts {
+				if g.mode == frightened {
+					g.mode = waves[w.wave].mode
+				}
+			}
+		}
+	}
+	for _, g := range w.ghosts {
+		w.updateGhost(g)
+	}
+	w.collide(&ev)
+	if !w.over && w.pelletsLeft == 0 {
+		ev.RoundsCleared++
+		w.round++
+		w.refill()
+		w.resetActors()
+	}
+	return ev
+}
+
+// movePlayer moves like the game's player: only while a direction is held,
+// not into a wall, and round through the tunnels
+func (w *world) movePlayer(dir direction) {
+	if dir == none {
+		return
+	}
+	s := steps[dir]
+	nx, ny := w.px+float64(s[0])*playerSpeed, w.py+float64(s[1])*playerSpeed
+	if s[0] != 0 {
+		if x, ok := w.tunnelMove(nx, ny); ok {
+			w.px, w.pdir = x, dir
+			return
+		}
+	}
+	if !w.boxHitsWall(nx, ny) {
+		w.px, w.py = nx, ny
+		w.pdir = dir
+	}
+}
+
+// boxHitsWall checks the corners of a tile-sized box; outside the maze is
+// wall. Tiles are found by truncating, as the game does, so a box up to a
+// tile past the left or top edge still counts as on the first tile.
+func (w *world) boxHitsWall(x, y float64) bool {
+	for _, c := range [4][2]float64{{x, y}, {x + tileSize - 1, y}, {x, y + tileSize - 1}, {x + tileSize - 1, y + tileSize - 1}} {
+		tx, ty := int(c[0])/tileSize, int(c[1])/tileSize
+		if !w.inside(tx, ty) || w.level[ty][tx] == maze.Wall {
+			return true
+		}
+	}
+	return false
+}
+
+// tunnelMove is the game's tunnelMove: in a tunnel row a box may stick out
+// of the side of the maze over open tiles, and comes back in on the other
+// side once its centre crosses the edge. ok is false when the box is inside
+// the maze or not in a tunnel.
+func (w *world) tunnelMove(x, y float64) (float64, bool) {
+	row := int(y) / tileSize
+	if y < 0 || int(y)%tileSize != 0 || row >= len(w.level) || !maze.Tunnel(w.level[row]) {
+		return x, false
+	}
+	n := len(w.level[row])
+	width := float64(n * tileSize)
+	if x >= 0 && x+tileSize <= width {
+		return x, false
+	}
+
+	if centre := x + tileSize/2; centre < 0 {
+		x += width
+	} else if centre >= width {
+		x -= width
+	}
+	for _, cx := range []float64{x, x + tileSize - 1} {
+		tx := int(math.Floor(cx / tileSize))
+		if w.level[row][(tx%n+n)%n] == maze.Wall {
+			return x, false
+		}
+	}
+	return x, true
+}
+
+func (w *world) inside(x, y int) bool {
+	return y >= 0 && y < len(w.level) && x >= 0 && x < len(w.level[y])
+}
+
+func (w *world) playerTile() (int, int) {
+	return int(w.px+tileSize/2) / tileSize, int(w.py+tileSize/2) / tileSize
+}
+
+func (w *world) eat(ev *Events) {
+	tx, ty := w.playerTile()
+	if !w.inside(tx, ty) {
+		return
+	}
+	switch w.level[ty][tx] {
+	case maze.Pellet, maze.PowerPellet:
+		w.eaten = append(w.eaten, [2]int{tx, ty})
+	}
+	switch w.level[ty][tx] {
+	case maze.Pellet:
+		w.level[ty][tx] = maze.Empty
+		w.score += pelletPoints
+		w.pelletsLeft--
+		ev.Pellets++
+	case maze.PowerPellet:
+		w.level[ty][tx] = maze.Empty
+		w.score += powerPelletPoints
+		w.pelletsLeft--
+		ev.PowerPellets++
+		w.frightTimer = frightDuration
+		for _, g := range w.ghosts {
+			if g.mode == chase || g.mode == scatter {
+				g.mode = frightened
+				g.turnAround()
+			}
+		}
+	}
+}
+
+func (w *world) updateWaves() {
+	if w.frightTimer > 0 {
+		return // the wave clock pauses during fright, as in the arcade
+	}
+	wave := waves[w.wave]
+	if wave.frames < 0 {
+		return
+	}
+	w.waveTimer++
+	if w.waveTimer < wave.frames {
+		return
+	}
+	w.wave++
+	w.waveTimer = 0
+	for _, g := range w.ghosts {
+		if g.mode == chase || g.mode == scatter {
+			g.mode = waves[w.wave].mode
+			g.turnAround()
+		}
+	}
+}
+
+func (w *world) ghostSpeed(g *ghost) float64 {
+	speed := ghostBaseSpeed
+	switch g.mode {
+	case frightened:
+		return speed * 0.5
+	case dead:
+		return speed * 2
+	}
+	return speed
+}
+
+func (w *world) updateGhost(g *ghost) {
+	if g.mode == inHouse && g.waitFrames > 0 {
+		g.waitFrames--
+		return
+	}
+	dist := w.ghostSpeed(g)
+	for dist > 0 {
+		if g.dir == none {
+			g.dir = w.chooseDirection(g)
+			if g.dir == none {
+				return
+			}
+		}
+		s := steps[g.dir]
+		nextX, nextY := g.tileX+s[0], g.tileY+s[1]
+		cx, cy := float64(nextX*tileSize), float64(nextY*tileSize)
+		remaining := abs(cx-g.x) + abs(cy-g.y)
+		if dist < remaining {
+			g.x += float64(s[0]) * dist
+			g.y += float64(s[1]) * dist
+			return
+		}
+		g.x, g.y = cx, cy
+		g.tileX, g.tileY = nextX, nextY
+		dist -= remaining
+		w.arrive(g)
+		g.dir = w.chooseDirection(g)
+	}
+}
+
+// arrive handles reaching the end of a trip through the house
+func (w *world) arrive(g *ghost) {
+	at := [2]int{g.tileX, g.tileY}
+	switch {
+	case g.mode == dead && at == w.houseCenter:
+		g.mode = inHouse
+		g.waitFrames = 0
+	case g.mode == inHouse && at == w.houseExit:
+		g.mode = waves[w.wave].mode
+	}
+}
+
+// chooseDirection picks the way to go from the ghost's current tile
+func (w *world) chooseDirection(g *ghost) direction {
+	switch g.mode {
+	case dead:
+		return w.downhill(g, w.homeDist)
+	case inHouse:
+		return w.downhill(g, w.exitDist)
+	}
+
+	var options []direction
+	for d := up; d <= right; d++ {
+		if d == g.dir.reverse() && g.dir != none {
+			continue
+		}
+		if w.ghostCanEnter(g.tileX+steps[d][0], g.tileY+steps[d][1]) {
+			options = append(options, d)
+		}
+	}
+	if len(options) == 0 {
+		if back := g.dir.reverse(); back != none && w.ghostCanEnter(g.tileX+steps[back][0], g.tileY+steps[back][1]) {
+			return back
+		}
+		return none
+	}
+	if g.mode == frightened {
+		return options[w.rng.Intn(len(options))]
+	}
+
+	target := w.target(g)
+	best, bestDist := none, 1<<30
+	for _, d := range options {
+		dx := g.tileX + steps[d][0] - target[0]
+		dy := g.tileY + steps[d][1] - target[1]
+		if dist := dx*dx + dy*dy; dist < bestDist {
+			best, bestDist = d, dist
+		}
+	}
+	return best
+}
+
+// target is the tile a chasing or scattering ghost heads for, as in the
+// game's updateChaseTarget
+func (w *world) target(g *ghost) [2]int {
+	if g.mode == scatter {
+		return g.corner
+	}
+	px, py := w.playerTile()
+	if g.name == "sukuna" {
+		s := steps[w.pdir]
+		return [2]int{px + 2*s[0], py + 2*s[1]}
+	}
+	return [2]int{px, py}
+}
+
+func (w *world) ghostCanEnter(x, y int) bool {
+	return w.inside(x, y) && w.level[y][x] != maze.Wall && [2]int{x, y} != w.houseDoor
+}
+
+// downhill steps to the neighbour closest to the goal of a distance map
+func (w *world) downhill(g *ghost, dist [][]int) direction {
+	best, bestDist := none, dist[g.tileY][g.tileX]
+	for d := up; d <= right; d++ {
+		x, y := g.tileX+steps[d][0], g.tileY+steps[d][1]
+		if w.inside(x, y) && dist[y][x] >= 0 && dist[y][x] < bestDist {
+			best, bestDist = d, dist[y][x]
+		}
+	}
+	return best
+}
+
+// distances is the walking distance from every tile to goal, -1 for walls
+// and unreachable tiles. Paths may use the house door.
+func (w *world) distances(goal [2]int) [][]int {
+	dist := make([][]int, len(w.template))
+	for y, row := range w.template {
+		dist[y] = make([]int, len(row))
+		for x := range dist[y] {
+			dist[y][x] = -1
+		}
+	}
+	if !w.inside(goal[0], goal[1]) {
+		return dist
+	}
+	dist[goal[1]][goal[0]] = 0
+	queue := [][2]int{goal}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for d := up; d <= right; d++ {
+			x, y := cur[0]+steps[d][0], cur[1]+steps[d][1]
+			if !w.inside(x, y) || dist[y][x] >= 0 || w.template[y][x] == maze.Wall {
+				continue
+			}
+			dist[y][x] = dist[cur[1]][cur[0]] + 1
+			queue = append(queue, [2]int{x, y})
+		}
+	}
+	return dist
+}
+
+// collide resolves touching ghosts: centres closer than a tile
+func (w *world) collide(ev *Events) {
+	for _, g := range w.ghosts {
+		dx, dy := g.x-w.px, g.y-w.py
+		if dx*dx+dy*dy >= tileSize*tileSize {
+			continue
+		}
+		switch g.mode {
+		case frightened:
+			g.mode = dead
+			w.score += ghostPoints
+			ev.GhostsEaten++
+		case chase, scatter:
+			ev.Deaths++
+			w.lives--
+			if w.lives <= 0 {
+				w.over = true
+				return
+			}
+			w.resetActors()
+			return
+		}
+	}
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,212 @@
+package maze
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Smallest maze Generate makes; anything smaller can't fit the ghost house
+// with a corridor round it and a maze either side.
+const (
+	MinGenerateWidth  = 15
+	MinGenerateHeight = 15
+)
+
+// Generate makes a random maze in the style of the classic one: mirrored
+// left to right, without dead ends, with the ghost house in the middle, a
+// tunnel through the sides and a power pellet near each corner. The same
+// seed and size always give the same maze. The maze is a lattice of
+// corridors on odd tiles with the middle column one of them, so the height
+// is rounded down to an odd number and the width to one less than a
+// multiple of four.
+func Generate(seed int64, width, height int) (*Level, error) {
+	if width < MinGenerateWidth || height < MinGenerateHeight {
+		return nil, fmt.Errorf("maze must be at least %dx%d, not %dx%d", MinGenerateWidth, MinGenerateHeight, width, height)
+	}
+	g := &generator{
+		rng: rand.New(rand.NewSource(seed)),
+		w:   width - (width-3)%4,
+		h:   height - 1 + height%2,
+	}
+	l := g.generate()
+	if problem, ok := FirstError(Validate(l)); ok {
+		// A bug here rather than bad input, but don't hand out a broken maze
+		return nil, fmt.Errorf("generated maze %d is invalid: %s", seed, problem)
+	}
+	return l, nil
+}
+
+type generator struct {
+	rng   *rand.Rand
+	w, h  int
+	tiles [][]int
+	house Rect // inside of the house
+	wall  Rect // the house and its wall, which corridors keep out of
+}
+
+func (g *generator) generate() *Level {
+	g.tiles = make([][]int, g.h)
+	for y := range g.tiles {
+		g.tiles[y] = make([]int, g.w)
+		for x := range g.tiles[y] {
+			g.tiles[y][x] = Wall
+		}
+	}
+
+	// The house is 5x3 inside, its wall on even tiles so that the corridor
+	// round it runs along the lattice
+	cx := g.w / 2
+	top := (g.h/2 - 2) &^ 1
+	g.house = Rect{cx - 2, top + 1, 5, 3}
+	g.wall = Rect{cx - 3, top, 7, 5}
+	for x := g.wall.X - 1; x <= g.wall.X+g.wall.W; x++ {
+		g.open(Point{x, g.wall.Y - 1})
+		g.open(Point{x, g.wall.Y + g.wall.H})
+	}
+	for y := g.wall.Y - 1; y <= g.wall.Y+g.wall.H; y++ {
+		g.open(Point{g.wall.X - 1, y})
+		g.open(Point{g.wall.X + g.wall.W, y})
+	}
+
+	g.carve()
+	g.removeDeadEnds()
+	g.loops(g.w * g.h / 60)
+
+	// Tunnel through both sides on a row in the middle third
+	rows := (g.h/3 + 1) / 2
+	tunnel := (g.h/3 | 1) + 2*g.rng.Intn(max(rows, 1))
+	g.open(Point{0, tunnel})
+
+	l := &Level{
+		House: g.house,
+		Door:  Point{cx, top},
+		Fruit: Point{cx, g.wall.Y + g.wall.H},
+		Start: Point{cx, g.h*3/4 | 1},
+	}
+	for y := g.house.Y; y < g.house.Y+g.house.H; y++ {
+		for x := g.house.X; x < g.house.X+g.house.W; x++ {
+			g.tiles[y][x] = Empty
+		}
+	}
+	g.tiles[l.Door.Y][l.Door.X] = Empty
+
+	// Pellets everywhere in the maze proper; the house, its corridor, the
+	// tunnel mouths and the spawn points stay clear
+	around := Rect{g.wall.X - 1, g.wall.Y - 1, g.wall.W + 2, g.wall.H + 2}
+	for y, row := range g.tiles {
+		for x, t := range row {
+			p := Point{x, y}
+			if t == Empty && !around.Contains(p) && x > 0 && x < g.w-1 && p != l.Start {
+				row[x] = Pellet
+			}
+		}
+	}
+	for _, p := range []Point{{1, 3}, {1, g.h - 4}} {
+		g.tiles[p.Y][p.X] = PowerPellet
+		g.tiles[p.Y][g.w-1-p.X] = PowerPellet
+	}
+	l.Tiles = g.tiles
+	return l
+}
+
+// open clears p and its mirror image
+func (g *generator) open(p Point) {
+	g.tiles[p.Y][p.X] = Empty
+	g.tiles[p.Y][g.w-1-p.X] = Empty
+}
+
+// node reports whether p is a corridor crossing the maze can be carved to:
+// odd on both axes, inside the border and clear of the house
+func (g *generator) node(p Point) bool {
+	return p.X > 0 && p.X < g.w-1 && p.Y > 0 && p.Y < g.h-1 &&
+		p.X%2 == 1 && p.Y%2 == 1 && !g.wall.Contains(p)
+}
+
+// links are the nodes two tiles from p, with the tile joining each
+func (g *generator) links(p Point) (nodes, joins []Point) {
+	for _, d := range [4]Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		n := Point{p.X + 2*d.X, p.Y + 2*d.Y}
+		if g.node(n) {
+			nodes = append(nodes, n)
+			joins = append(joins, Point{p.X + d.X, p.Y + d.Y})
+		}
+	}
+	return nodes, joins
+}
+
+// carve digs a random spanning tree through the left half, middle column
+// included, so that with its mirror image every node is connected
+func (g *generator) carve() {
+	cx := g.w / 2
+	start := Point{1, 1}
+	seen := map[Point]bool{start: true}
+	stack := []Point{start}
+	g.open(start)
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		nodes, joins := g.links(p)
+		var next []int
+		for i, n := range nodes {
+			if n.X <= cx && !seen[n] {
+				next = append(next, i)
+			}
+		}
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		i := next[g.rng.Intn(len(next))]
+		seen[nodes[i]] = true
+		g.open(joins[i])
+		g.open(nodes[i])
+		stack = append(stack, nodes[i])
+	}
+}
+
+// degree counts the open tiles next to p
+func (g *generator) degree(p Point) int {
+	n := 0
+	for _, d := range [4]Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		q := Point{p.X + d.X, p.Y + d.Y}
+		if q.X >= 0 && q.X < g.w && q.Y >= 0 && q.Y < g.h && g.tiles[q.Y][q.X] != Wall {
+			n++
+		}
+	}
+	return n
+}
+
+// removeDeadEnds joins every node with one way out to another neighbour
+func (g *generator) removeDeadEnds() {
+	for y := 1; y < g.h-1; y += 2 {
+		for x := 1; x <= g.w/2; x += 2 {
+			p := Point{x, y}
+			if !g.node(p) || g.degree(p) >= 2 {
+				continue
+			}
+			_, joins := g.links(p)
+			var closed []Point
+			for _, j := range joins {
+				if g.tiles[j.Y][j.X] == Wall {
+					closed = append(closed, j)
+				}
+			}
+			g.open(closed[g.rng.Intn(len(closed))])
+		}
+	}
+}
+
+// loops opens up to n more walls between nodes for extra ways round
+func (g *generator) loops(n int) {
+	for tries := 0; n > 0 && tries < 20*n; tries++ {
+		p := Point{1 + 2*g.rng.Intn(g.w/4+1), 1 + 2*g.rng.Intn(g.h/2)}
+		if !g.node(p) {
+			continue
+		}
+		_, joins := g.links(p)
+		j := joins[g.rng.Intn(len(joins))]
+		if g.tiles[j.Y][j.X] == Wall {
+			g.open(j)
+			n--
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package maze
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var generateSizes = [][2]int{{15, 15}, {16, 18}, {19, 21}, {27, 31}, {28, 36}, {41, 35}, {60, 45}}
+
+func TestGenerate(t *testing.T) {
+	for _, size := range generateSizes {
+		for seed := int64(0); seed < 50; seed++ {
+			t.Run(fmt.Sprintf("%dx%d/%d", size[0], size[1], seed), func(t *testing.T) {
+				l, err := Generate(seed, size[0], size[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				// Warnings too: a generated maze has no dead ends
+				if problems := Validate(l); len(problems) > 0 {
+					t.Errorf("%d problem(s), the first is %s", len(problems), problems[0])
+				}
+				for y, row := range l.Tiles {
+					for x, tile := range row {
+						if mirror := row[len(row)-1-x]; tile != mirror {
+							t.Fatalf("(%d,%d) is %d but its mirror image is %d", x, y, tile, mirror)
+						}
+					}
+				}
+				again, err := Generate(seed, size[0], size[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(again, l) {
+					t.Fatal("the same seed made a different maze")
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateTooSmall(t *testing.T) {
+	if _, err := Generate(1, MinGenerateWidth-1, MinGenerateHeight); err == nil {
+		t.Error("made a maze narrower than the minimum")
+	}
+	if _, err := Generate(1, MinGenerateWidth, MinGenerateHeight-1); err == nil {
+		t.Error("made a maze shorter than the minimum")
+	}
+}
@@ -0,0 +1,258 @@
+package maze
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Point is a tile position
+type Point struct {
+	X, Y int
+}
+
+// Rect is a block of tiles
+type Rect struct {
+	X, Y, W, H int
+}
+
+// Contains reports whether p is inside r
+func (r Rect) Contains(p Point) bool {
+	return p.X >= r.X && p.X < r.X+r.W && p.Y >= r.Y && p.Y < r.Y+r.H
+}
+
+// Level is a maze plus where things go in it
+type Level struct {
+	Tiles [][]int
+	Start Point // where the player starts
+	House Rect  // inside of the ghost house, which the ghosts start in
+	Door  Point // opening the ghosts leave the house through
+	Fruit Point // where a bonus fruit appears
+}
+
+// ClassicLevel is the built-in maze with its usual positions
+func ClassicLevel() *Level {
+	return &Level{
+		Tiles: Classic(),
+		Start: Point{1, 1},
+		House: Rect{11, 12, 3, 3},
+		Door:  Point{12, 11},
+		Fruit: Point{13, 16},
+	}
+}
+
+// HouseExit is the tile just outside the door, away from the house
+func (l *Level) HouseExit() Point {
+	h, d := l.House, l.Door
+	switch {
+	case d.Y < h.Y:
+		d.Y--
+	case d.Y >= h.Y+h.H:
+		d.Y++
+	case d.X < h.X:
+		d.X--
+	case d.X >= h.X+h.W:
+		d.X++
+	}
+	return d
+}
+
+// GhostStarts are the tiles the four curses start on: the middle of the
+// house, either side of it and below it
+func (l *Level) GhostStarts() []Point {
+	h := l.House
+	cx, cy := h.X+h.W/2, h.Y+h.H/2
+	return []Point{
+		{cx, cy},
+		{max(h.X, cx-1), cy},
+		{min(h.X+h.W-1, cx+1), cy},
+		{cx, min(h.Y+h.H-1, cy+1)},
+	}
+}
+
+// Copy returns a deep copy of the level
+func (l *Level) Copy() *Level {
+	c := *l
+	c.Tiles = Copy(l.Tiles)
+	return &c
+}
+
+// Width is the length of the longest row
+func (l *Level) Width() int {
+	w := 0
+	for _, row := range l.Tiles {
+		w = max(w, len(row))
+	}
+	return w
+}
+
+// Height is the number of rows
+func (l *Level) Height() int {
+	return len(l.Tiles)
+}
+
+// In reports whether p is on the grid
+func (l *Level) In(p Point) bool {
+	return p.Y >= 0 && p.Y < len(l.Tiles) && p.X >= 0 && p.X < len(l.Tiles[p.Y])
+}
+
+// At returns the tile at p, Wall when p is off the grid
+func (l *Level) At(p Point) int {
+	if !l.In(p) {
+		return Wall
+	}
+	return l.Tiles[p.Y][p.X]
+}
+
+// Level files are text, one character per tile and one line per row:
+//
+//	#  wall           .  pellet        o  power pellet
+//	   (space) empty  P  player start  H  ghost house
+//	-  house door     F  fruit spawn
+//
+// Lines starting with ; are comments. Rows are not padded, so a row that
+// lost its trailing spaces is reported by Validate.
+var (
+	tileChars   = map[int]byte{Empty: ' ', Wall: '#', Pellet: '.', Player: ' ', PowerPellet: 'o'}
+	errNoMarker = errors.New("missing")
+)
+
+// Parse reads a level file
+func Parse(r io.Reader) (*Level, error) {
+	l := &Level{}
+	var house []Point
+	var start, door, fruit []Point
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(text, ";") {
+			continue
+		}
+		y := len(l.Tiles)
+		row := make([]int, len(text))
+		for x, ch := range []byte(text) {
+			p := Point{x, y}
+			switch ch {
+			case '#':
+				row[x] = Wall
+			case '.':
+				row[x] = Pellet
+			case 'o':
+				row[x] = PowerPellet
+			case ' ':
+				row[x] = Empty
+			case 'P':
+				row[x] = Empty // the start is kept in l.Start
+				start = append(start, p)
+			case 'H':
+				house = append(house, p)
+			case '-':
+				door = append(door, p)
+			case 'F':
+				fruit = append(fruit, p)
+			default:
+				return nil, fmt.Errorf("line %d: unknown tile %q", line, ch)
+			}
+		}
+		l.Tiles = append(l.Tiles, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(l.Tiles) == 0 {
+		return nil, errors.New("empty level")
+	}
+
+	var err error
+	if l.Start, err = oneMarker(start, "player start P"); err != nil {
+		return nil, err
+	}
+	if l.Door, err = oneMarker(door, "house door -"); err != nil {
+		return nil, err
+	}
+	if l.Fruit, err = oneMarker(fruit, "fruit spawn F"); err != nil {
+		return nil, err
+	}
+	if len(house) == 0 {
+		return nil, fmt.Errorf("ghost house H %w", errNoMarker)
+	}
+	l.House = bounds(house)
+	return l, nil
+}
+
+func oneMarker(found []Point, name string) (Point, error) {
+	switch len(found) {
+	case 0:
+		return Point{}, fmt.Errorf("%s %w", name, errNoMarker)
+	case 1:
+		return found[0], nil
+	}
+	return Point{}, fmt.Errorf("more than one %s, at %v and %v", name, found[0], found[1])
+}
+
+// bounds is the smallest Rect holding every point
+func bounds(points []Point) Rect {
+	minX, minY, maxX, maxY := points[0].X, points[0].Y, points[0].X, points[0].Y
+	for _, p := range points[1:] {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	return Rect{minX, minY, maxX - minX + 1, maxY - minY + 1}
+}
+
+// Format writes the level in the level file format
+func (l *Level) Format(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for y, row := range l.Tiles {
+		line := make([]byte, len(row))
+		for x, t := range row {
+			p := Point{x, y}
+			switch {
+			case p == l.Start:
+				line[x] = 'P'
+			case p == l.Door:
+				line[x] = '-'
+			case p == l.Fruit:
+				line[x] = 'F'
+			case l.House.Contains(p):
+				line[x] = 'H'
+			default:
+				ch, ok := tileChars[t]
+				if !ok {
+					return fmt.Errorf("tile (%d,%d) has unknown value %d", x, y, t)
+				}
+				line[x] = ch
+			}
+		}
+		bw.Write(line)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// Load reads a level file from disk
+func Load(path string) (*Level, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Save writes the level to disk
+func (l *Level) Save(path string) error {
+	var buf bytes.Buffer
+	if err := l.Format(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
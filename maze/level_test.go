@@ -0,0 +1,69 @@
+package maze
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLevel = `; a small level with one of everything
+#########
+#o..P..o#
+#.##-##.#
+ .#HHH#. 
+#.#####.#
+#...F...#
+#########
+`
+
+func TestParseFormatRoundTrip(t *testing.T) {
+	l, err := Parse(strings.NewReader(testLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.At(l.Start); got != Empty {
+		t.Errorf("start tile is %d, want Empty", got)
+	}
+	want := Rect{3, 3, 3, 1}
+	if l.Start != (Point{4, 1}) || l.Door != (Point{4, 2}) || l.Fruit != (Point{4, 5}) || l.House != want {
+		t.Errorf("markers: start %v door %v fruit %v house %v", l.Start, l.Door, l.Fruit, l.House)
+	}
+
+	var buf bytes.Buffer
+	if err := l.Format(&buf); err != nil {
+		t.Fatal(err)
+	}
+	text := testLevel[strings.IndexByte(testLevel, '\n')+1:] // without the comment
+	if buf.String() != text {
+		t.Fatalf("Format wrote\n%s\nwant\n%s", buf.String(), text)
+	}
+	again, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, l) {
+		t.Fatalf("parsed again as %+v, want %+v", again, l)
+	}
+}
+
+func TestClassicLevelRoundTrip(t *testing.T) {
+	var first bytes.Buffer
+	if err := ClassicLevel().Format(&first); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Parse(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var second bytes.Buffer
+	if err := l.Format(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Fatalf("formatted after parsing:\n%s\nwant\n%s", second.String(), first.String())
+	}
+	if c := ClassicLevel(); l.Start != c.Start || l.House != c.House || l.Door != c.Door || l.Fruit != c.Fruit {
+		t.Fatalf("markers moved: got %+v", l)
+	}
+}
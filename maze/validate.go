@@ -0,0 +1,258 @@
+package maze
+
+import "fmt"
+
+// Severity says whether a problem makes a level unplayable
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Problem is something wrong with a level
+type Problem struct {
+	Severity Severity
+	At       *Point // the tile concerned, if there is one
+	Msg      string
+}
+
+func (p Problem) String() string {
+	if p.At != nil {
+		return fmt.Sprintf("%s at (%d,%d): %s", p.Severity, p.At.X, p.At.Y, p.Msg)
+	}
+	return fmt.Sprintf("%s: %s", p.Severity, p.Msg)
+}
+
+// HasErrors reports whether any of the problems is an error
+func HasErrors(problems []Problem) bool {
+	_, ok := FirstError(problems)
+	return ok
+}
+
+// FirstError returns the first of the problems that is an error. Warnings
+// can come before it, so it, not problems[0], is why a level is refused.
+func FirstError(problems []Problem) (Problem, bool) {
+	for _, p := range problems {
+		if p.Severity == Error {
+			return p, true
+		}
+	}
+	return Problem{}, false
+}
+
+// Validate checks that a level can be played: the rows line up, tunnels
+// have both ends, nobody starts in a wall, every pellet can be reached from
+// the player start and the ghost house is closed except for a door the
+// curses can leave by. Dead ends are only a warning.
+func Validate(l *Level) []Problem {
+	var problems []Problem
+	report := func(sev Severity, at *Point, format string, args ...any) {
+		problems = append(problems, Problem{sev, at, fmt.Sprintf(format, args...)})
+	}
+
+	w := l.Width()
+	for y, row := range l.Tiles {
+		if len(row) != w {
+			report(Error, &Point{len(row), y}, "row is %d tiles wide, the widest is %d", len(row), w)
+		}
+	}
+	problems = append(problems, validateEdges(l)...)
+
+	for _, sp := range l.spawns() {
+		if l.At(sp.at) == Wall {
+			report(Error, &sp.at, "%s spawns in a wall", sp.name)
+		}
+	}
+	house := validateHouse(l)
+	if l.At(l.Start) == Wall {
+		return append(problems, house...)
+	}
+
+	reach := Reachable(l, l.Start)
+	unreachable := 0
+	for y, row := range l.Tiles {
+		for x, t := range row {
+			if (t == Pellet || t == PowerPellet) && !reach[Point{x, y}] {
+				if unreachable == 0 {
+					report(Error, &Point{x, y}, "pellet can't be reached from the player start")
+				}
+				unreachable++
+			}
+		}
+	}
+	if unreachable > 1 {
+		report(Error, nil, "%d pellets can't be reached in all", unreachable)
+	}
+
+	var deadEnds []Point
+	for p := range reach {
+		if len(l.neighbours(p)) < 2 && !l.House.Contains(p) {
+			deadEnds = append(deadEnds, p)
+		}
+	}
+	if len(deadEnds) > 0 {
+		first := deadEnds[0]
+		for _, p := range deadEnds {
+			if p.Y < first.Y || p.Y == first.Y && p.X < first.X {
+				first = p
+			}
+		}
+		report(Warning, &first, "%d dead end(s), the first is here", len(deadEnds))
+	}
+
+	problems = append(problems, house...)
+	if exit := l.HouseExit(); !HasErrors(house) {
+		fromExit := Reachable(l, exit)
+		switch {
+		case l.At(exit) == Wall:
+			report(Error, &exit, "the ghost house exit is a wall")
+		case !fromExit[l.Start]:
+			report(Error, &exit, "the maze can't be reached from the ghost house exit")
+		case !fromExit[l.GhostStarts()[0]]:
+			report(Error, &exit, "the ghost house can't be reached from its exit")
+		}
+	}
+	return problems
+}
+
+// validateEdges checks that open tiles on the left and right edges pair up
+// as tunnels, and that nothing the player can reach opens off the top or
+// bottom, as only rows wrap around
+func validateEdges(l *Level) []Problem {
+	var problems []Problem
+	for y, row := range l.Tiles {
+		if len(row) == 0 {
+			continue
+		}
+		left, right := row[0] != Wall, row[len(row)-1] != Wall
+		if left != right {
+			at := Point{0, y}
+			if right {
+				at.X = len(row) - 1
+			}
+			problems = append(problems, Problem{Error, &at, "tunnel has no other end on the opposite side"})
+		}
+	}
+
+	if l.At(l.Start) == Wall {
+		return problems
+	}
+	reach := Reachable(l, l.Start)
+	for _, y := range []int{0, len(l.Tiles) - 1} {
+		for x, t := range l.Tiles[y] {
+			if p := (Point{x, y}); t != Wall && reach[p] {
+				problems = append(problems, Problem{Error, &p, "open tile on the edge of the maze leads off it"})
+			}
+		}
+	}
+	return problems
+}
+
+type spawn struct {
+	name string
+	at   Point
+}
+
+// spawns are the tiles actors appear on
+func (l *Level) spawns() []spawn {
+	spawns := []spawn{{"player", l.Start}, {"fruit", l.Fruit}}
+	for i, p := range l.GhostStarts() {
+		spawns = append(spawns, spawn{fmt.Sprintf("curse %d", i+1), p})
+	}
+	return spawns
+}
+
+func validateHouse(l *Level) []Problem {
+	var problems []Problem
+	report := func(at Point, format string, args ...any) {
+		problems = append(problems, Problem{Error, &at, fmt.Sprintf(format, args...)})
+	}
+
+	h := l.House
+	if h.W < 1 || h.H < 1 {
+		return []Problem{{Error, nil, "there is no ghost house"}}
+	}
+	spawns := map[Point]bool{}
+	for _, p := range l.GhostStarts() {
+		spawns[p] = true
+	}
+	for y := h.Y; y < h.Y+h.H; y++ {
+		for x := h.X; x < h.X+h.W; x++ {
+			// Walls under a curse are reported as a spawn in a wall
+			if p := (Point{x, y}); l.At(p) == Wall && !spawns[p] {
+				report(p, "wall inside the ghost house")
+			}
+		}
+	}
+
+	doorOnEdge := false
+	for y := h.Y - 1; y <= h.Y+h.H; y++ {
+		for x := h.X - 1; x <= h.X+h.W; x++ {
+			p := Point{x, y}
+			if h.Contains(p) {
+				continue
+			}
+			corner := (x == h.X-1 || x == h.X+h.W) && (y == h.Y-1 || y == h.Y+h.H)
+			switch {
+			case p == l.Door && !corner:
+				doorOnEdge = true
+				if l.At(p) == Wall {
+					report(p, "the ghost house door is a wall")
+				}
+			case l.At(p) != Wall && !corner:
+				report(p, "gap in the ghost house wall")
+			}
+		}
+	}
+	if !doorOnEdge {
+		report(l.Door, "the ghost house door isn't in the house wall")
+	}
+	return problems
+}
+
+// Reachable returns the open tiles that can be walked to from start. Rows
+// open at both ends are tunnels joining the two sides.
+func Reachable(l *Level, start Point) map[Point]bool {
+	seen := map[Point]bool{start: true}
+	queue := []Point{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, n := range l.neighbours(p) {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return seen
+}
+
+// neighbours are the open tiles next to p, through tunnels too
+func (l *Level) neighbours(p Point) []Point {
+	var out []Point
+	for _, d := range [4]Point{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		n := Point{p.X + d.X, p.Y + d.Y}
+		if n.Y >= 0 && n.Y < len(l.Tiles) {
+			row := l.Tiles[n.Y]
+			switch {
+			case n.X < 0 && row[len(row)-1] != Wall:
+				n.X = len(row) - 1
+			case n.X >= len(row) && len(row) > 0 && row[0] != Wall:
+				n.X = 0
+			}
+		}
+		if l.In(n) && l.At(n) != Wall {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package maze
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFirstErrorSkipsWarnings(t *testing.T) {
+	l, err := Parse(strings.NewReader(testLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Tiles[5][2] = Wall  // leaves dead ends either side
+	l.Tiles[4][4] = Empty // a gap under the ghost house
+
+	problems := Validate(l)
+	if len(problems) == 0 || problems[0].Severity != Warning {
+		t.Fatalf("want the dead ends reported first, got %v", problems)
+	}
+	got, ok := FirstError(problems)
+	if !ok || got.Severity != Error || !strings.Contains(got.Msg, "ghost house") {
+		t.Fatalf("FirstError = %v, %v, want the gap in the ghost house", got, ok)
+	}
+	if !HasErrors(problems) {
+		t.Error("HasErrors missed the gap")
+	}
+
+	if got, ok := FirstError(problems[:1]); ok {
+		t.Errorf("FirstError of a warning = %v, want none", got)
+	}
+}